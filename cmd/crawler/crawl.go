@@ -0,0 +1,699 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+
+    "github.com/DavisAndn/go-aws-crawler/internal/awsfetch"
+    "github.com/DavisAndn/go-aws-crawler/internal/payload"
+    "github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// crawlRun is the state shared by the fetchers of one crawl.
+type crawlRun struct {
+    ctx       context.Context
+    awsConfig aws.Config
+    global    bool // Whether to fetch global resources, which one region of a run fetches for all.
+    data      *payload.InitialData
+    mu        sync.Mutex
+}
+
+// fetcher fetches one service into a crawl. The service name selects it in
+// partial crawls requested by the backend.
+type fetcher struct {
+    service string
+    fetch   func(c *crawlRun) error
+}
+
+// fetchers lists every service the crawler fetches.
+var fetchers = []fetcher{
+    {"account", func(c *crawlRun) error {
+        account, err := awsfetch.FetchAccountInfo(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.Account = account
+        c.mu.Unlock()
+        return nil
+    }},
+    {"ec2_instances", func(c *crawlRun) error {
+        instances, err := awsfetch.FetchEC2Instances(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.EC2Instances = instances
+        c.mu.Unlock()
+        return nil
+    }},
+    {"vpc", func(c *crawlRun) error {
+        vpcs, subnets, routeTables, natGateways, internetGateways, err := awsfetch.FetchVPCData(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.VPCs = vpcs
+        c.data.Subnets = subnets
+        c.data.RouteTables = routeTables
+        c.data.NATGateways = natGateways
+        c.data.InternetGateways = internetGateways
+        c.mu.Unlock()
+        return nil
+    }},
+    {"s3_buckets", func(c *crawlRun) error {
+        buckets, err := awsfetch.FetchS3Buckets(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.S3Buckets = buckets
+        c.mu.Unlock()
+        return nil
+    }},
+    {"rds_instances", func(c *crawlRun) error {
+        rds, err := awsfetch.FetchRDSInstances(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.RDSInstances = rds
+        c.mu.Unlock()
+        return nil
+    }},
+    {"route53_zones", func(c *crawlRun) error {
+        zones, err := awsfetch.FetchRoute53Zones(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.Route53Zones = zones
+        c.mu.Unlock()
+        return nil
+    }},
+    {"autoscaling_groups", func(c *crawlRun) error {
+        groups, err := awsfetch.FetchAutoScalingGroups(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.AutoScalingGroups = groups
+        c.mu.Unlock()
+        return nil
+    }},
+    {"load_balancers", func(c *crawlRun) error {
+        lbs, err := awsfetch.FetchLoadBalancers(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.LoadBalancers = lbs
+        c.mu.Unlock()
+        return nil
+    }},
+    {"eks_clusters", func(c *crawlRun) error {
+        eks, err := awsfetch.FetchEKSClusters(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.EKSClusters = eks
+        c.mu.Unlock()
+        return nil
+    }},
+    {"iam", func(c *crawlRun) error {
+        users, policies, err := awsfetch.FetchIAMData(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.IAMUsers = users
+        c.data.IAMPolicies = policies
+        c.mu.Unlock()
+        return nil
+    }},
+    {"elasticache", func(c *crawlRun) error {
+        caches, err := awsfetch.FetchElastiCaches(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.ElastiCaches = caches
+        c.mu.Unlock()
+        return nil
+    }},
+    {"apigateway_rest_apis", func(c *crawlRun) error {
+        restAPIs, vpcLinks, err := awsfetch.FetchAPIGatewayRestAPIs(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.APIGatewayRestAPIs = restAPIs
+        c.data.APIGatewayVpcLinks = vpcLinks
+        c.mu.Unlock()
+        return nil
+    }},
+    {"apigateway_v2_apis", func(c *crawlRun) error {
+        apis, vpcLinks, err := awsfetch.FetchAPIGatewayV2APIs(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.APIGatewayV2APIs = apis
+        c.data.APIGatewayV2VpcLinks = vpcLinks
+        c.mu.Unlock()
+        return nil
+    }},
+    {"apigateway_domain_names", func(c *crawlRun) error {
+        domains, err := awsfetch.FetchAPIGatewayDomainNames(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.APIGatewayDomainNames = domains
+        c.mu.Unlock()
+        return nil
+    }},
+    {"vpc_peering_connections", func(c *crawlRun) error {
+        peerings, err := awsfetch.FetchVPCPeeringConnections(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.VPCPeeringConnections = peerings
+        c.mu.Unlock()
+        return nil
+    }},
+    {"transit_gateways", func(c *crawlRun) error {
+        tgws, err := awsfetch.FetchTransitGateways(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.TransitGateways = tgws
+        c.mu.Unlock()
+        return nil
+    }},
+    {"vpc_endpoints", func(c *crawlRun) error {
+        endpoints, err := awsfetch.FetchVPCEndpoints(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.VPCEndpoints = endpoints
+        c.mu.Unlock()
+        return nil
+    }},
+    {"vpn", func(c *crawlRun) error {
+        connections, customerGateways, vpnGateways, err := awsfetch.FetchVPNData(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.VPNConnections = connections
+        c.data.CustomerGateways = customerGateways
+        c.data.VPNGateways = vpnGateways
+        c.mu.Unlock()
+        return nil
+    }},
+    {"egress_only_internet_gateways", func(c *crawlRun) error {
+        eigws, err := awsfetch.FetchEgressOnlyInternetGateways(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.EgressOnlyInternetGateways = eigws
+        c.mu.Unlock()
+        return nil
+    }},
+    {"direct_connect", func(c *crawlRun) error {
+        vifs, dxGateways, err := awsfetch.FetchDirectConnectData(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.DirectConnectVirtualInterfaces = vifs
+        c.data.DirectConnectGateways = dxGateways
+        c.mu.Unlock()
+        return nil
+    }},
+    {"network_interfaces", func(c *crawlRun) error {
+        enis, err := awsfetch.FetchNetworkInterfaces(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.NetworkInterfaces = enis
+        c.mu.Unlock()
+        return nil
+    }},
+    {"elastic_ips", func(c *crawlRun) error {
+        eips, err := awsfetch.FetchElasticIPs(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.ElasticIPs = eips
+        c.mu.Unlock()
+        return nil
+    }},
+    {"acm_certificates", func(c *crawlRun) error {
+        certs, err := awsfetch.FetchACMCertificates(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.ACMCertificates = certs
+        c.mu.Unlock()
+        return nil
+    }},
+    {"cloudformation_stacks", func(c *crawlRun) error {
+        stacks, err := awsfetch.FetchCloudFormationStacks(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.CloudFormationStacks = stacks
+        c.mu.Unlock()
+        return nil
+    }},
+    {"cloudwatch_alarms", func(c *crawlRun) error {
+        metricAlarms, compositeAlarms, err := awsfetch.FetchCloudWatchAlarms(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.MetricAlarms = metricAlarms
+        c.data.CompositeAlarms = compositeAlarms
+        c.mu.Unlock()
+        return nil
+    }},
+    {"log_groups", func(c *crawlRun) error {
+        logGroups, err := awsfetch.FetchLogGroups(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.LogGroups = logGroups
+        c.mu.Unlock()
+        return nil
+    }},
+    {"ecr_repositories", func(c *crawlRun) error {
+        repos, err := awsfetch.FetchECRRepositories(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.ECRRepositories = repos
+        c.mu.Unlock()
+        return nil
+    }},
+    {"container_image_consumers", func(c *crawlRun) error {
+        consumers, eksErrors, err := awsfetch.FetchContainerImageConsumers(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        for _, e := range eksErrors {
+            log.Printf("Skipping workloads of EKS cluster %s: %s", e.ClusterArn, e.Error)
+        }
+        c.mu.Lock()
+        c.data.ContainerImageConsumers = consumers
+        c.data.EKSWorkloadErrors = eksErrors
+        c.mu.Unlock()
+        return nil
+    }},
+    {"efs_file_systems", func(c *crawlRun) error {
+        fileSystems, err := awsfetch.FetchEFSFileSystems(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.EFSFileSystems = fileSystems
+        c.mu.Unlock()
+        return nil
+    }},
+    {"ebs_volumes", func(c *crawlRun) error {
+        volumes, err := awsfetch.FetchEBSVolumes(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.EBSVolumes = volumes
+        c.mu.Unlock()
+        return nil
+    }},
+    {"dynamodb_tables", func(c *crawlRun) error {
+        tables, err := awsfetch.FetchDynamoDBTables(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.DynamoDBTables = tables
+        c.mu.Unlock()
+        return nil
+    }},
+    {"fsx_file_systems", func(c *crawlRun) error {
+        fileSystems, err := awsfetch.FetchFSxFileSystems(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.FSxFileSystems = fileSystems
+        c.mu.Unlock()
+        return nil
+    }},
+    {"backup_vaults", func(c *crawlRun) error {
+        vaults, err := awsfetch.FetchBackupVaults(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.BackupVaults = vaults
+        c.mu.Unlock()
+        return nil
+    }},
+    {"backup_plans", func(c *crawlRun) error {
+        plans, err := awsfetch.FetchBackupPlans(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.BackupPlans = plans
+        c.mu.Unlock()
+        return nil
+    }},
+    {"backup_protected_resources", func(c *crawlRun) error {
+        resources, err := awsfetch.FetchBackupProtectedResources(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.BackupProtectedResources = resources
+        c.mu.Unlock()
+        return nil
+    }},
+    {"kinesis_streams", func(c *crawlRun) error {
+        streams, err := awsfetch.FetchKinesisStreams(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.KinesisStreams = streams
+        c.mu.Unlock()
+        return nil
+    }},
+    {"firehose_delivery_streams", func(c *crawlRun) error {
+        streams, err := awsfetch.FetchFirehoseDeliveryStreams(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.FirehoseDeliveryStreams = streams
+        c.mu.Unlock()
+        return nil
+    }},
+    {"msk_clusters", func(c *crawlRun) error {
+        clusters, err := awsfetch.FetchMSKClusters(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.MSKClusters = clusters
+        c.mu.Unlock()
+        return nil
+    }},
+    {"redshift_clusters", func(c *crawlRun) error {
+        clusters, err := awsfetch.FetchRedshiftClusters(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.RedshiftClusters = clusters
+        c.mu.Unlock()
+        return nil
+    }},
+    {"redshift_serverless_workgroups", func(c *crawlRun) error {
+        workgroups, err := awsfetch.FetchRedshiftServerlessWorkgroups(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.RedshiftServerlessWorkgroups = workgroups
+        c.mu.Unlock()
+        return nil
+    }},
+    {"opensearch_domains", func(c *crawlRun) error {
+        domains, err := awsfetch.FetchOpenSearchDomains(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.OpenSearchDomains = domains
+        c.mu.Unlock()
+        return nil
+    }},
+    {"state_machines", func(c *crawlRun) error {
+        machines, err := awsfetch.FetchStateMachines(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.StateMachines = machines
+        c.mu.Unlock()
+        return nil
+    }},
+    {"eventbridge", func(c *crawlRun) error {
+        buses, destinations, connections, err := awsfetch.FetchEventBridgeData(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.EventBuses = buses
+        c.data.APIDestinations = destinations
+        c.data.EventConnections = connections
+        c.mu.Unlock()
+        return nil
+    }},
+    {"schedules", func(c *crawlRun) error {
+        schedules, err := awsfetch.FetchSchedules(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.Schedules = schedules
+        c.mu.Unlock()
+        return nil
+    }},
+    {"cognito_user_pools", func(c *crawlRun) error {
+        pools, err := awsfetch.FetchCognitoUserPools(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.CognitoUserPools = pools
+        c.mu.Unlock()
+        return nil
+    }},
+    {"cognito_identity_pools", func(c *crawlRun) error {
+        pools, err := awsfetch.FetchCognitoIdentityPools(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.CognitoIdentityPools = pools
+        c.mu.Unlock()
+        return nil
+    }},
+    {"waf_web_acls", func(c *crawlRun) error {
+        acls, err := awsfetch.FetchWAFWebACLs(c.ctx, c.awsConfig, c.global)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.WAFWebACLs = acls
+        c.mu.Unlock()
+        return nil
+    }},
+    {"shield_protections", func(c *crawlRun) error {
+        if !c.global {
+            return nil
+        }
+        protections, err := awsfetch.FetchShieldProtections(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.ShieldProtections = protections
+        c.mu.Unlock()
+        return nil
+    }},
+    {"service_quotas", func(c *crawlRun) error {
+        quotas, err := awsfetch.FetchServiceQuotas(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.ServiceQuotas = quotas
+        c.mu.Unlock()
+        return nil
+    }},
+    {"securityhub_findings", func(c *crawlRun) error {
+        findings, err := awsfetch.FetchSecurityHubFindings(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.SecurityFindings = append(c.data.SecurityFindings, findings...)
+        c.mu.Unlock()
+        return nil
+    }},
+    {"guardduty_findings", func(c *crawlRun) error {
+        findings, err := awsfetch.FetchGuardDutyFindings(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.SecurityFindings = append(c.data.SecurityFindings, findings...)
+        c.mu.Unlock()
+        return nil
+    }},
+    {"inspector_findings", func(c *crawlRun) error {
+        findings, err := awsfetch.FetchInspectorFindings(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.SecurityFindings = append(c.data.SecurityFindings, findings...)
+        c.mu.Unlock()
+        return nil
+    }},
+    {"access_analyzer_findings", func(c *crawlRun) error {
+        findings, err := awsfetch.FetchAccessAnalyzerFindings(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.SecurityFindings = append(c.data.SecurityFindings, findings...)
+        c.mu.Unlock()
+        return nil
+    }},
+    {"ssm_managed_instances", func(c *crawlRun) error {
+        managed, err := awsfetch.FetchSSMManagedInstances(c.ctx, c.awsConfig)
+        if err != nil {
+            return err
+        }
+        c.mu.Lock()
+        c.data.SSMHybridInstances = managed
+        c.mu.Unlock()
+        return nil
+    }},
+}
+
+// requiredServices fail the whole crawl when they cannot be fetched: the
+// account identifies the crawl, and the rest of the inventory hangs off the
+// instances and networks.
+var requiredServices = map[string]bool{"account": true, "ec2_instances": true, "vpc": true}
+
+// crawl runs the fetchers of the services in scope concurrently, or of every
+// service when scope is nil, and then links the results. It also returns how
+// long each service took to fetch, and the errors of the services that failed;
+// their sections are left empty. Only a failure of a required service fails
+// the crawl.
+func crawl(ctx context.Context, awsConfig aws.Config, scope map[string]bool, global bool) (*payload.InitialData, map[string]time.Duration, map[string]error, error) {
+    c := &crawlRun{ctx: ctx, awsConfig: awsConfig, global: global, data: &payload.InitialData{}}
+    var wg sync.WaitGroup
+    var fetchErrs []error
+    durations := make(map[string]time.Duration)
+    serviceErrs := make(map[string]error)
+    crawled := make(map[string]bool)
+    var resultMu sync.Mutex
+    for _, f := range fetchers {
+        if scope != nil && !scope[f.service] {
+            continue
+        }
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            start := time.Now()
+            err := f.fetch(c)
+            resultMu.Lock()
+            defer resultMu.Unlock()
+            durations[f.service] = time.Since(start)
+            switch {
+            case err == nil:
+                crawled[f.service] = true
+            case requiredServices[f.service]:
+                fetchErrs = append(fetchErrs, fmt.Errorf("%s: %w", f.service, err))
+            default:
+                log.Printf("Leaving %s empty, fetching it failed: %v", f.service, err)
+                serviceErrs[f.service] = err
+            }
+        }()
+    }
+    wg.Wait()
+    if len(fetchErrs) > 0 {
+        return nil, nil, nil, errors.Join(fetchErrs...)
+    }
+
+    data := c.data
+    data.StackMembership = awsfetch.StackMembershipIndex(data.CloudFormationStacks)
+    awsfetch.LinkECRImageConsumers(data.ECRRepositories, data.ContainerImageConsumers)
+    awsfetch.LinkLoadBalancerWebACLs(data.LoadBalancers, data.WAFWebACLs)
+    awsfetch.LinkRDSNetworkInterfaces(data.NetworkInterfaces, data.RDSInstances)
+    awsfetch.LinkACMCertificates(data.ACMCertificates, data.LoadBalancers, data.APIGatewayDomainNames)
+    data.BackupCoverage = awsfetch.BackupCoverageIndex(data.BackupPlans, data.BackupVaults, data.BackupProtectedResources, awsfetch.BackupInventory{
+        Account:        data.Account,
+        RDSInstances:   data.RDSInstances,
+        EBSVolumes:     data.EBSVolumes,
+        EFSFileSystems: data.EFSFileSystems,
+        DynamoDBTables: data.DynamoDBTables,
+    })
+    // Managed nodes that are crawled EC2 instances are attached to them; only hybrid nodes remain.
+    data.SSMHybridInstances = awsfetch.LinkSSMManagedInstances(data.EC2Instances, data.SSMHybridInstances)
+    awsfetch.LinkSecurityFindings(data.SecurityFindings, awsfetch.FindingInventory{
+        Account:         data.Account,
+        EC2Instances:    data.EC2Instances,
+        EBSVolumes:      data.EBSVolumes,
+        S3Buckets:       data.S3Buckets,
+        IAMUsers:        data.IAMUsers,
+        EKSClusters:     data.EKSClusters,
+        RDSInstances:    data.RDSInstances,
+        LoadBalancers:   data.LoadBalancers,
+        ECRRepositories: data.ECRRepositories,
+        DynamoDBTables:  data.DynamoDBTables,
+        EFSFileSystems:  data.EFSFileSystems,
+    })
+    // Usage is not counted from the sections of services that failed.
+    awsfetch.ApplyQuotaUsage(data.ServiceQuotas, awsfetch.QuotaInventory{
+        Services:                   crawled,
+        EC2Instances:               data.EC2Instances,
+        ElasticIPs:                 data.ElasticIPs,
+        VPCs:                       data.VPCs,
+        Subnets:                    data.Subnets,
+        InternetGateways:           data.InternetGateways,
+        EgressOnlyInternetGateways: data.EgressOnlyInternetGateways,
+        NATGateways:                data.NATGateways,
+        NetworkInterfaces:          data.NetworkInterfaces,
+        LoadBalancers:              data.LoadBalancers,
+        AutoScalingGroups:          data.AutoScalingGroups,
+        EKSClusters:                data.EKSClusters,
+        RDSInstances:               data.RDSInstances,
+        CloudFormationStacks:       data.CloudFormationStacks,
+        ECRRepositories:            data.ECRRepositories,
+        EFSFileSystems:             data.EFSFileSystems,
+    })
+    return data, durations, serviceErrs, nil
+}
+
+// serviceNames returns the names of the services the crawler fetches.
+func serviceNames() []string {
+    names := make([]string, len(fetchers))
+    for i, f := range fetchers {
+        names[i] = f.service
+    }
+    return names
+}
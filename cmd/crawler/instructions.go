@@ -0,0 +1,183 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "slices"
+    "time"
+
+    "github.com/DavisAndn/go-aws-crawler/internal/command"
+    "github.com/DavisAndn/go-aws-crawler/internal/envelope"
+    "github.com/DavisAndn/go-aws-crawler/internal/payload"
+    "github.com/DavisAndn/go-aws-crawler/internal/sink"
+    "github.com/DavisAndn/go-aws-crawler/internal/version"
+    "github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// crawler crawls regions of the account, delivers the results to the sinks
+// and executes the instructions the backend sends back.
+type crawler struct {
+    crawlID   string
+    agentID   string
+    awsConfig aws.Config
+    sinks     sink.Multi
+    regions   []string
+    store     sink.SpoolStore // Nil when settings cannot be saved.
+    settings  *command.Settings
+    crawls    int // Region crawls run for instructions in this invocation.
+}
+
+// crawlAndDeliver crawls region and delivers the result as crawl id. The
+// crawl covers every service, or only those requested by the instruction in
+// when it is not nil, trimmed to the resources it names.
+func (c *crawler) crawlAndDeliver(ctx context.Context, id, region string, in *command.Instruction) (*payload.InitialData, error) {
+    awsConfig := c.awsConfig.Copy()
+    awsConfig.Region = region
+    startedAt := time.Now()
+
+    var scope map[string]bool
+    services := serviceNames()
+    if in != nil {
+        // The account is always crawled so partial crawls identify it.
+        scope = map[string]bool{"account": true}
+        for _, service := range in.Services {
+            scope[service] = true
+        }
+        services = slices.DeleteFunc(services, func(service string) bool { return !scope[service] })
+    }
+
+    // Global resources are crawled with the first configured region only.
+    global := len(c.regions) > 0 && region == c.regions[0]
+    data, durations, serviceErrs, err := crawl(ctx, awsConfig, scope, global)
+    if err != nil {
+        log.Printf("Error during resource fetching in %s: %v", region, err)
+        return nil, err
+    }
+    env := envelope.Envelope{
+        SchemaVersion: envelope.SchemaVersion,
+        CrawlID:       id,
+        StartedAt:     startedAt.UTC(),
+        FinishedAt:    time.Now().UTC(),
+        Crawler: envelope.Crawler{
+            Version: version.Version,
+            Commit:  version.Commit,
+            AgentID: c.agentID,
+        },
+        Scope: envelope.Scope{
+            AccountID: data.Account.AccountID,
+            Region:    region,
+            Global:    global,
+            Services:  services,
+        },
+        ServiceDurationsMs: envelope.Durations(durations),
+        ServiceErrors:      envelope.Errors(serviceErrs),
+    }
+    if in != nil {
+        env.Scope.Partial = true
+        env.Scope.Instruction = in.ID
+        if len(in.ResourceIDs) > 0 {
+            command.FilterResources(data, in.ResourceIDs)
+            env.Scope.ResourceIDs = in.ResourceIDs
+        }
+    }
+
+    err = c.sinks.Deliver(ctx, &sink.Crawl{
+        ID:        id,
+        AccountID: data.Account.AccountID,
+        Region:    region,
+        StartedAt: startedAt,
+        Data:      &payload.Payload{Envelope: env, InitialData: *data},
+    })
+    if err != nil {
+        log.Printf("Error delivering crawl %s to %s: %v", id, c.sinks.Name(), err)
+        return nil, err
+    }
+    log.Printf("Crawl %s of %s delivered.", id, region)
+    return data, nil
+}
+
+// runInstructions validates the instructions against policy and executes
+// those that pass and have not run before. Failures are logged; a failed
+// instruction runs again when the backend repeats it.
+func (c *crawler) runInstructions(ctx context.Context, policy command.Policy, instructions []command.Instruction) {
+    // The same instruction may arrive in a reply and in a poll.
+    seen := make(map[string]bool)
+    instructions = slices.DeleteFunc(instructions, func(in command.Instruction) bool {
+        duplicate := seen[in.ID]
+        seen[in.ID] = true
+        return duplicate
+    })
+    if len(instructions) > command.MaxInstructions {
+        log.Printf("Dropping %d instructions over the limit of %d", len(instructions)-command.MaxInstructions, command.MaxInstructions)
+        instructions = instructions[:command.MaxInstructions]
+    }
+    for _, in := range instructions {
+        if c.settings.WasExecuted(in.ID) {
+            log.Printf("Skipping instruction %s, already executed", in.ID)
+            continue
+        }
+        if err := policy.Validate(in); err != nil {
+            log.Printf("Rejected instruction %s: %v", in.ID, err)
+            continue
+        }
+        // The first crawl instruction always runs, however many regions it
+        // covers, so that no instruction waits forever.
+        if in.Type == command.Recrawl || in.Type == command.Describe {
+            n := len(c.instructionRegions(in))
+            if c.crawls > 0 && c.crawls+n > command.MaxCrawls {
+                log.Printf("Deferring instruction %s, its %d crawls would exceed the limit of %d per invocation", in.ID, n, command.MaxCrawls)
+                continue
+            }
+            c.crawls += n
+        }
+        if err := c.execute(ctx, policy, in); err != nil {
+            log.Printf("Error executing instruction %s (%s): %v", in.ID, in.Type, err)
+            continue
+        }
+        log.Printf("Executed instruction %s (%s)", in.ID, in.Type)
+        c.settings.MarkExecuted(in.ID)
+    }
+    if c.store != nil {
+        if err := c.settings.Save(ctx, c.store); err != nil {
+            log.Printf("Error saving settings: %v", err)
+        }
+    }
+}
+
+func (c *crawler) execute(ctx context.Context, policy command.Policy, in command.Instruction) error {
+    switch in.Type {
+    case command.Recrawl, command.Describe:
+        regions := c.instructionRegions(in)
+        var errs []error
+        for _, region := range regions {
+            id := c.crawlID + "-" + in.ID
+            if len(regions) > 1 {
+                id += "-" + region
+            }
+            if _, err := c.crawlAndDeliver(ctx, id, region, &in); err != nil {
+                errs = append(errs, err)
+            }
+        }
+        return errors.Join(errs...)
+    case command.SetRegions:
+        if c.store == nil {
+            return fmt.Errorf("regions cannot be saved while the spool is disabled")
+        }
+        c.settings.Regions = in.Regions
+        return nil
+    case command.SetSchedule:
+        return command.UpdateSchedule(ctx, c.awsConfig, policy.ScheduleName, in.Schedule)
+    }
+    return fmt.Errorf("unknown instruction type %q", in.Type)
+}
+
+// instructionRegions returns the regions a crawl instruction covers: those it
+// names, or every configured region.
+func (c *crawler) instructionRegions(in command.Instruction) []string {
+    if len(in.Regions) > 0 {
+        return in.Regions
+    }
+    return c.regions
+}
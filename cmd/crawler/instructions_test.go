@@ -0,0 +1,143 @@
+package main
+
+import (
+    "context"
+    "io/fs"
+    "reflect"
+    "strconv"
+    "testing"
+
+    "github.com/DavisAndn/go-aws-crawler/internal/command"
+    "github.com/DavisAndn/go-aws-crawler/internal/sink"
+)
+
+// memStore is an in-memory spool store for settings.
+type memStore map[string][]byte
+
+func (m memStore) List(context.Context, string) ([]sink.SpoolEntry, error) { return nil, nil }
+
+func (m memStore) Get(_ context.Context, key string) ([]byte, error) {
+    data, ok := m[key]
+    if !ok {
+        return nil, fs.ErrNotExist
+    }
+    return data, nil
+}
+
+func (m memStore) Put(_ context.Context, key string, data []byte) error {
+    m[key] = data
+    return nil
+}
+
+func (m memStore) Delete(_ context.Context, key string) error {
+    delete(m, key)
+    return nil
+}
+
+var testPolicy = command.Policy{
+    Allowed:  []command.Type{command.Recrawl, command.Describe, command.SetRegions},
+    Services: []string{"account", "ec2_instances"},
+    Regions:  []string{"us-east-1", "eu-west-1"},
+}
+
+func setRegions(id string, regions ...string) command.Instruction {
+    return command.Instruction{ID: id, Type: command.SetRegions, Regions: regions}
+}
+
+func TestRunInstructions(t *testing.T) {
+    var overLimit []command.Instruction
+    var overLimitIDs []string
+    for i := 0; i < command.MaxInstructions+2; i++ {
+        id := "r-" + strconv.Itoa(i)
+        overLimit = append(overLimit, setRegions(id, "us-east-1"))
+        if i < command.MaxInstructions {
+            overLimitIDs = append(overLimitIDs, id)
+        }
+    }
+
+    tests := []struct {
+        name         string
+        store        sink.SpoolStore
+        executed     []string
+        crawls       int
+        instructions []command.Instruction
+        wantRegions  []string
+        wantExecuted []string
+    }{
+        {
+            name:         "duplicates run once",
+            store:        memStore{},
+            instructions: []command.Instruction{setRegions("r-1", "us-east-1"), setRegions("r-1", "eu-west-1")},
+            wantRegions:  []string{"us-east-1"},
+            wantExecuted: []string{"r-1"},
+        },
+        {
+            name:         "instructions over the limit are dropped",
+            store:        memStore{},
+            instructions: overLimit,
+            wantRegions:  []string{"us-east-1"},
+            wantExecuted: overLimitIDs,
+        },
+        {
+            name:         "executed instructions are skipped",
+            store:        memStore{},
+            executed:     []string{"r-1"},
+            instructions: []command.Instruction{setRegions("r-1", "eu-west-1"), setRegions("r-2", "us-east-1")},
+            wantRegions:  []string{"us-east-1"},
+            wantExecuted: []string{"r-1", "r-2"},
+        },
+        {
+            name:         "rejected instructions are not executed",
+            store:        memStore{},
+            instructions: []command.Instruction{setRegions("r-1", "ap-south-1"), {ID: "bad id", Type: command.SetRegions, Regions: []string{"us-east-1"}}},
+        },
+        {
+            name:         "set_regions needs a store",
+            instructions: []command.Instruction{setRegions("r-1", "eu-west-1")},
+        },
+        {
+            name:         "crawls over the limit are deferred",
+            store:        memStore{},
+            crawls:       command.MaxCrawls,
+            instructions: []command.Instruction{{ID: "c-1", Type: command.Recrawl, Services: []string{"ec2_instances"}, Regions: []string{"us-east-1"}}},
+        },
+    }
+    for _, tt := range tests {
+        c := &crawler{
+            crawlID:  "crawl",
+            regions:  []string{"us-east-1"},
+            store:    tt.store,
+            settings: &command.Settings{Executed: tt.executed},
+            crawls:   tt.crawls,
+        }
+        c.runInstructions(context.Background(), testPolicy, tt.instructions)
+        if !reflect.DeepEqual(c.settings.Regions, tt.wantRegions) {
+            t.Errorf("%s: regions = %v, want %v", tt.name, c.settings.Regions, tt.wantRegions)
+        }
+        if !reflect.DeepEqual(c.settings.Executed, tt.wantExecuted) {
+            t.Errorf("%s: executed = %v, want %v", tt.name, c.settings.Executed, tt.wantExecuted)
+        }
+        if c.crawls != tt.crawls {
+            t.Errorf("%s: ran %d crawls, want none", tt.name, c.crawls-tt.crawls)
+        }
+        if tt.store == nil {
+            continue
+        }
+        saved, err := command.LoadSettings(context.Background(), tt.store)
+        if err != nil {
+            t.Errorf("%s: LoadSettings() error = %v", tt.name, err)
+        } else if !reflect.DeepEqual(saved, c.settings) {
+            t.Errorf("%s: saved settings = %+v, want %+v", tt.name, saved, c.settings)
+        }
+    }
+}
+
+func TestInstructionRegions(t *testing.T) {
+    c := &crawler{regions: []string{"us-east-1", "eu-west-1"}}
+    if got := c.instructionRegions(command.Instruction{Regions: []string{"eu-west-1"}}); !reflect.DeepEqual(got, []string{"eu-west-1"}) {
+        t.Errorf("instructionRegions() of named regions = %v, want [eu-west-1]", got)
+    }
+    if got := c.instructionRegions(command.Instruction{}); !reflect.DeepEqual(got, c.regions) {
+        t.Errorf("instructionRegions() without regions = %v, want %v", got, c.regions)
+    }
+}
@@ -2,38 +2,22 @@ package main
 
 import (
     "context"
-    "encoding/json"
+    "crypto/rand"
+    "encoding/hex"
+    "errors"
     "log"
-    "sync"
     "time"
 
     "github.com/aws/aws-lambda-go/lambda"
-    "github.com/DavisAndn/go-aws-crawler/internal/awsfetch"
+    "github.com/aws/aws-lambda-go/lambdacontext"
     "github.com/DavisAndn/go-aws-crawler/internal/backend"
+    "github.com/DavisAndn/go-aws-crawler/internal/command"
     "github.com/DavisAndn/go-aws-crawler/internal/config"
+    "github.com/DavisAndn/go-aws-crawler/internal/sink"
     awsCfg "github.com/aws/aws-sdk-go-v2/config"
     "github.com/aws/aws-sdk-go-v2/aws"
 )
 
-// InitialData structure remains the same as before.
-type InitialData struct {
-    EC2Instances      []awsfetch.EC2Instance      `json:"ec2_instances"`
-    VPCs              []awsfetch.VPC              `json:"vpcs"`
-    Subnets           []awsfetch.Subnet           `json:"subnets"`
-    RouteTables       []awsfetch.RouteTable       `json:"route_tables"`
-    NATGateways       []awsfetch.NATGateway       `json:"nat_gateways"`
-    InternetGateways  []awsfetch.InternetGateway  `json:"internet_gateways"`
-    S3Buckets         []awsfetch.S3Bucket         `json:"s3_buckets"`
-    RDSInstances      []awsfetch.RDSInstance      `json:"rds_instances"`
-    Route53Zones      []awsfetch.Route53Zone      `json:"route53_hosted_zones"`
-    AutoScalingGroups []awsfetch.AutoScalingGroup `json:"autoscaling_groups"`
-    LoadBalancers     []awsfetch.LoadBalancer     `json:"load_balancers"`
-    EKSClusters       []awsfetch.EKSCluster       `json:"eks_clusters"`
-    IAMUsers          []awsfetch.IAMUser          `json:"iam_users"`
-    IAMPolicies       []awsfetch.IAMPolicy        `json:"iam_policies"`
-    ElastiCaches      []awsfetch.ElastiCache      `json:"elastic_caches"`
-}
-
 func handler(ctx context.Context) (string, error) {
     cfg, err := config.LoadConfig()
     if err != nil {
@@ -60,164 +44,80 @@ func handler(ctx context.Context) (string, error) {
         log.Printf("Access Key ID: %s", creds.AccessKeyID)
     }
 
-    crawlCtx, cancel := context.WithTimeout(ctx, 15*time.Minute)
-    defer cancel()
+    crawlID := newCrawlID(ctx)
+    log.Printf("Crawl ID: %s", crawlID)
 
-    var wg sync.WaitGroup
-    initialData := &InitialData{}
-    var fetchErr error
-    var mu sync.Mutex
+    client, err := backend.NewClient(cfg)
+    if err != nil {
+        log.Printf("Error configuring backend client: %v", err)
+        return "", err
+    }
+    store := sink.NewSpoolStore(cfg, awsConfig)
+    sinks, err := sink.FromConfig(cfg, awsConfig, client, store)
+    if err != nil {
+        log.Printf("Error configuring sinks: %v", err)
+        return "", err
+    }
 
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        instances, err := awsfetch.FetchEC2Instances(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
-        }
-        mu.Lock()
-        initialData.EC2Instances = instances
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        vpcs, subnets, routeTables, natGateways, internetGateways, err := awsfetch.FetchVPCData(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
-        }
-        mu.Lock()
-        initialData.VPCs = vpcs
-        initialData.Subnets = subnets
-        initialData.RouteTables = routeTables
-        initialData.NATGateways = natGateways
-        initialData.InternetGateways = internetGateways
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        buckets, err := awsfetch.FetchS3Buckets(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
-        }
-        mu.Lock()
-        initialData.S3Buckets = buckets
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        rds, err := awsfetch.FetchRDSInstances(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
-        }
-        mu.Lock()
-        initialData.RDSInstances = rds
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        zones, err := awsfetch.FetchRoute53Zones(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
-        }
-        mu.Lock()
-        initialData.Route53Zones = zones
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        groups, err := awsfetch.FetchAutoScalingGroups(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
+    // Instructions from the backend may have changed the regions to crawl.
+    settings := &command.Settings{}
+    if store != nil {
+        if settings, err = command.LoadSettings(ctx, store); err != nil {
+            log.Printf("Error loading settings: %v", err)
+            settings = &command.Settings{}
         }
-        mu.Lock()
-        initialData.AutoScalingGroups = groups
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        lbs, err := awsfetch.FetchLoadBalancers(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
-        }
-        mu.Lock()
-        initialData.LoadBalancers = lbs
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        eks, err := awsfetch.FetchEKSClusters(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
-        }
-        mu.Lock()
-        initialData.EKSClusters = eks
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        users, policies, err := awsfetch.FetchIAMData(crawlCtx, awsConfig)
-        if err != nil {
-            fetchErr = err
-            return
+    }
+    regions := settings.Regions
+    if len(regions) == 0 {
+        regions = []string{cfg.AWSRegion}
+    }
+
+    crawlCtx, cancel := context.WithTimeout(ctx, 15*time.Minute)
+    defer cancel()
+
+    c := &crawler{
+        crawlID:   crawlID,
+        agentID:   cfg.AgentID,
+        awsConfig: awsConfig,
+        sinks:     sinks,
+        regions:   regions,
+        store:     store,
+        settings:  settings,
+    }
+    var crawlErrs []error
+    var enabledRegions []string
+    for _, region := range regions {
+        id := crawlID
+        if len(regions) > 1 {
+            id += "-" + region
         }
-        mu.Lock()
-        initialData.IAMUsers = users
-        initialData.IAMPolicies = policies
-        mu.Unlock()
-    }()
-
-    wg.Add(1)
-    go func() {
-        defer wg.Done()
-        caches, err := awsfetch.FetchElastiCaches(crawlCtx, awsConfig)
+        data, err := c.crawlAndDeliver(crawlCtx, id, region, nil)
         if err != nil {
-            fetchErr = err
-            return
+            crawlErrs = append(crawlErrs, err)
+            continue
         }
-        mu.Lock()
-        initialData.ElastiCaches = caches
-        mu.Unlock()
-    }()
-
-    wg.Wait()
-    if fetchErr != nil {
-        log.Printf("Error during resource fetching: %v", fetchErr)
-        return "", fetchErr
+        enabledRegions = data.Account.EnabledRegions
     }
 
-    payload, err := json.Marshal(initialData)
-    if err != nil {
-        log.Printf("Error marshaling initial data: %v", err)
-        return "", err
+    if len(enabledRegions) == 0 {
+        // No crawl could read the account; only the configured regions are known.
+        enabledRegions = regions
     }
 
-    err = backend.SendInitialCrawlResults(cfg.BackendEndpoint, payload)
+    instructions, err := client.Instructions(crawlCtx)
     if err != nil {
-        log.Printf("Error sending initial crawl results: %v", err)
+        log.Printf("Error receiving instructions: %v", err)
+    }
+    if len(instructions) > 0 {
+        c.runInstructions(crawlCtx, command.Policy{
+            Allowed:      cfg.InstructionsAllowed,
+            Services:     serviceNames(),
+            Regions:      enabledRegions,
+            ScheduleName: cfg.CrawlScheduleName,
+        }, instructions)
+    }
+
+    if err := errors.Join(crawlErrs...); err != nil {
         return "", err
     }
 
@@ -225,6 +125,17 @@ func handler(ctx context.Context) (string, error) {
     return "Crawl completed successfully", nil
 }
 
+// newCrawlID returns the Lambda request ID when invoked by Lambda and a
+// random ID otherwise.
+func newCrawlID(ctx context.Context) string {
+    if lc, ok := lambdacontext.FromContext(ctx); ok && lc.AwsRequestID != "" {
+        return lc.AwsRequestID
+    }
+    b := make([]byte, 16)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
+
 func main() {
     lambda.Start(handler)
 }
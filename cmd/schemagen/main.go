@@ -0,0 +1,65 @@
+// Command schemagen writes the JSON Schema of the crawl payload, generated
+// from the Go types in internal/payload.
+//
+// With -check it instead verifies the contract: that the published schema is
+// up to date with the types, and that fixture crawls marshalled by this build
+// validate against it.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"log"
+	"os"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/payload"
+	"github.com/DavisAndn/go-aws-crawler/internal/schema"
+)
+
+func main() {
+	out := flag.String("o", "schema/crawl-payload.schema.json", "schema file to write or check")
+	check := flag.Bool("check", false, "check the schema file and fixtures instead of writing the file")
+	flag.Parse()
+	log.SetFlags(0)
+
+	generated, err := payload.SchemaJSON()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if !*check {
+		if err := os.WriteFile(*out, generated, 0o644); err != nil {
+			log.Fatalf("Failed to write schema: %v", err)
+		}
+		return
+	}
+
+	published, err := os.ReadFile(*out)
+	if err != nil {
+		log.Fatalf("Failed to read schema: %v", err)
+	}
+	if !bytes.Equal(published, generated) {
+		log.Fatalf("%s is out of date with the payload types; run go generate ./internal/payload", *out)
+	}
+	s, err := schema.Parse(published)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fixtures, err := payload.Fixtures()
+	if err != nil {
+		log.Fatal(err)
+	}
+	failed := false
+	for _, f := range fixtures {
+		if err := s.Validate(f.Data); err != nil {
+			log.Printf("FAIL %s:\n%v", f.Name, err)
+			failed = true
+			continue
+		}
+		log.Printf("ok   %s", f.Name)
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
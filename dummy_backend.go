@@ -2,10 +2,63 @@
 package main
 
 import (
+	"crypto/rand"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	mathrand "math/rand/v2"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/backend"
+	"github.com/DavisAndn/go-aws-crawler/internal/envelope"
+	crawlpayload "github.com/DavisAndn/go-aws-crawler/internal/payload"
+	"github.com/DavisAndn/go-aws-crawler/internal/schema"
+)
+
+// issuedTokenLifetime is the lifetime of tokens handed out by /api/token.
+const issuedTokenLifetime = 5 * time.Minute
+
+// Authentication settings, read from the same variables the crawler uses.
+// Each check is skipped when its variable is unset.
+var (
+	signingSecret = []byte(os.Getenv("BACKEND_SIGNING_SECRET"))
+	staticToken   = os.Getenv("BACKEND_TOKEN")
+	clientID      = os.Getenv("BACKEND_CLIENT_ID")
+	clientSecret  = os.Getenv("BACKEND_CLIENT_SECRET")
+
+	nonces = backend.NewNonceCache()
+
+	tokensMu sync.Mutex
+	tokens   = make(map[string]time.Time) // Issued token to expiry.
+
+	// failureRate is the fraction of deliveries answered with 503, to
+	// exercise the crawler's retries.
+	failureRate, _ = strconv.ParseFloat(os.Getenv("DUMMY_FAILURE_RATE"), 64)
+
+	// acceptEncodings lists the request encodings the backend decompresses;
+	// others are answered with 415 to exercise the crawler's fallback.
+	acceptEncodings = envOr("DUMMY_ACCEPT_ENCODINGS", "gzip,zstd")
+
+	// instructions is a JSON array of instructions returned with every
+	// accepted crawl and from /api/instructions.
+	instructions = json.RawMessage(os.Getenv("DUMMY_INSTRUCTIONS"))
+
+	// payloadSchema, loaded from DUMMY_SCHEMA_FILE, rejects crawls that do
+	// not validate against it. Nil accepts every crawl.
+	payloadSchema *schema.Schema
+
+	deliveredMu sync.Mutex
+	delivered   = make(map[string]bool) // Idempotency keys already accepted.
 )
 
 // handler processes incoming POST requests to /api/aws-resources.
@@ -16,29 +69,331 @@ func handler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	body, ok := receive(w, r)
+	if !ok {
+		return
+	}
+	accept(w, r.Header.Get(backend.HeaderIdempotencyKey), r.Header.Get(backend.HeaderAgentID), body)
+}
+
+// receive reads the request body and applies the authentication, signature
+// and failure-injection checks, answering the request itself when one fails.
+func receive(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
 	// Read the request body
 	body, err := io.ReadAll(r.Body)
 	if err != nil {
 		http.Error(w, "Cannot read body", http.StatusBadRequest)
+		return nil, false
+	}
+
+	if !authorized(r) {
+		log.Println("Rejected request with missing or invalid bearer token")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+	if len(signingSecret) > 0 {
+		if err := backend.VerifyRequest(r, body, signingSecret, nonces); err != nil {
+			log.Printf("Rejected request with bad signature: %v", err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return nil, false
+		}
+	}
+
+	if failureRate > 0 && mathrand.Float64() < failureRate {
+		log.Println("Simulating a transient failure")
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "Service unavailable", http.StatusServiceUnavailable)
+		return nil, false
+	}
+
+	// Signatures cover the body as sent, so decompress only after verifying.
+	if encoding := r.Header.Get("Content-Encoding"); encoding != "" {
+		if !accepted(encoding) {
+			log.Printf("Rejected request with unsupported encoding %q", encoding)
+			w.Header().Set("Accept-Encoding", acceptEncodings)
+			http.Error(w, "Unsupported content encoding", http.StatusUnsupportedMediaType)
+			return nil, false
+		}
+		if body, err = backend.Decompress(encoding, body); err != nil {
+			http.Error(w, "Cannot decompress body", http.StatusBadRequest)
+			return nil, false
+		}
+	}
+	return body, true
+}
+
+// accepted reports whether encoding is listed in acceptEncodings.
+func accepted(encoding string) bool {
+	for _, e := range strings.Split(acceptEncodings, ",") {
+		if strings.EqualFold(strings.TrimSpace(e), encoding) {
+			return true
+		}
+	}
+	return false
+}
+
+// envOr returns the environment variable name, or def when it is unset.
+func envOr(name, def string) string {
+	if v, ok := os.LookupEnv(name); ok {
+		return v
+	}
+	return def
+}
+
+// accept logs a complete crawl payload unless the crawl was already delivered.
+func accept(w http.ResponseWriter, crawlID, agentID string, body []byte) {
+	// Upgrade payloads of older crawler builds to the current schema
+	payload, version, err := envelope.Migrate(body, crawlpayload.Payload{})
+	if err != nil {
+		log.Printf("Rejected crawl %s: %v", crawlID, err)
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	if payloadSchema != nil {
+		if err := payloadSchema.Validate(payload); err != nil {
+			log.Printf("Rejected crawl %s, it does not match the schema:\n%v", crawlID, err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	deliveredMu.Lock()
+	duplicate := delivered[crawlID]
+	if crawlID != "" {
+		delivered[crawlID] = true
+	}
+	deliveredMu.Unlock()
+	if duplicate {
+		log.Printf("Ignoring duplicate delivery of crawl %s", crawlID)
+		fmt.Fprintln(w, "Duplicate delivery ignored")
 		return
 	}
 
 	// Log the received payload
-	log.Println("Received data:")
-	fmt.Println(string(body))
+	log.Printf("Received crawl %s (schema version %d) from agent %q:", crawlID, version, agentID)
+	fmt.Println(string(payload))
 
-	// Respond with OK
+	// Respond with OK, handing out any instructions
+	if len(instructions) > 0 {
+		writeInstructions(w)
+		return
+	}
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintln(w, "Data received successfully")
 }
 
+// instructionsHandler answers instruction polls.
+func instructionsHandler(w http.ResponseWriter, r *http.Request) {
+	if _, ok := receive(w, r); !ok {
+		return
+	}
+	writeInstructions(w)
+}
+
+func writeInstructions(w http.ResponseWriter) {
+	list := instructions
+	if len(list) == 0 {
+		list = json.RawMessage("[]")
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]json.RawMessage{"instructions": list})
+}
+
+// uploadSession holds the batches of a chunked upload until it is committed.
+type uploadSession struct {
+	crawlID string
+	agentID string
+	batches map[int]backend.Batch
+}
+
+var (
+	sessionsMu     sync.Mutex
+	sessions       = make(map[string]*uploadSession)
+	sessionByCrawl = make(map[string]string)
+)
+
+// openSessionHandler opens a chunked upload session. Reopening a session for
+// the same crawl returns the existing one.
+func openSessionHandler(w http.ResponseWriter, r *http.Request) {
+	body, ok := receive(w, r)
+	if !ok {
+		return
+	}
+	var req backend.OpenSessionRequest
+	if err := json.Unmarshal(body, &req); err != nil || req.CrawlID == "" {
+		http.Error(w, "Invalid session request", http.StatusBadRequest)
+		return
+	}
+
+	sessionsMu.Lock()
+	id, ok := sessionByCrawl[req.CrawlID]
+	if !ok {
+		raw := make([]byte, 16)
+		rand.Read(raw)
+		id = hex.EncodeToString(raw)
+		sessions[id] = &uploadSession{crawlID: req.CrawlID, agentID: req.AgentID, batches: make(map[int]backend.Batch)}
+		sessionByCrawl[req.CrawlID] = id
+	}
+	sessionsMu.Unlock()
+
+	log.Printf("Opened upload session %s for crawl %s", id, req.CrawlID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(backend.OpenSessionResponse{SessionID: id})
+}
+
+// batchHandler stores one batch. A re-sent batch replaces the earlier copy.
+func batchHandler(w http.ResponseWriter, r *http.Request) {
+	body, ok := receive(w, r)
+	if !ok {
+		return
+	}
+	seq, err := strconv.Atoi(r.PathValue("seq"))
+	var batch backend.Batch
+	if err != nil || json.Unmarshal(body, &batch) != nil || batch.Sequence != seq {
+		http.Error(w, "Invalid batch", http.StatusBadRequest)
+		return
+	}
+
+	sessionsMu.Lock()
+	defer sessionsMu.Unlock()
+	session, ok := sessions[r.PathValue("id")]
+	if !ok {
+		http.Error(w, "Unknown session", http.StatusNotFound)
+		return
+	}
+	session.batches[seq] = batch
+	fmt.Fprintln(w, "Batch received")
+}
+
+// commitHandler reassembles the batches of a session into the payload a
+// single-request upload would have sent.
+func commitHandler(w http.ResponseWriter, r *http.Request) {
+	body, ok := receive(w, r)
+	if !ok {
+		return
+	}
+	var commit backend.CommitRequest
+	if err := json.Unmarshal(body, &commit); err != nil {
+		http.Error(w, "Invalid commit", http.StatusBadRequest)
+		return
+	}
+
+	sessionsMu.Lock()
+	id := r.PathValue("id")
+	session, ok := sessions[id]
+	if !ok {
+		sessionsMu.Unlock()
+		http.Error(w, "Unknown session", http.StatusNotFound)
+		return
+	}
+	payload, err := backend.Reassemble(session.batches, commit)
+	if err == nil {
+		delete(sessions, id)
+		delete(sessionByCrawl, session.crawlID)
+	}
+	sessionsMu.Unlock()
+	if err != nil {
+		log.Printf("Rejected commit of session %s: %v", id, err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	log.Printf("Reassembled crawl %s from %d batches", session.crawlID, commit.BatchCount)
+	accept(w, session.crawlID, session.agentID, payload)
+}
+
+// authorized reports whether the request carries an accepted bearer token:
+// the static token or one issued by tokenHandler.
+func authorized(r *http.Request) bool {
+	if staticToken == "" && clientID == "" {
+		return true
+	}
+	var token string
+	if _, err := fmt.Sscanf(r.Header.Get("Authorization"), "Bearer %s", &token); err != nil {
+		return false
+	}
+	if staticToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(staticToken)) == 1 {
+		return true
+	}
+	tokensMu.Lock()
+	defer tokensMu.Unlock()
+	expires, ok := tokens[token]
+	return ok && time.Now().Before(expires)
+}
+
+// tokenHandler implements the client credentials token exchange.
+func tokenHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if clientID == "" || r.PostFormValue("grant_type") != "client_credentials" ||
+		subtle.ConstantTimeCompare([]byte(r.PostFormValue("client_id")), []byte(clientID)) != 1 ||
+		subtle.ConstantTimeCompare([]byte(r.PostFormValue("client_secret")), []byte(clientSecret)) != 1 {
+		http.Error(w, "Invalid client", http.StatusUnauthorized)
+		return
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		http.Error(w, "Cannot issue token", http.StatusInternalServerError)
+		return
+	}
+	token := hex.EncodeToString(raw)
+	tokensMu.Lock()
+	tokens[token] = time.Now().Add(issuedTokenLifetime)
+	tokensMu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   int(issuedTokenLifetime.Seconds()),
+	})
+}
+
 func main() {
-	// Set up the route
+	if path := os.Getenv("DUMMY_SCHEMA_FILE"); path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to read payload schema: %v", err)
+		}
+		if payloadSchema, err = schema.Parse(data); err != nil {
+			log.Fatal(err)
+		}
+		log.Printf("Validating crawls against %s", path)
+	}
+
+	// Set up the routes
 	http.HandleFunc("/api/aws-resources", handler)
+	http.HandleFunc("/api/token", tokenHandler)
+	http.HandleFunc("GET /api/instructions", instructionsHandler)
+	http.HandleFunc("POST /api/aws-resources/sessions", openSessionHandler)
+	http.HandleFunc("PUT /api/aws-resources/sessions/{id}/batches/{seq}", batchHandler)
+	http.HandleFunc("POST /api/aws-resources/sessions/{id}/commit", commitHandler)
 
-	// Start the HTTP server on port 8181
-	log.Println("Dummy backend listening on :8181")
-	err := http.ListenAndServe(":8181", nil)
+	// Serve HTTPS when a certificate is configured, requiring client
+	// certificates signed by DUMMY_CLIENT_CA_FILE when that is set too.
+	server := &http.Server{Addr: ":8181"}
+	certFile, keyFile := os.Getenv("DUMMY_TLS_CERT_FILE"), os.Getenv("DUMMY_TLS_KEY_FILE")
+	if caFile := os.Getenv("DUMMY_CLIENT_CA_FILE"); caFile != "" {
+		pem, err := os.ReadFile(caFile)
+		if err != nil {
+			log.Fatalf("Failed to read client CA: %v", err)
+		}
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(pem)
+		server.TLSConfig = &tls.Config{ClientCAs: pool, ClientAuth: tls.RequireAndVerifyClientCert}
+	}
+
+	var err error
+	if certFile != "" {
+		log.Println("Dummy backend listening with TLS on :8181")
+		err = server.ListenAndServeTLS(certFile, keyFile)
+	} else {
+		log.Println("Dummy backend listening on :8181")
+		err = server.ListenAndServe()
+	}
 	if err != nil {
 		log.Fatalf("Failed to start server: %v", err)
 	}
@@ -3,23 +3,62 @@ module github.com/DavisAndn/go-aws-crawler
 go 1.23.6
 
 require (
+	github.com/aws/aws-lambda-go v1.47.0
 	github.com/aws/aws-sdk-go-v2 v1.36.1
 	github.com/aws/aws-sdk-go-v2/config v1.29.6
+	github.com/aws/aws-sdk-go-v2/service/accessanalyzer v1.37.0
+	github.com/aws/aws-sdk-go-v2/service/acm v1.30.18
+	github.com/aws/aws-sdk-go-v2/service/apigateway v1.28.11
+	github.com/aws/aws-sdk-go-v2/service/apigatewayv2 v1.25.0
 	github.com/aws/aws-sdk-go-v2/service/autoscaling v1.51.12
+	github.com/aws/aws-sdk-go-v2/service/backup v1.40.10
+	github.com/aws/aws-sdk-go-v2/service/cloudformation v1.57.0
+	github.com/aws/aws-sdk-go-v2/service/cloudfront v1.44.10
+	github.com/aws/aws-sdk-go-v2/service/cloudwatch v1.43.14
+	github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs v1.45.13
+	github.com/aws/aws-sdk-go-v2/service/cognitoidentity v1.28.5
+	github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider v1.49.4
+	github.com/aws/aws-sdk-go-v2/service/directconnect v1.30.12
+	github.com/aws/aws-sdk-go-v2/service/dynamodb v1.40.1
 	github.com/aws/aws-sdk-go-v2/service/ec2 v1.202.4
+	github.com/aws/aws-sdk-go-v2/service/ecr v1.41.0
+	github.com/aws/aws-sdk-go-v2/service/ecs v1.53.13
+	github.com/aws/aws-sdk-go-v2/service/efs v1.34.11
 	github.com/aws/aws-sdk-go-v2/service/eks v1.58.0
 	github.com/aws/aws-sdk-go-v2/service/elasticache v1.44.12
 	github.com/aws/aws-sdk-go-v2/service/elasticloadbalancing v1.28.17
 	github.com/aws/aws-sdk-go-v2/service/elasticloadbalancingv2 v1.43.12
+	github.com/aws/aws-sdk-go-v2/service/eventbridge v1.36.11
+	github.com/aws/aws-sdk-go-v2/service/firehose v1.36.4
+	github.com/aws/aws-sdk-go-v2/service/fsx v1.52.0
+	github.com/aws/aws-sdk-go-v2/service/guardduty v1.52.10
 	github.com/aws/aws-sdk-go-v2/service/iam v1.39.1
+	github.com/aws/aws-sdk-go-v2/service/inspector2 v1.34.9
+	github.com/aws/aws-sdk-go-v2/service/kafka v1.38.16
+	github.com/aws/aws-sdk-go-v2/service/kinesis v1.32.19
+	github.com/aws/aws-sdk-go-v2/service/lambda v1.69.13
+	github.com/aws/aws-sdk-go-v2/service/opensearch v1.45.11
+	github.com/aws/aws-sdk-go-v2/service/organizations v1.37.8
 	github.com/aws/aws-sdk-go-v2/service/rds v1.93.12
+	github.com/aws/aws-sdk-go-v2/service/redshift v1.53.12
+	github.com/aws/aws-sdk-go-v2/service/redshiftserverless v1.25.0
 	github.com/aws/aws-sdk-go-v2/service/route53 v1.48.7
 	github.com/aws/aws-sdk-go-v2/service/s3 v1.76.1
+	github.com/aws/aws-sdk-go-v2/service/scheduler v1.12.17
+	github.com/aws/aws-sdk-go-v2/service/securityhub v1.55.10
+	github.com/aws/aws-sdk-go-v2/service/servicequotas v1.25.18
+	github.com/aws/aws-sdk-go-v2/service/sfn v1.34.12
+	github.com/aws/aws-sdk-go-v2/service/shield v1.29.0
+	github.com/aws/aws-sdk-go-v2/service/sqs v1.37.14
+	github.com/aws/aws-sdk-go-v2/service/ssm v1.56.12
+	github.com/aws/aws-sdk-go-v2/service/sts v1.33.14
+	github.com/aws/aws-sdk-go-v2/service/wafv2 v1.56.0
+	github.com/aws/smithy-go v1.22.2
+	github.com/klauspost/compress v1.18.0
 )
 
 require (
-	github.com/aws/aws-lambda-go v1.47.0 // indirect
-	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.6.8 // indirect
+	github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream v1.6.9 // indirect
 	github.com/aws/aws-sdk-go-v2/credentials v1.17.59 // indirect
 	github.com/aws/aws-sdk-go-v2/feature/ec2/imds v1.16.28 // indirect
 	github.com/aws/aws-sdk-go-v2/internal/configsources v1.3.32 // indirect
@@ -28,10 +67,9 @@ require (
 	github.com/aws/aws-sdk-go-v2/internal/v4a v1.3.32 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/accept-encoding v1.12.2 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/checksum v1.6.0 // indirect
+	github.com/aws/aws-sdk-go-v2/service/internal/endpoint-discovery v1.10.13 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/presigned-url v1.12.13 // indirect
 	github.com/aws/aws-sdk-go-v2/service/internal/s3shared v1.18.13 // indirect
 	github.com/aws/aws-sdk-go-v2/service/sso v1.24.15 // indirect
 	github.com/aws/aws-sdk-go-v2/service/ssooidc v1.28.14 // indirect
-	github.com/aws/aws-sdk-go-v2/service/sts v1.33.14 // indirect
-	github.com/aws/smithy-go v1.22.2 // indirect
 )
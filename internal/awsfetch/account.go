@@ -0,0 +1,168 @@
+package awsfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/organizations"
+	orgtypes "github.com/aws/aws-sdk-go-v2/service/organizations/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// AccountInfo identifies the account a crawl ran against and summarises its
+// account-level security settings.
+type AccountInfo struct {
+	AccountID      string             `json:"AccountId"`
+	AccountAlias   string             `json:"AccountAlias"`
+	Partition      string             `json:"Partition"`
+	Region         string             `json:"Region"`
+	PrincipalArn   string             `json:"PrincipalArn"` // The identity the crawler ran as.
+	OrganizationID string             `json:"OrganizationId"`
+	ManagementID   string             `json:"ManagementAccountId"`
+	OUPath         []string           `json:"OUPath"` // Root first; empty when not visible to this account.
+	PasswordPolicy *IAMPasswordPolicy `json:"PasswordPolicy"`
+	RootMFAEnabled bool               `json:"RootMFAEnabled"`
+	RootAccessKeys bool               `json:"RootAccessKeysPresent"`
+	AccountSummary map[string]int32   `json:"AccountSummary"`
+	EnabledRegions []string           `json:"EnabledRegions"`
+}
+
+// IAMPasswordPolicy represents the account's IAM password policy.
+type IAMPasswordPolicy struct {
+	MinimumPasswordLength      int32 `json:"MinimumPasswordLength"`
+	RequireSymbols             bool  `json:"RequireSymbols"`
+	RequireNumbers             bool  `json:"RequireNumbers"`
+	RequireUppercaseCharacters bool  `json:"RequireUppercaseCharacters"`
+	RequireLowercaseCharacters bool  `json:"RequireLowercaseCharacters"`
+	AllowUsersToChangePassword bool  `json:"AllowUsersToChangePassword"`
+	ExpirePasswords            bool  `json:"ExpirePasswords"`
+	MaxPasswordAge             int32 `json:"MaxPasswordAge"`
+	PasswordReusePrevention    int32 `json:"PasswordReusePrevention"`
+	HardExpiry                 bool  `json:"HardExpiry"`
+}
+
+// FetchAccountInfo retrieves the caller identity and account-level settings.
+// Organization details are left empty when the account is not in an
+// organization or may not read it.
+func FetchAccountInfo(ctx context.Context, cfg aws.Config) (AccountInfo, error) {
+	info := AccountInfo{Region: cfg.Region}
+
+	identity, err := sts.NewFromConfig(cfg).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return info, fmt.Errorf("error fetching caller identity: %w", err)
+	}
+	info.AccountID = aws.ToString(identity.Account)
+	info.PrincipalArn = aws.ToString(identity.Arn)
+	if parts := strings.SplitN(info.PrincipalArn, ":", 3); len(parts) == 3 {
+		info.Partition = parts[1]
+	}
+
+	iamClient := iam.NewFromConfig(cfg)
+	aliases, err := iamClient.ListAccountAliases(ctx, &iam.ListAccountAliasesInput{})
+	if err != nil {
+		return info, fmt.Errorf("error fetching account aliases: %w", err)
+	}
+	// An account has at most one alias.
+	if len(aliases.AccountAliases) > 0 {
+		info.AccountAlias = aliases.AccountAliases[0]
+	}
+
+	policy, err := iamClient.GetAccountPasswordPolicy(ctx, &iam.GetAccountPasswordPolicyInput{})
+	var noPolicy *iamtypes.NoSuchEntityException
+	switch {
+	case errors.As(err, &noPolicy):
+	case err != nil:
+		return info, fmt.Errorf("error fetching password policy: %w", err)
+	default:
+		p := policy.PasswordPolicy
+		info.PasswordPolicy = &IAMPasswordPolicy{
+			MinimumPasswordLength:      aws.ToInt32(p.MinimumPasswordLength),
+			RequireSymbols:             p.RequireSymbols,
+			RequireNumbers:             p.RequireNumbers,
+			RequireUppercaseCharacters: p.RequireUppercaseCharacters,
+			RequireLowercaseCharacters: p.RequireLowercaseCharacters,
+			AllowUsersToChangePassword: p.AllowUsersToChangePassword,
+			ExpirePasswords:            p.ExpirePasswords,
+			MaxPasswordAge:             aws.ToInt32(p.MaxPasswordAge),
+			PasswordReusePrevention:    aws.ToInt32(p.PasswordReusePrevention),
+			HardExpiry:                 aws.ToBool(p.HardExpiry),
+		}
+	}
+
+	summary, err := iamClient.GetAccountSummary(ctx, &iam.GetAccountSummaryInput{})
+	if err != nil {
+		return info, fmt.Errorf("error fetching account summary: %w", err)
+	}
+	info.AccountSummary = summary.SummaryMap
+	info.RootMFAEnabled = summary.SummaryMap["AccountMFAEnabled"] == 1
+	info.RootAccessKeys = summary.SummaryMap["AccountAccessKeysPresent"] == 1
+
+	// Without AllRegions, DescribeRegions only returns regions enabled for the account.
+	regions, err := ec2.NewFromConfig(cfg).DescribeRegions(ctx, &ec2.DescribeRegionsInput{})
+	if err != nil {
+		return info, fmt.Errorf("error fetching enabled regions: %w", err)
+	}
+	for _, r := range regions.Regions {
+		info.EnabledRegions = append(info.EnabledRegions, aws.ToString(r.RegionName))
+	}
+
+	if err := fetchOrganizationInfo(ctx, organizations.NewFromConfig(cfg), &info); err != nil {
+		return info, err
+	}
+	return info, nil
+}
+
+func fetchOrganizationInfo(ctx context.Context, client *organizations.Client, info *AccountInfo) error {
+	var notInUse *orgtypes.AWSOrganizationsNotInUseException
+	var denied *orgtypes.AccessDeniedException
+
+	org, err := client.DescribeOrganization(ctx, &organizations.DescribeOrganizationInput{})
+	switch {
+	case errors.As(err, &notInUse), errors.As(err, &denied):
+		return nil
+	case err != nil:
+		return fmt.Errorf("error fetching organization: %w", err)
+	}
+	info.OrganizationID = aws.ToString(org.Organization.Id)
+	info.ManagementID = aws.ToString(org.Organization.MasterAccountId)
+
+	// ListParents is only permitted from the management account or a
+	// delegated administrator; other accounts report no OU path.
+	var path []string
+	childID := info.AccountID
+	for {
+		parents, err := client.ListParents(ctx, &organizations.ListParentsInput{ChildId: &childID})
+		if errors.As(err, &denied) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error fetching organization parents of %s: %w", childID, err)
+		}
+		if len(parents.Parents) == 0 {
+			break
+		}
+		parent := parents.Parents[0]
+		childID = aws.ToString(parent.Id)
+		if parent.Type == orgtypes.ParentTypeRoot {
+			path = append(path, "Root")
+			break
+		}
+		ou, err := client.DescribeOrganizationalUnit(ctx, &organizations.DescribeOrganizationalUnitInput{OrganizationalUnitId: parent.Id})
+		if err != nil {
+			return fmt.Errorf("error describing organizational unit %s: %w", childID, err)
+		}
+		path = append(path, aws.ToString(ou.OrganizationalUnit.Name))
+	}
+	// The walk went from the account up; report the path from the root down.
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	info.OUPath = path
+	return nil
+}
@@ -0,0 +1,126 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/acm"
+	acmtypes "github.com/aws/aws-sdk-go-v2/service/acm/types"
+)
+
+// ACMCertificate represents an ACM certificate and the resources using it.
+// InUseBy is split into LoadBalancerArns, CloudFrontDistributionIDs and
+// APIGatewayDomainNames so the certificate can be joined to those inventories.
+type ACMCertificate struct {
+	CertificateArn            string     `json:"CertificateArn"`
+	DomainName                string     `json:"DomainName"`
+	SubjectAlternativeNames   []string   `json:"SubjectAlternativeNames"`
+	Status                    string     `json:"Status"`
+	Type                      string     `json:"Type"`
+	KeyAlgorithm              string     `json:"KeyAlgorithm"`
+	Issuer                    string     `json:"Issuer"`
+	NotBefore                 *time.Time `json:"NotBefore"`
+	NotAfter                  *time.Time `json:"NotAfter"`
+	RenewalEligibility        string     `json:"RenewalEligibility"`
+	RenewalStatus             string     `json:"RenewalStatus"`
+	InUseBy                   []string   `json:"InUseBy"`
+	LoadBalancerArns          []string   `json:"LoadBalancerArns"`
+	CloudFrontDistributionIDs []string   `json:"CloudFrontDistributionIds"`
+	APIGatewayDomainNames     []string   `json:"ApiGatewayDomainNames"`
+}
+
+// FetchACMCertificates retrieves all ACM certificates in the region.
+func FetchACMCertificates(ctx context.Context, cfg aws.Config) ([]ACMCertificate, error) {
+	client := acm.NewFromConfig(cfg)
+	// ListCertificates only returns RSA_1024 and RSA_2048 certificates unless
+	// other key types are requested explicitly.
+	paginator := acm.NewListCertificatesPaginator(client, &acm.ListCertificatesInput{
+		Includes: &acmtypes.Filters{KeyTypes: acmtypes.KeyAlgorithm("").Values()},
+	})
+	var certs []ACMCertificate
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ACM certificates: %w", err)
+		}
+		for _, summary := range page.CertificateSummaryList {
+			desc, err := client.DescribeCertificate(ctx, &acm.DescribeCertificateInput{
+				CertificateArn: summary.CertificateArn,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error describing ACM certificate %s: %w", aws.ToString(summary.CertificateArn), err)
+			}
+			c := desc.Certificate
+			cert := ACMCertificate{
+				CertificateArn:          aws.ToString(c.CertificateArn),
+				DomainName:              aws.ToString(c.DomainName),
+				SubjectAlternativeNames: c.SubjectAlternativeNames,
+				Status:                  string(c.Status),
+				Type:                    string(c.Type),
+				KeyAlgorithm:            string(c.KeyAlgorithm),
+				Issuer:                  aws.ToString(c.Issuer),
+				NotBefore:               c.NotBefore,
+				NotAfter:                c.NotAfter,
+				RenewalEligibility:      string(c.RenewalEligibility),
+				InUseBy:                 c.InUseBy,
+			}
+			if c.RenewalSummary != nil {
+				cert.RenewalStatus = string(c.RenewalSummary.RenewalStatus)
+			}
+			for _, arn := range c.InUseBy {
+				switch {
+				case strings.Contains(arn, ":elasticloadbalancing:"):
+					cert.LoadBalancerArns = append(cert.LoadBalancerArns, arn)
+				case strings.Contains(arn, ":cloudfront::"):
+					cert.CloudFrontDistributionIDs = append(cert.CloudFrontDistributionIDs, arn[strings.LastIndex(arn, "/")+1:])
+				case strings.Contains(arn, ":apigateway:") && strings.Contains(arn, "/domainnames/"):
+					cert.APIGatewayDomainNames = append(cert.APIGatewayDomainNames, arn[strings.LastIndex(arn, "/")+1:])
+				}
+			}
+			certs = append(certs, cert)
+		}
+	}
+	return certs, nil
+}
+
+// LinkACMCertificates records on each load balancer the certificates in use by
+// it, and on each API Gateway domain name without a certificate ARN the
+// certificate ACM reports in use by it.
+func LinkACMCertificates(certs []ACMCertificate, lbs []LoadBalancer, domains []APIGatewayDomainName) {
+	byLoadBalancer := make(map[string][]string)
+	byDomain := make(map[string]string)
+	for _, cert := range certs {
+		for _, arn := range cert.LoadBalancerArns {
+			// Classic load balancers are crawled without an ARN; their ARNs
+			// end in "loadbalancer/<name>", where others add a type and ID.
+			key := arn
+			if _, resource, _ := strings.Cut(arn, ":loadbalancer/"); resource != "" && !strings.Contains(resource, "/") {
+				key = classicLoadBalancerKey(resource)
+			}
+			byLoadBalancer[key] = append(byLoadBalancer[key], cert.CertificateArn)
+		}
+		for _, name := range cert.APIGatewayDomainNames {
+			byDomain[name] = cert.CertificateArn
+		}
+	}
+	for i := range lbs {
+		key := lbs[i].LoadBalancerArn
+		if lbs[i].Type == "classic" {
+			key = classicLoadBalancerKey(lbs[i].LoadBalancerName)
+		}
+		lbs[i].CertificateArns = byLoadBalancer[key]
+	}
+	for i := range domains {
+		if domains[i].CertificateArn == "" {
+			domains[i].CertificateArn = byDomain[domains[i].DomainName]
+		}
+	}
+}
+
+func classicLoadBalancerKey(name string) string {
+	return "classic/" + name
+}
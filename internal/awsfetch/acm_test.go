@@ -0,0 +1,34 @@
+package awsfetch
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLinkACMCertificates(t *testing.T) {
+	certs := []ACMCertificate{
+		{CertificateArn: "cert-1", LoadBalancerArns: []string{
+			"arn:aws:elasticloadbalancing:us-east-1:111:loadbalancer/app/web/0123",
+			"arn:aws:elasticloadbalancing:us-east-1:111:loadbalancer/legacy",
+		}},
+		{CertificateArn: "cert-2", LoadBalancerArns: []string{"arn:aws:elasticloadbalancing:us-east-1:111:loadbalancer/legacy"}},
+		{CertificateArn: "cert-3", APIGatewayDomainNames: []string{"api.example.com"}},
+	}
+	lbs := []LoadBalancer{
+		{LoadBalancerName: "web", LoadBalancerArn: "arn:aws:elasticloadbalancing:us-east-1:111:loadbalancer/app/web/0123", Type: "application"},
+		{LoadBalancerName: "legacy", Type: "classic"},
+		{LoadBalancerName: "app", Type: "classic"},
+	}
+	domains := []APIGatewayDomainName{{DomainName: "api.example.com"}, {DomainName: "own.example.com", CertificateArn: "cert-9"}}
+
+	LinkACMCertificates(certs, lbs, domains)
+
+	for i, want := range [][]string{{"cert-1"}, {"cert-1", "cert-2"}, nil} {
+		if !slices.Equal(lbs[i].CertificateArns, want) {
+			t.Errorf("%s: CertificateArns = %q, want %q", lbs[i].LoadBalancerName, lbs[i].CertificateArns, want)
+		}
+	}
+	if domains[0].CertificateArn != "cert-3" || domains[1].CertificateArn != "cert-9" {
+		t.Errorf("domain certificates = %q, %q, want cert-3, cert-9", domains[0].CertificateArn, domains[1].CertificateArn)
+	}
+}
@@ -0,0 +1,226 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigateway"
+	apigwtypes "github.com/aws/aws-sdk-go-v2/service/apigateway/types"
+)
+
+// APIGatewayRestAPI represents an API Gateway REST API.
+type APIGatewayRestAPI struct {
+	ID             string                 `json:"Id"`
+	Name           string                 `json:"Name"`
+	EndpointTypes  []string               `json:"EndpointTypes"`
+	VpcEndpointIDs []string               `json:"VpcEndpointIds"`
+	ApiKeySource   string                 `json:"ApiKeySource"`
+	Stages         []APIGatewayStage      `json:"Stages"`
+	Resources      []APIGatewayResource   `json:"Resources"`
+	Authorizers    []APIGatewayAuthorizer `json:"Authorizers"`
+	Tags           map[string]string      `json:"Tags"`
+}
+
+// APIGatewayStage represents a deployed stage of a REST API.
+type APIGatewayStage struct {
+	StageName      string `json:"StageName"`
+	DeploymentID   string `json:"DeploymentId"`
+	WebACLArn      string `json:"WebAclArn"`
+	TracingEnabled bool   `json:"TracingEnabled"`
+}
+
+// APIGatewayResource represents a REST API resource path and its methods.
+type APIGatewayResource struct {
+	ID      string             `json:"Id"`
+	Path    string             `json:"Path"`
+	Methods []APIGatewayMethod `json:"Methods"`
+}
+
+// APIGatewayMethod represents a method on a REST API resource and its integration.
+type APIGatewayMethod struct {
+	HTTPMethod        string `json:"HttpMethod"`
+	AuthorizationType string `json:"AuthorizationType"`
+	AuthorizerID      string `json:"AuthorizerId"`
+	ApiKeyRequired    bool   `json:"ApiKeyRequired"`
+	IntegrationType   string `json:"IntegrationType"`
+	IntegrationURI    string `json:"IntegrationUri"`
+	ConnectionType    string `json:"ConnectionType"`
+	VpcLinkID         string `json:"VpcLinkId"`
+	LambdaFunctionArn string `json:"LambdaFunctionArn"`
+}
+
+// APIGatewayAuthorizer represents a REST API authorizer.
+type APIGatewayAuthorizer struct {
+	ID                string   `json:"Id"`
+	Name              string   `json:"Name"`
+	Type              string   `json:"Type"`
+	ProviderARNs      []string `json:"ProviderARNs"`
+	LambdaFunctionArn string   `json:"LambdaFunctionArn"`
+}
+
+// APIGatewayVpcLink represents a REST API VPC link to network load balancers.
+type APIGatewayVpcLink struct {
+	ID               string   `json:"Id"`
+	Name             string   `json:"Name"`
+	Status           string   `json:"Status"`
+	LoadBalancerArns []string `json:"LoadBalancerArns"`
+}
+
+// FetchAPIGatewayRestAPIs retrieves REST APIs with their stages, resources and authorizers, and REST VPC links.
+func FetchAPIGatewayRestAPIs(ctx context.Context, cfg aws.Config) ([]APIGatewayRestAPI, []APIGatewayVpcLink, error) {
+	client := apigateway.NewFromConfig(cfg)
+
+	var apis []APIGatewayRestAPI
+	paginator := apigateway.NewGetRestApisPaginator(client, &apigateway.GetRestApisInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error fetching API Gateway REST APIs: %w", err)
+		}
+		for _, item := range page.Items {
+			api := APIGatewayRestAPI{
+				ID:           aws.ToString(item.Id),
+				Name:         aws.ToString(item.Name),
+				ApiKeySource: string(item.ApiKeySource),
+				Tags:         item.Tags,
+			}
+			if item.EndpointConfiguration != nil {
+				for _, t := range item.EndpointConfiguration.Types {
+					api.EndpointTypes = append(api.EndpointTypes, string(t))
+				}
+				api.VpcEndpointIDs = item.EndpointConfiguration.VpcEndpointIds
+			}
+
+			api.Stages, err = fetchRestAPIStages(ctx, client, api.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			api.Resources, err = fetchRestAPIResources(ctx, client, api.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			api.Authorizers, err = fetchRestAPIAuthorizers(ctx, client, api.ID)
+			if err != nil {
+				return nil, nil, err
+			}
+			apis = append(apis, api)
+		}
+	}
+
+	var vpcLinks []APIGatewayVpcLink
+	linkPaginator := apigateway.NewGetVpcLinksPaginator(client, &apigateway.GetVpcLinksInput{})
+	for linkPaginator.HasMorePages() {
+		page, err := linkPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error fetching API Gateway VPC links: %w", err)
+		}
+		for _, link := range page.Items {
+			vpcLinks = append(vpcLinks, APIGatewayVpcLink{
+				ID:               aws.ToString(link.Id),
+				Name:             aws.ToString(link.Name),
+				Status:           string(link.Status),
+				LoadBalancerArns: link.TargetArns,
+			})
+		}
+	}
+
+	return apis, vpcLinks, nil
+}
+
+func fetchRestAPIStages(ctx context.Context, client *apigateway.Client, apiID string) ([]APIGatewayStage, error) {
+	out, err := client.GetStages(ctx, &apigateway.GetStagesInput{RestApiId: &apiID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching stages for REST API %s: %w", apiID, err)
+	}
+	var stages []APIGatewayStage
+	for _, s := range out.Item {
+		stages = append(stages, APIGatewayStage{
+			StageName:      aws.ToString(s.StageName),
+			DeploymentID:   aws.ToString(s.DeploymentId),
+			WebACLArn:      aws.ToString(s.WebAclArn),
+			TracingEnabled: s.TracingEnabled,
+		})
+	}
+	return stages, nil
+}
+
+func fetchRestAPIResources(ctx context.Context, client *apigateway.Client, apiID string) ([]APIGatewayResource, error) {
+	paginator := apigateway.NewGetResourcesPaginator(client, &apigateway.GetResourcesInput{
+		RestApiId: &apiID,
+		Embed:     []string{"methods"},
+	})
+	var resources []APIGatewayResource
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching resources for REST API %s: %w", apiID, err)
+		}
+		for _, r := range page.Items {
+			resource := APIGatewayResource{
+				ID:   aws.ToString(r.Id),
+				Path: aws.ToString(r.Path),
+			}
+			for httpMethod, m := range r.ResourceMethods {
+				method := APIGatewayMethod{
+					HTTPMethod:        httpMethod,
+					AuthorizationType: aws.ToString(m.AuthorizationType),
+					AuthorizerID:      aws.ToString(m.AuthorizerId),
+					ApiKeyRequired:    aws.ToBool(m.ApiKeyRequired),
+				}
+				if integ := m.MethodIntegration; integ != nil {
+					method.IntegrationType = string(integ.Type)
+					method.IntegrationURI = aws.ToString(integ.Uri)
+					method.ConnectionType = string(integ.ConnectionType)
+					if integ.ConnectionType == apigwtypes.ConnectionTypeVpcLink {
+						method.VpcLinkID = aws.ToString(integ.ConnectionId)
+					}
+					method.LambdaFunctionArn = lambdaArnFromIntegrationURI(method.IntegrationURI)
+				}
+				resource.Methods = append(resource.Methods, method)
+			}
+			resources = append(resources, resource)
+		}
+	}
+	return resources, nil
+}
+
+func fetchRestAPIAuthorizers(ctx context.Context, client *apigateway.Client, apiID string) ([]APIGatewayAuthorizer, error) {
+	var authorizers []APIGatewayAuthorizer
+	input := &apigateway.GetAuthorizersInput{RestApiId: &apiID}
+	for {
+		out, err := client.GetAuthorizers(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching authorizers for REST API %s: %w", apiID, err)
+		}
+		for _, a := range out.Items {
+			authorizers = append(authorizers, APIGatewayAuthorizer{
+				ID:                aws.ToString(a.Id),
+				Name:              aws.ToString(a.Name),
+				Type:              string(a.Type),
+				ProviderARNs:      a.ProviderARNs,
+				LambdaFunctionArn: lambdaArnFromIntegrationURI(aws.ToString(a.AuthorizerUri)),
+			})
+		}
+		if out.Position == nil {
+			break
+		}
+		input.Position = out.Position
+	}
+	return authorizers, nil
+}
+
+// lambdaArnFromIntegrationURI extracts the Lambda function ARN from an API Gateway
+// integration or authorizer URI. Plain Lambda ARNs, as used by HTTP API
+// integrations, are returned unchanged.
+func lambdaArnFromIntegrationURI(uri string) string {
+	if strings.HasPrefix(uri, "arn:") && strings.Contains(uri, ":lambda:") && strings.Contains(uri, ":function:") {
+		if i := strings.Index(uri, "/functions/"); i >= 0 {
+			fn := uri[i+len("/functions/"):]
+			return strings.TrimSuffix(fn, "/invocations")
+		}
+		return uri
+	}
+	return ""
+}
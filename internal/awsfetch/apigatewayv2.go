@@ -0,0 +1,286 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/apigatewayv2"
+	apigwv2types "github.com/aws/aws-sdk-go-v2/service/apigatewayv2/types"
+)
+
+// APIGatewayV2API represents an API Gateway HTTP or WebSocket API.
+type APIGatewayV2API struct {
+	APIID        string                    `json:"ApiId"`
+	Name         string                    `json:"Name"`
+	ProtocolType string                    `json:"ProtocolType"`
+	APIEndpoint  string                    `json:"ApiEndpoint"`
+	Routes       []APIGatewayV2Route       `json:"Routes"`
+	Integrations []APIGatewayV2Integration `json:"Integrations"`
+	Stages       []APIGatewayV2Stage       `json:"Stages"`
+	Tags         map[string]string         `json:"Tags"`
+}
+
+// APIGatewayV2Route represents a route of an HTTP or WebSocket API.
+type APIGatewayV2Route struct {
+	RouteID           string `json:"RouteId"`
+	RouteKey          string `json:"RouteKey"`
+	AuthorizationType string `json:"AuthorizationType"`
+	AuthorizerID      string `json:"AuthorizerId"`
+	IntegrationID     string `json:"IntegrationId"`
+}
+
+// APIGatewayV2Integration represents an integration of an HTTP or WebSocket API.
+type APIGatewayV2Integration struct {
+	IntegrationID     string `json:"IntegrationId"`
+	IntegrationType   string `json:"IntegrationType"`
+	IntegrationURI    string `json:"IntegrationUri"`
+	ConnectionType    string `json:"ConnectionType"`
+	VpcLinkID         string `json:"VpcLinkId"`
+	LambdaFunctionArn string `json:"LambdaFunctionArn"`
+	LoadBalancerArn   string `json:"LoadBalancerArn"`
+}
+
+// APIGatewayV2Stage represents a stage of an HTTP or WebSocket API.
+type APIGatewayV2Stage struct {
+	StageName    string `json:"StageName"`
+	DeploymentID string `json:"DeploymentId"`
+	AutoDeploy   bool   `json:"AutoDeploy"`
+}
+
+// APIGatewayV2VpcLink represents an HTTP API VPC link.
+type APIGatewayV2VpcLink struct {
+	VpcLinkID        string   `json:"VpcLinkId"`
+	Name             string   `json:"Name"`
+	Status           string   `json:"Status"`
+	SubnetIDs        []string `json:"SubnetIds"`
+	SecurityGroupIDs []string `json:"SecurityGroupIds"`
+}
+
+// APIGatewayDomainName represents an API Gateway custom domain name.
+type APIGatewayDomainName struct {
+	DomainName           string                 `json:"DomainName"`
+	EndpointType         string                 `json:"EndpointType"`
+	ApiGatewayDomainName string                 `json:"ApiGatewayDomainName"`
+	HostedZoneID         string                 `json:"HostedZoneId"`
+	CertificateArn       string                 `json:"CertificateArn"`
+	SecurityPolicy       string                 `json:"SecurityPolicy"`
+	APIMappings          []APIGatewayAPIMapping `json:"ApiMappings"`
+}
+
+// APIGatewayAPIMapping maps a custom domain path to an API stage.
+type APIGatewayAPIMapping struct {
+	APIID         string `json:"ApiId"`
+	Stage         string `json:"Stage"`
+	APIMappingKey string `json:"ApiMappingKey"`
+}
+
+// FetchAPIGatewayV2APIs retrieves HTTP and WebSocket APIs with their routes, integrations and stages, and HTTP API VPC links.
+func FetchAPIGatewayV2APIs(ctx context.Context, cfg aws.Config) ([]APIGatewayV2API, []APIGatewayV2VpcLink, error) {
+	client := apigatewayv2.NewFromConfig(cfg)
+
+	var apis []APIGatewayV2API
+	input := &apigatewayv2.GetApisInput{}
+	for {
+		out, err := client.GetApis(ctx, input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error fetching API Gateway v2 APIs: %w", err)
+		}
+		for _, item := range out.Items {
+			api := APIGatewayV2API{
+				APIID:        aws.ToString(item.ApiId),
+				Name:         aws.ToString(item.Name),
+				ProtocolType: string(item.ProtocolType),
+				APIEndpoint:  aws.ToString(item.ApiEndpoint),
+				Tags:         item.Tags,
+			}
+			api.Routes, err = fetchV2Routes(ctx, client, api.APIID)
+			if err != nil {
+				return nil, nil, err
+			}
+			api.Integrations, err = fetchV2Integrations(ctx, client, api.APIID)
+			if err != nil {
+				return nil, nil, err
+			}
+			api.Stages, err = fetchV2Stages(ctx, client, api.APIID)
+			if err != nil {
+				return nil, nil, err
+			}
+			apis = append(apis, api)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	var vpcLinks []APIGatewayV2VpcLink
+	linkInput := &apigatewayv2.GetVpcLinksInput{}
+	for {
+		out, err := client.GetVpcLinks(ctx, linkInput)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error fetching API Gateway v2 VPC links: %w", err)
+		}
+		for _, link := range out.Items {
+			vpcLinks = append(vpcLinks, APIGatewayV2VpcLink{
+				VpcLinkID:        aws.ToString(link.VpcLinkId),
+				Name:             aws.ToString(link.Name),
+				Status:           string(link.VpcLinkStatus),
+				SubnetIDs:        link.SubnetIds,
+				SecurityGroupIDs: link.SecurityGroupIds,
+			})
+		}
+		if out.NextToken == nil {
+			break
+		}
+		linkInput.NextToken = out.NextToken
+	}
+
+	return apis, vpcLinks, nil
+}
+
+func fetchV2Routes(ctx context.Context, client *apigatewayv2.Client, apiID string) ([]APIGatewayV2Route, error) {
+	var routes []APIGatewayV2Route
+	input := &apigatewayv2.GetRoutesInput{ApiId: &apiID}
+	for {
+		out, err := client.GetRoutes(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching routes for API %s: %w", apiID, err)
+		}
+		for _, r := range out.Items {
+			target := aws.ToString(r.Target)
+			routes = append(routes, APIGatewayV2Route{
+				RouteID:           aws.ToString(r.RouteId),
+				RouteKey:          aws.ToString(r.RouteKey),
+				AuthorizationType: string(r.AuthorizationType),
+				AuthorizerID:      aws.ToString(r.AuthorizerId),
+				IntegrationID:     strings.TrimPrefix(target, "integrations/"),
+			})
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return routes, nil
+}
+
+func fetchV2Integrations(ctx context.Context, client *apigatewayv2.Client, apiID string) ([]APIGatewayV2Integration, error) {
+	var integrations []APIGatewayV2Integration
+	input := &apigatewayv2.GetIntegrationsInput{ApiId: &apiID}
+	for {
+		out, err := client.GetIntegrations(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching integrations for API %s: %w", apiID, err)
+		}
+		for _, i := range out.Items {
+			integ := APIGatewayV2Integration{
+				IntegrationID:   aws.ToString(i.IntegrationId),
+				IntegrationType: string(i.IntegrationType),
+				IntegrationURI:  aws.ToString(i.IntegrationUri),
+				ConnectionType:  string(i.ConnectionType),
+			}
+			integ.LambdaFunctionArn = lambdaArnFromIntegrationURI(integ.IntegrationURI)
+			if i.ConnectionType == apigwv2types.ConnectionTypeVpcLink {
+				integ.VpcLinkID = aws.ToString(i.ConnectionId)
+				integ.LoadBalancerArn = loadBalancerArnFromListenerArn(integ.IntegrationURI)
+			}
+			integrations = append(integrations, integ)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return integrations, nil
+}
+
+func fetchV2Stages(ctx context.Context, client *apigatewayv2.Client, apiID string) ([]APIGatewayV2Stage, error) {
+	var stages []APIGatewayV2Stage
+	input := &apigatewayv2.GetStagesInput{ApiId: &apiID}
+	for {
+		out, err := client.GetStages(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching stages for API %s: %w", apiID, err)
+		}
+		for _, s := range out.Items {
+			stages = append(stages, APIGatewayV2Stage{
+				StageName:    aws.ToString(s.StageName),
+				DeploymentID: aws.ToString(s.DeploymentId),
+				AutoDeploy:   aws.ToBool(s.AutoDeploy),
+			})
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return stages, nil
+}
+
+// FetchAPIGatewayDomainNames retrieves custom domain names and their API mappings.
+// The v2 API lists domains for REST, HTTP and WebSocket APIs alike.
+func FetchAPIGatewayDomainNames(ctx context.Context, cfg aws.Config) ([]APIGatewayDomainName, error) {
+	client := apigatewayv2.NewFromConfig(cfg)
+
+	var domains []APIGatewayDomainName
+	input := &apigatewayv2.GetDomainNamesInput{}
+	for {
+		out, err := client.GetDomainNames(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching API Gateway domain names: %w", err)
+		}
+		for _, d := range out.Items {
+			domain := APIGatewayDomainName{DomainName: aws.ToString(d.DomainName)}
+			if len(d.DomainNameConfigurations) > 0 {
+				conf := d.DomainNameConfigurations[0]
+				domain.EndpointType = string(conf.EndpointType)
+				domain.ApiGatewayDomainName = aws.ToString(conf.ApiGatewayDomainName)
+				domain.HostedZoneID = aws.ToString(conf.HostedZoneId)
+				domain.CertificateArn = aws.ToString(conf.CertificateArn)
+				domain.SecurityPolicy = string(conf.SecurityPolicy)
+			}
+
+			mapInput := &apigatewayv2.GetApiMappingsInput{DomainName: d.DomainName}
+			for {
+				mapOut, err := client.GetApiMappings(ctx, mapInput)
+				if err != nil {
+					return nil, fmt.Errorf("error fetching API mappings for domain %s: %w", domain.DomainName, err)
+				}
+				for _, m := range mapOut.Items {
+					domain.APIMappings = append(domain.APIMappings, APIGatewayAPIMapping{
+						APIID:         aws.ToString(m.ApiId),
+						Stage:         aws.ToString(m.Stage),
+						APIMappingKey: aws.ToString(m.ApiMappingKey),
+					})
+				}
+				if mapOut.NextToken == nil {
+					break
+				}
+				mapInput.NextToken = mapOut.NextToken
+			}
+			domains = append(domains, domain)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return domains, nil
+}
+
+// loadBalancerArnFromListenerArn derives the load balancer ARN from an ELBv2
+// listener ARN, e.g. ".../listener/app/name/id/listener-id" becomes
+// ".../loadbalancer/app/name/id". Other ARNs yield an empty string.
+func loadBalancerArnFromListenerArn(listenerArn string) string {
+	i := strings.Index(listenerArn, ":listener/")
+	if i < 0 || !strings.HasPrefix(listenerArn, "arn:") {
+		return ""
+	}
+	rest := listenerArn[i+len(":listener/"):]
+	if j := strings.LastIndex(rest, "/"); j >= 0 {
+		rest = rest[:j]
+	}
+	return listenerArn[:i] + ":loadbalancer/" + rest
+}
@@ -0,0 +1,479 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/backup"
+	backuptypes "github.com/aws/aws-sdk-go-v2/service/backup/types"
+)
+
+// BackupVault represents an AWS Backup vault and the recovery points stored in it.
+type BackupVault struct {
+	BackupVaultName        string                `json:"BackupVaultName"`
+	BackupVaultArn         string                `json:"BackupVaultArn"`
+	VaultType              string                `json:"VaultType"`
+	EncryptionKeyArn       string                `json:"EncryptionKeyArn"`
+	Locked                 bool                  `json:"Locked"`
+	MinRetentionDays       int64                 `json:"MinRetentionDays"`
+	MaxRetentionDays       int64                 `json:"MaxRetentionDays"`
+	NumberOfRecoveryPoints int64                 `json:"NumberOfRecoveryPoints"`
+	RecoveryPoints         []BackupRecoveryPoint `json:"RecoveryPoints"`
+}
+
+// BackupRecoveryPoint represents a backup of a single resource.
+type BackupRecoveryPoint struct {
+	RecoveryPointArn  string     `json:"RecoveryPointArn"`
+	ResourceArn       string     `json:"ResourceArn"`
+	ResourceType      string     `json:"ResourceType"`
+	Status            string     `json:"Status"`
+	CreationDate      *time.Time `json:"CreationDate"`
+	BackupSizeInBytes int64      `json:"BackupSizeInBytes"`
+	IsEncrypted       bool       `json:"IsEncrypted"`
+	BackupPlanID      string     `json:"BackupPlanId"`
+	DeleteAt          *time.Time `json:"DeleteAt"`
+}
+
+// BackupPlan represents an AWS Backup plan with its rules and resource selections.
+type BackupPlan struct {
+	BackupPlanID      string            `json:"BackupPlanId"`
+	BackupPlanArn     string            `json:"BackupPlanArn"`
+	BackupPlanName    string            `json:"BackupPlanName"`
+	VersionID         string            `json:"VersionId"`
+	LastExecutionDate *time.Time        `json:"LastExecutionDate"`
+	Rules             []BackupRule      `json:"Rules"`
+	Selections        []BackupSelection `json:"Selections"`
+}
+
+// BackupRule represents a scheduled rule of a backup plan.
+type BackupRule struct {
+	RuleName               string   `json:"RuleName"`
+	TargetBackupVaultName  string   `json:"TargetBackupVaultName"`
+	ScheduleExpression     string   `json:"ScheduleExpression"`
+	EnableContinuousBackup bool     `json:"EnableContinuousBackup"`
+	DeleteAfterDays        int64    `json:"DeleteAfterDays"`
+	MoveToColdStorageDays  int64    `json:"MoveToColdStorageAfterDays"`
+	CopyDestinationVaults  []string `json:"CopyDestinationVaultArns"`
+}
+
+// BackupSelection represents the set of resources assigned to a backup plan,
+// either by ARN (wildcards allowed) or by tag.
+type BackupSelection struct {
+	SelectionID   string            `json:"SelectionId"`
+	SelectionName string            `json:"SelectionName"`
+	IamRoleArn    string            `json:"IamRoleArn"`
+	Resources     []string          `json:"Resources"`
+	NotResources  []string          `json:"NotResources"`
+	TagConditions []BackupCondition `json:"TagConditions"`
+}
+
+// BackupCondition is a tag condition of a backup selection. Conditions from
+// the selection's ListOfTags have the operator "STRINGEQUALS" and select a
+// resource when any of them holds; the others ("StringEquals", "StringLike",
+// "StringNotEquals", "StringNotLike") must all hold.
+type BackupCondition struct {
+	Operator string `json:"Operator"`
+	Key      string `json:"Key"`
+	Value    string `json:"Value"`
+}
+
+// BackupProtectedResource represents a resource that has at least one recovery point.
+type BackupProtectedResource struct {
+	ResourceArn          string     `json:"ResourceArn"`
+	ResourceType         string     `json:"ResourceType"`
+	ResourceName         string     `json:"ResourceName"`
+	LastBackupTime       *time.Time `json:"LastBackupTime"`
+	LastBackupVaultArn   string     `json:"LastBackupVaultArn"`
+	LastRecoveryPointArn string     `json:"LastRecoveryPointArn"`
+}
+
+// BackupCoverage describes how a resource is covered by AWS Backup.
+type BackupCoverage struct {
+	BackupPlanIDs  []string   `json:"BackupPlanIds"`
+	LastBackupTime *time.Time `json:"LastBackupTime"`
+}
+
+// FetchBackupVaults retrieves backup vaults with their recovery points.
+func FetchBackupVaults(ctx context.Context, cfg aws.Config) ([]BackupVault, error) {
+	client := backup.NewFromConfig(cfg)
+	paginator := backup.NewListBackupVaultsPaginator(client, &backup.ListBackupVaultsInput{})
+	var vaults []BackupVault
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching backup vaults: %w", err)
+		}
+		for _, v := range page.BackupVaultList {
+			vault := BackupVault{
+				BackupVaultName:        aws.ToString(v.BackupVaultName),
+				BackupVaultArn:         aws.ToString(v.BackupVaultArn),
+				VaultType:              string(v.VaultType),
+				EncryptionKeyArn:       aws.ToString(v.EncryptionKeyArn),
+				Locked:                 aws.ToBool(v.Locked),
+				MinRetentionDays:       aws.ToInt64(v.MinRetentionDays),
+				MaxRetentionDays:       aws.ToInt64(v.MaxRetentionDays),
+				NumberOfRecoveryPoints: v.NumberOfRecoveryPoints,
+			}
+			vault.RecoveryPoints, err = fetchRecoveryPoints(ctx, client, vault.BackupVaultName)
+			if err != nil {
+				return nil, err
+			}
+			vaults = append(vaults, vault)
+		}
+	}
+	return vaults, nil
+}
+
+func fetchRecoveryPoints(ctx context.Context, client *backup.Client, vaultName string) ([]BackupRecoveryPoint, error) {
+	paginator := backup.NewListRecoveryPointsByBackupVaultPaginator(client, &backup.ListRecoveryPointsByBackupVaultInput{
+		BackupVaultName: &vaultName,
+	})
+	var points []BackupRecoveryPoint
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching recovery points for backup vault %s: %w", vaultName, err)
+		}
+		for _, rp := range page.RecoveryPoints {
+			point := BackupRecoveryPoint{
+				RecoveryPointArn:  aws.ToString(rp.RecoveryPointArn),
+				ResourceArn:       aws.ToString(rp.ResourceArn),
+				ResourceType:      aws.ToString(rp.ResourceType),
+				Status:            string(rp.Status),
+				CreationDate:      rp.CreationDate,
+				BackupSizeInBytes: aws.ToInt64(rp.BackupSizeInBytes),
+				IsEncrypted:       rp.IsEncrypted,
+			}
+			if rp.CreatedBy != nil {
+				point.BackupPlanID = aws.ToString(rp.CreatedBy.BackupPlanId)
+			}
+			if rp.CalculatedLifecycle != nil {
+				point.DeleteAt = rp.CalculatedLifecycle.DeleteAt
+			}
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
+
+// FetchBackupPlans retrieves backup plans with their rules and selections.
+func FetchBackupPlans(ctx context.Context, cfg aws.Config) ([]BackupPlan, error) {
+	client := backup.NewFromConfig(cfg)
+	paginator := backup.NewListBackupPlansPaginator(client, &backup.ListBackupPlansInput{})
+	var plans []BackupPlan
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching backup plans: %w", err)
+		}
+		for _, p := range page.BackupPlansList {
+			plan := BackupPlan{
+				BackupPlanID:      aws.ToString(p.BackupPlanId),
+				BackupPlanArn:     aws.ToString(p.BackupPlanArn),
+				BackupPlanName:    aws.ToString(p.BackupPlanName),
+				VersionID:         aws.ToString(p.VersionId),
+				LastExecutionDate: p.LastExecutionDate,
+			}
+
+			out, err := client.GetBackupPlan(ctx, &backup.GetBackupPlanInput{BackupPlanId: p.BackupPlanId})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching backup plan %s: %w", plan.BackupPlanName, err)
+			}
+			if out.BackupPlan != nil {
+				for _, r := range out.BackupPlan.Rules {
+					rule := BackupRule{
+						RuleName:               aws.ToString(r.RuleName),
+						TargetBackupVaultName:  aws.ToString(r.TargetBackupVaultName),
+						ScheduleExpression:     aws.ToString(r.ScheduleExpression),
+						EnableContinuousBackup: aws.ToBool(r.EnableContinuousBackup),
+					}
+					if r.Lifecycle != nil {
+						rule.DeleteAfterDays = aws.ToInt64(r.Lifecycle.DeleteAfterDays)
+						rule.MoveToColdStorageDays = aws.ToInt64(r.Lifecycle.MoveToColdStorageAfterDays)
+					}
+					for _, c := range r.CopyActions {
+						rule.CopyDestinationVaults = append(rule.CopyDestinationVaults, aws.ToString(c.DestinationBackupVaultArn))
+					}
+					plan.Rules = append(plan.Rules, rule)
+				}
+			}
+
+			plan.Selections, err = fetchBackupSelections(ctx, client, plan.BackupPlanID)
+			if err != nil {
+				return nil, err
+			}
+			plans = append(plans, plan)
+		}
+	}
+	return plans, nil
+}
+
+func fetchBackupSelections(ctx context.Context, client *backup.Client, planID string) ([]BackupSelection, error) {
+	paginator := backup.NewListBackupSelectionsPaginator(client, &backup.ListBackupSelectionsInput{BackupPlanId: &planID})
+	var selections []BackupSelection
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing selections of backup plan %s: %w", planID, err)
+		}
+		for _, s := range page.BackupSelectionsList {
+			out, err := client.GetBackupSelection(ctx, &backup.GetBackupSelectionInput{
+				BackupPlanId: &planID,
+				SelectionId:  s.SelectionId,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching backup selection %s: %w", aws.ToString(s.SelectionId), err)
+			}
+			selection := BackupSelection{
+				SelectionID:   aws.ToString(s.SelectionId),
+				SelectionName: aws.ToString(s.SelectionName),
+				IamRoleArn:    aws.ToString(s.IamRoleArn),
+			}
+			if sel := out.BackupSelection; sel != nil {
+				selection.Resources = sel.Resources
+				selection.NotResources = sel.NotResources
+				for _, c := range sel.ListOfTags {
+					selection.TagConditions = append(selection.TagConditions, BackupCondition{
+						Operator: string(c.ConditionType),
+						Key:      aws.ToString(c.ConditionKey),
+						Value:    aws.ToString(c.ConditionValue),
+					})
+				}
+				if c := sel.Conditions; c != nil {
+					selection.TagConditions = appendBackupConditions(selection.TagConditions, "StringEquals", c.StringEquals)
+					selection.TagConditions = appendBackupConditions(selection.TagConditions, "StringNotEquals", c.StringNotEquals)
+					selection.TagConditions = appendBackupConditions(selection.TagConditions, "StringLike", c.StringLike)
+					selection.TagConditions = appendBackupConditions(selection.TagConditions, "StringNotLike", c.StringNotLike)
+				}
+			}
+			selections = append(selections, selection)
+		}
+	}
+	return selections, nil
+}
+
+func appendBackupConditions(conditions []BackupCondition, operator string, params []backuptypes.ConditionParameter) []BackupCondition {
+	for _, p := range params {
+		conditions = append(conditions, BackupCondition{
+			Operator: operator,
+			Key:      aws.ToString(p.ConditionKey),
+			Value:    aws.ToString(p.ConditionValue),
+		})
+	}
+	return conditions
+}
+
+// FetchBackupProtectedResources retrieves every resource that has been backed up by AWS Backup.
+func FetchBackupProtectedResources(ctx context.Context, cfg aws.Config) ([]BackupProtectedResource, error) {
+	client := backup.NewFromConfig(cfg)
+	paginator := backup.NewListProtectedResourcesPaginator(client, &backup.ListProtectedResourcesInput{})
+	var resources []BackupProtectedResource
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching backup protected resources: %w", err)
+		}
+		for _, r := range page.Results {
+			resources = append(resources, BackupProtectedResource{
+				ResourceArn:          aws.ToString(r.ResourceArn),
+				ResourceType:         aws.ToString(r.ResourceType),
+				ResourceName:         aws.ToString(r.ResourceName),
+				LastBackupTime:       r.LastBackupTime,
+				LastBackupVaultArn:   aws.ToString(r.LastBackupVaultArn),
+				LastRecoveryPointArn: aws.ToString(r.LastRecoveryPointArn),
+			})
+		}
+	}
+	return resources, nil
+}
+
+// BackupInventory is the part of the crawled inventory that backup plans
+// select resources from.
+type BackupInventory struct {
+	Account        AccountInfo // Completes EBS volume ARNs.
+	RDSInstances   []RDSInstance
+	EBSVolumes     []EBSVolume
+	EFSFileSystems []EFSFileSystem
+	DynamoDBTables []DynamoDBTable
+}
+
+// backupCandidate is a crawled resource a backup selection may select.
+type backupCandidate struct {
+	arn  string
+	tags map[string]string
+}
+
+func (inv BackupInventory) candidates() []backupCandidate {
+	var candidates []backupCandidate
+	for _, db := range inv.RDSInstances {
+		candidates = append(candidates, backupCandidate{db.DBInstanceArn, db.Tags})
+	}
+	if inv.Account.AccountID != "" {
+		for _, v := range inv.EBSVolumes {
+			arn := fmt.Sprintf("arn:%s:ec2:%s:%s:volume/%s", inv.Account.Partition, inv.Account.Region, inv.Account.AccountID, v.VolumeID)
+			candidates = append(candidates, backupCandidate{arn, v.Tags})
+		}
+	}
+	for _, fs := range inv.EFSFileSystems {
+		candidates = append(candidates, backupCandidate{fs.FileSystemArn, fs.Tags})
+	}
+	for _, t := range inv.DynamoDBTables {
+		candidates = append(candidates, backupCandidate{t.TableArn, t.Tags})
+	}
+	return candidates
+}
+
+// BackupCoverageIndex maps resource ARNs to the backup plans that cover them.
+// A resource is covered by a plan when one of the plan's selections selects
+// it, evaluated against the crawled inventory and its tags, or when the plan
+// created one of its recovery points. Selections naming an ARN without
+// wildcards cover it even when it was not crawled, unless a tag condition
+// needs its tags.
+func BackupCoverageIndex(plans []BackupPlan, vaults []BackupVault, protected []BackupProtectedResource, inv BackupInventory) map[string]BackupCoverage {
+	index := make(map[string]BackupCoverage)
+	addPlan := func(arn, planID string) {
+		coverage := index[arn]
+		if planID != "" && !containsString(coverage.BackupPlanIDs, planID) {
+			coverage.BackupPlanIDs = append(coverage.BackupPlanIDs, planID)
+		}
+		index[arn] = coverage
+	}
+
+	for _, v := range vaults {
+		for _, rp := range v.RecoveryPoints {
+			addPlan(rp.ResourceArn, rp.BackupPlanID)
+		}
+	}
+	for _, r := range protected {
+		coverage := index[r.ResourceArn]
+		coverage.LastBackupTime = r.LastBackupTime
+		index[r.ResourceArn] = coverage
+	}
+
+	// Resources known to AWS Backup or named by a selection but not crawled
+	// are matched by ARN only.
+	candidates := inv.candidates()
+	known := make(map[string]bool)
+	for _, c := range candidates {
+		known[c.arn] = true
+	}
+	addKnown := func(arn string) {
+		if !known[arn] {
+			known[arn] = true
+			candidates = append(candidates, backupCandidate{arn: arn})
+		}
+	}
+	for arn := range index {
+		addKnown(arn)
+	}
+	for _, p := range plans {
+		for _, s := range p.Selections {
+			for _, pattern := range s.Resources {
+				if !strings.Contains(pattern, "*") {
+					addKnown(pattern)
+				}
+			}
+		}
+	}
+
+	for _, p := range plans {
+		for _, s := range p.Selections {
+			for _, c := range candidates {
+				if s.selects(c) {
+					addPlan(c.arn, p.BackupPlanID)
+				}
+			}
+		}
+	}
+	return index
+}
+
+// selects reports whether the selection assigns c to its plan. A resource is
+// selected when it matches Resources or any ListOfTags condition, matches
+// every other tag condition, and matches none of NotResources. A selection by
+// tag conditions alone applies to every resource.
+func (s BackupSelection) selects(c backupCandidate) bool {
+	if c.arn == "" || matchesAnyArnPattern(c.arn, s.NotResources) {
+		return false
+	}
+	inResources := matchesAnyArnPattern(c.arn, s.Resources)
+	if len(s.Resources) == 0 && len(s.TagConditions) > 0 {
+		inResources = !s.hasListOfTags()
+	}
+	return s.matchesTags(c.tags, inResources)
+}
+
+// matchesTags evaluates the tag conditions against tags; selected is whether
+// the resource is already selected by ARN. With tags nil only conditions that
+// cannot fail without tags pass.
+func (s BackupSelection) matchesTags(tags map[string]string, selected bool) bool {
+	for _, c := range s.TagConditions {
+		value, ok := tags[strings.TrimPrefix(c.Key, "aws:ResourceTag/")]
+		switch c.Operator {
+		case "STRINGEQUALS":
+			selected = selected || (ok && value == c.Value)
+		case "StringEquals":
+			if !ok || value != c.Value {
+				return false
+			}
+		case "StringLike":
+			if !ok || !matchArnPattern(c.Value, value) {
+				return false
+			}
+		case "StringNotEquals":
+			if ok && value == c.Value {
+				return false
+			}
+		case "StringNotLike":
+			if ok && matchArnPattern(c.Value, value) {
+				return false
+			}
+		}
+	}
+	return selected
+}
+
+func (s BackupSelection) hasListOfTags() bool {
+	for _, c := range s.TagConditions {
+		if c.Operator == "STRINGEQUALS" {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAnyArnPattern(arn string, patterns []string) bool {
+	for _, p := range patterns {
+		if matchArnPattern(p, arn) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchArnPattern reports whether arn matches pattern, where "*" matches any
+// run of characters. It also matches tag values against StringLike patterns.
+func matchArnPattern(pattern, arn string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == arn
+	}
+	if !strings.HasPrefix(arn, parts[0]) {
+		return false
+	}
+	arn = arn[len(parts[0]):]
+	for _, part := range parts[1 : len(parts)-1] {
+		i := strings.Index(arn, part)
+		if i < 0 {
+			return false
+		}
+		arn = arn[i+len(part):]
+	}
+	return strings.HasSuffix(arn, parts[len(parts)-1])
+}
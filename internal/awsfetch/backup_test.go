@@ -0,0 +1,144 @@
+package awsfetch
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestMatchArnPattern(t *testing.T) {
+	tests := []struct {
+		pattern, arn string
+		want         bool
+	}{
+		{"arn:aws:rds:us-east-1:111:db:orders", "arn:aws:rds:us-east-1:111:db:orders", true},
+		{"arn:aws:rds:us-east-1:111:db:orders", "arn:aws:rds:us-east-1:111:db:orders-2", false},
+		{"*", "anything", true},
+		{"arn:aws:rds:*:*:db:*", "arn:aws:rds:eu-west-1:111:db:orders", true},
+		{"arn:aws:rds:*:*:db:*", "arn:aws:dynamodb:eu-west-1:111:table/orders", false},
+		{"arn:aws:dynamodb:*:*:table/prod-*", "arn:aws:dynamodb:us-east-1:111:table/prod-orders", true},
+		{"arn:aws:dynamodb:*:*:table/prod-*", "arn:aws:dynamodb:us-east-1:111:table/dev-orders", false},
+		{"*-prod", "orders-prod", true},
+		{"ab*bc", "abc", false}, // Prefix and suffix must not overlap.
+		{"a*b*c", "a-c-b", false},
+		{"a*b*c", "a-b-c", true},
+		{"prod*", "prod", true},
+	}
+	for _, tt := range tests {
+		if got := matchArnPattern(tt.pattern, tt.arn); got != tt.want {
+			t.Errorf("matchArnPattern(%q, %q) = %v, want %v", tt.pattern, tt.arn, got, tt.want)
+		}
+	}
+}
+
+func TestBackupSelectionSelects(t *testing.T) {
+	const (
+		orders = "arn:aws:rds:us-east-1:111:db:orders"
+		users  = "arn:aws:rds:us-east-1:111:db:users"
+	)
+	prod := map[string]string{"env": "prod", "team": "payments"}
+	dev := map[string]string{"env": "dev"}
+	listOfTags := BackupCondition{Operator: "STRINGEQUALS", Key: "aws:ResourceTag/env", Value: "prod"}
+
+	tests := []struct {
+		name      string
+		selection BackupSelection
+		arn       string
+		tags      map[string]string
+		want      bool
+	}{
+		{"by ARN", BackupSelection{Resources: []string{orders}}, orders, nil, true},
+		{"other ARN", BackupSelection{Resources: []string{orders}}, users, nil, false},
+		{"by wildcard", BackupSelection{Resources: []string{"arn:aws:rds:*:*:db:*"}}, users, nil, true},
+		{"excluded by NotResources", BackupSelection{Resources: []string{"*"}, NotResources: []string{users}}, users, nil, false},
+		{"by ListOfTags", BackupSelection{TagConditions: []BackupCondition{listOfTags}}, orders, prod, true},
+		{"ListOfTags without the tag", BackupSelection{TagConditions: []BackupCondition{listOfTags}}, orders, dev, false},
+		{"ListOfTags or Resources", BackupSelection{Resources: []string{users}, TagConditions: []BackupCondition{listOfTags}}, users, dev, true},
+		{"any ListOfTags condition", BackupSelection{TagConditions: []BackupCondition{
+			{Operator: "STRINGEQUALS", Key: "env", Value: "staging"},
+			{Operator: "STRINGEQUALS", Key: "team", Value: "payments"},
+		}}, orders, prod, true},
+		{"StringEquals alone applies to every resource", BackupSelection{TagConditions: []BackupCondition{
+			{Operator: "StringEquals", Key: "aws:ResourceTag/env", Value: "prod"},
+		}}, orders, prod, true},
+		{"StringEquals fails", BackupSelection{TagConditions: []BackupCondition{
+			{Operator: "StringEquals", Key: "aws:ResourceTag/env", Value: "prod"},
+		}}, orders, dev, false},
+		{"StringEquals narrows Resources", BackupSelection{Resources: []string{"*"}, TagConditions: []BackupCondition{
+			{Operator: "StringEquals", Key: "aws:ResourceTag/env", Value: "prod"},
+		}}, orders, dev, false},
+		{"StringLike", BackupSelection{Resources: []string{"*"}, TagConditions: []BackupCondition{
+			{Operator: "StringLike", Key: "aws:ResourceTag/team", Value: "pay*"},
+		}}, orders, prod, true},
+		{"StringNotEquals", BackupSelection{Resources: []string{"*"}, TagConditions: []BackupCondition{
+			{Operator: "StringNotEquals", Key: "aws:ResourceTag/env", Value: "dev"},
+		}}, orders, dev, false},
+		{"StringNotEquals without the tag", BackupSelection{Resources: []string{"*"}, TagConditions: []BackupCondition{
+			{Operator: "StringNotEquals", Key: "aws:ResourceTag/env", Value: "dev"},
+		}}, orders, nil, true},
+		{"StringNotLike", BackupSelection{Resources: []string{"*"}, TagConditions: []BackupCondition{
+			{Operator: "StringNotLike", Key: "aws:ResourceTag/team", Value: "pay*"},
+		}}, orders, prod, false},
+		{"ListOfTags and StringEquals", BackupSelection{TagConditions: []BackupCondition{
+			listOfTags,
+			{Operator: "StringEquals", Key: "aws:ResourceTag/team", Value: "search"},
+		}}, orders, prod, false},
+		{"no ARN", BackupSelection{Resources: []string{"*"}}, "", nil, false},
+	}
+	for _, tt := range tests {
+		if got := tt.selection.selects(backupCandidate{tt.arn, tt.tags}); got != tt.want {
+			t.Errorf("%s: selects() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBackupCoverageIndex(t *testing.T) {
+	const (
+		orders   = "arn:aws:rds:us-east-1:111:db:orders"
+		users    = "arn:aws:rds:us-east-1:111:db:users"
+		volume   = "arn:aws:ec2:us-east-1:111:volume/vol-1"
+		table    = "arn:aws:dynamodb:us-east-1:111:table/events"
+		external = "arn:aws:rds:us-east-1:111:db:not-crawled"
+		backedUp = "arn:aws:elasticfilesystem:us-east-1:111:file-system/fs-1"
+	)
+	lastBackup := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	plans := []BackupPlan{
+		{BackupPlanID: "by-tag", Selections: []BackupSelection{{TagConditions: []BackupCondition{
+			{Operator: "STRINGEQUALS", Key: "aws:ResourceTag/backup", Value: "daily"},
+		}}}},
+		{BackupPlanID: "rds", Selections: []BackupSelection{{
+			Resources:    []string{"arn:aws:rds:*:*:db:*"},
+			NotResources: []string{users},
+		}}},
+		{BackupPlanID: "named", Selections: []BackupSelection{{Resources: []string{external}}}},
+	}
+	vaults := []BackupVault{{RecoveryPoints: []BackupRecoveryPoint{{ResourceArn: backedUp, BackupPlanID: "old"}}}}
+	protected := []BackupProtectedResource{{ResourceArn: backedUp, LastBackupTime: &lastBackup}}
+	inv := BackupInventory{
+		Account:        AccountInfo{Partition: "aws", Region: "us-east-1", AccountID: "111"},
+		RDSInstances:   []RDSInstance{{DBInstanceArn: orders}, {DBInstanceArn: users, Tags: map[string]string{"backup": "daily"}}},
+		EBSVolumes:     []EBSVolume{{VolumeID: "vol-1", Tags: map[string]string{"backup": "daily"}}},
+		DynamoDBTables: []DynamoDBTable{{TableArn: table}},
+	}
+
+	index := BackupCoverageIndex(plans, vaults, protected, inv)
+
+	want := map[string][]string{
+		orders:   {"rds"},
+		users:    {"by-tag"},
+		volume:   {"by-tag"},
+		external: {"rds", "named"},
+		backedUp: {"old"},
+	}
+	for arn, plans := range want {
+		if got := index[arn].BackupPlanIDs; !slices.Equal(got, plans) {
+			t.Errorf("%s: BackupPlanIds = %q, want %q", arn, got, plans)
+		}
+	}
+	if _, ok := index[table]; ok {
+		t.Errorf("%s is covered, want uncovered", table)
+	}
+	if got := index[backedUp].LastBackupTime; got == nil || !got.Equal(lastBackup) {
+		t.Errorf("%s: LastBackupTime = %v, want %v", backedUp, got, lastBackup)
+	}
+}
@@ -0,0 +1,154 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// redactedValue replaces parameter values that must not leave the account.
+const redactedValue = "[REDACTED]"
+
+// CloudFormationStack represents a CloudFormation stack and the resources it manages.
+type CloudFormationStack struct {
+	StackID     string                        `json:"StackId"`
+	StackName   string                        `json:"StackName"`
+	StackStatus string                        `json:"StackStatus"`
+	DriftStatus string                        `json:"DriftStatus"`
+	ParentID    string                        `json:"ParentId"`
+	RoleARN     string                        `json:"RoleARN"`
+	Parameters  map[string]string             `json:"Parameters"`
+	Outputs     map[string]string             `json:"Outputs"`
+	Tags        map[string]string             `json:"Tags"`
+	Resources   []CloudFormationStackResource `json:"Resources"`
+}
+
+// CloudFormationStackResource represents a resource managed by a stack.
+type CloudFormationStackResource struct {
+	LogicalResourceID  string `json:"LogicalResourceId"`
+	PhysicalResourceID string `json:"PhysicalResourceId"`
+	ResourceType       string `json:"ResourceType"`
+	ResourceStatus     string `json:"ResourceStatus"`
+}
+
+// StackMembership identifies the stack that owns a crawled resource.
+type StackMembership struct {
+	StackName         string `json:"StackName"`
+	StackID           string `json:"StackId"`
+	LogicalResourceID string `json:"LogicalResourceId"`
+	ResourceType      string `json:"ResourceType"`
+}
+
+// FetchCloudFormationStacks retrieves all stacks with their parameters, outputs and resources.
+// Values of NoEcho parameters are redacted.
+func FetchCloudFormationStacks(ctx context.Context, cfg aws.Config) ([]CloudFormationStack, error) {
+	client := cloudformation.NewFromConfig(cfg)
+	paginator := cloudformation.NewDescribeStacksPaginator(client, &cloudformation.DescribeStacksInput{})
+	var stacks []CloudFormationStack
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching CloudFormation stacks: %w", err)
+		}
+		for _, s := range page.Stacks {
+			stack := CloudFormationStack{
+				StackID:     aws.ToString(s.StackId),
+				StackName:   aws.ToString(s.StackName),
+				StackStatus: string(s.StackStatus),
+				ParentID:    aws.ToString(s.ParentId),
+				RoleARN:     aws.ToString(s.RoleARN),
+				Parameters:  make(map[string]string),
+				Outputs:     make(map[string]string),
+				Tags:        make(map[string]string),
+			}
+			if s.DriftInformation != nil {
+				stack.DriftStatus = string(s.DriftInformation.StackDriftStatus)
+			}
+
+			noEcho, err := noEchoParameters(ctx, client, stack.StackID)
+			if err != nil {
+				return nil, err
+			}
+			for _, p := range s.Parameters {
+				key := aws.ToString(p.ParameterKey)
+				if noEcho[key] {
+					stack.Parameters[key] = redactedValue
+					continue
+				}
+				stack.Parameters[key] = aws.ToString(p.ParameterValue)
+			}
+			for _, o := range s.Outputs {
+				stack.Outputs[aws.ToString(o.OutputKey)] = aws.ToString(o.OutputValue)
+			}
+			for _, t := range s.Tags {
+				stack.Tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+			}
+
+			stack.Resources, err = fetchStackResources(ctx, client, stack.StackID)
+			if err != nil {
+				return nil, err
+			}
+			stacks = append(stacks, stack)
+		}
+	}
+	return stacks, nil
+}
+
+// noEchoParameters returns the keys of parameters declared NoEcho in the stack's template.
+func noEchoParameters(ctx context.Context, client *cloudformation.Client, stackID string) (map[string]bool, error) {
+	out, err := client.GetTemplateSummary(ctx, &cloudformation.GetTemplateSummaryInput{StackName: &stackID})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching template summary for stack %s: %w", stackID, err)
+	}
+	noEcho := make(map[string]bool)
+	for _, p := range out.Parameters {
+		if aws.ToBool(p.NoEcho) {
+			noEcho[aws.ToString(p.ParameterKey)] = true
+		}
+	}
+	return noEcho, nil
+}
+
+func fetchStackResources(ctx context.Context, client *cloudformation.Client, stackID string) ([]CloudFormationStackResource, error) {
+	paginator := cloudformation.NewListStackResourcesPaginator(client, &cloudformation.ListStackResourcesInput{StackName: &stackID})
+	var resources []CloudFormationStackResource
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing resources of stack %s: %w", stackID, err)
+		}
+		for _, r := range page.StackResourceSummaries {
+			resources = append(resources, CloudFormationStackResource{
+				LogicalResourceID:  aws.ToString(r.LogicalResourceId),
+				PhysicalResourceID: aws.ToString(r.PhysicalResourceId),
+				ResourceType:       aws.ToString(r.ResourceType),
+				ResourceStatus:     string(r.ResourceStatus),
+			})
+		}
+	}
+	return resources, nil
+}
+
+// StackMembershipIndex maps the physical ID of every stack-managed resource
+// (an instance ID, bucket name, load balancer ARN, ...) to the stack that owns
+// it. Crawled resources missing from the index were not created by CloudFormation.
+func StackMembershipIndex(stacks []CloudFormationStack) map[string]StackMembership {
+	index := make(map[string]StackMembership)
+	for _, s := range stacks {
+		for _, r := range s.Resources {
+			if r.PhysicalResourceID == "" {
+				continue
+			}
+			index[r.PhysicalResourceID] = StackMembership{
+				StackName:         s.StackName,
+				StackID:           s.StackID,
+				LogicalResourceID: r.LogicalResourceID,
+				ResourceType:      r.ResourceType,
+			}
+		}
+	}
+	return index
+}
@@ -0,0 +1,178 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// MetricAlarm represents a CloudWatch metric alarm.
+type MetricAlarm struct {
+	AlarmName          string            `json:"AlarmName"`
+	AlarmArn           string            `json:"AlarmArn"`
+	StateValue         string            `json:"StateValue"`
+	Namespace          string            `json:"Namespace"`
+	MetricName         string            `json:"MetricName"`
+	Statistic          string            `json:"Statistic"`
+	ComparisonOperator string            `json:"ComparisonOperator"`
+	Threshold          float64           `json:"Threshold"`
+	Period             int32             `json:"Period"`
+	EvaluationPeriods  int32             `json:"EvaluationPeriods"`
+	Dimensions         map[string]string `json:"Dimensions"`
+	ActionsEnabled     bool              `json:"ActionsEnabled"`
+	AlarmActions       []string          `json:"AlarmActions"`
+	OKActions          []string          `json:"OKActions"`
+	SNSTopicArns       []string          `json:"SnsTopicArns"`
+	Resources          []AlarmedResource `json:"Resources"`
+}
+
+// CompositeAlarm represents a CloudWatch composite alarm.
+type CompositeAlarm struct {
+	AlarmName      string   `json:"AlarmName"`
+	AlarmArn       string   `json:"AlarmArn"`
+	StateValue     string   `json:"StateValue"`
+	AlarmRule      string   `json:"AlarmRule"`
+	ActionsEnabled bool     `json:"ActionsEnabled"`
+	AlarmActions   []string `json:"AlarmActions"`
+	OKActions      []string `json:"OKActions"`
+	SNSTopicArns   []string `json:"SnsTopicArns"`
+}
+
+// AlarmedResource identifies a crawled resource referenced by an alarm's metric dimensions.
+type AlarmedResource struct {
+	ResourceType string `json:"ResourceType"`
+	ResourceID   string `json:"ResourceId"`
+}
+
+// alarmDimension is a metric dimension name within a namespace.
+type alarmDimension struct {
+	namespace string
+	name      string
+}
+
+// alarmDimensionResources maps metric dimensions to the resource type they
+// identify. Dimension names are reused across namespaces with different
+// meanings (ClusterName names an EKS cluster in ContainerInsights but an ECS
+// cluster in AWS/ECS), so they are only matched within their namespace.
+var alarmDimensionResources = map[alarmDimension]string{
+	{"AWS/EC2", "InstanceId"}:                   "ec2-instance",
+	{"CWAgent", "InstanceId"}:                   "ec2-instance",
+	{"AWS/EC2", "AutoScalingGroupName"}:         "autoscaling-group",
+	{"AWS/AutoScaling", "AutoScalingGroupName"}: "autoscaling-group",
+	{"AWS/RDS", "DBInstanceIdentifier"}:         "rds-instance",
+	{"AWS/ApplicationELB", "LoadBalancer"}:      "load-balancer",
+	{"AWS/NetworkELB", "LoadBalancer"}:          "load-balancer",
+	{"AWS/GatewayELB", "LoadBalancer"}:          "load-balancer",
+	{"AWS/ELB", "LoadBalancerName"}:             "load-balancer",
+	{"AWS/ElastiCache", "CacheClusterId"}:       "elasticache-cluster",
+	{"AWS/S3", "BucketName"}:                    "s3-bucket",
+	{"ContainerInsights", "ClusterName"}:        "eks-cluster",
+	{"AWS/ECS", "ClusterName"}:                  "ecs-cluster",
+	{"ECS/ContainerInsights", "ClusterName"}:    "ecs-cluster",
+	{"AWS/NATGateway", "NatGatewayId"}:          "nat-gateway",
+	{"AWS/ApiGateway", "ApiName"}:               "apigateway-api",
+	{"AWS/ApiGateway", "ApiId"}:                 "apigateway-api",
+	{"AWS/Lambda", "FunctionName"}:              "lambda-function",
+}
+
+// FetchCloudWatchAlarms retrieves metric and composite alarms.
+func FetchCloudWatchAlarms(ctx context.Context, cfg aws.Config) ([]MetricAlarm, []CompositeAlarm, error) {
+	client := cloudwatch.NewFromConfig(cfg)
+	paginator := cloudwatch.NewDescribeAlarmsPaginator(client, &cloudwatch.DescribeAlarmsInput{
+		AlarmTypes: []cwtypes.AlarmType{cwtypes.AlarmTypeMetricAlarm, cwtypes.AlarmTypeCompositeAlarm},
+	})
+	var metricAlarms []MetricAlarm
+	var compositeAlarms []CompositeAlarm
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error fetching CloudWatch alarms: %w", err)
+		}
+		for _, a := range page.MetricAlarms {
+			alarm := MetricAlarm{
+				AlarmName:          aws.ToString(a.AlarmName),
+				AlarmArn:           aws.ToString(a.AlarmArn),
+				StateValue:         string(a.StateValue),
+				Namespace:          aws.ToString(a.Namespace),
+				MetricName:         aws.ToString(a.MetricName),
+				Statistic:          string(a.Statistic),
+				ComparisonOperator: string(a.ComparisonOperator),
+				Threshold:          aws.ToFloat64(a.Threshold),
+				Period:             aws.ToInt32(a.Period),
+				EvaluationPeriods:  aws.ToInt32(a.EvaluationPeriods),
+				Dimensions:         make(map[string]string),
+				ActionsEnabled:     aws.ToBool(a.ActionsEnabled),
+				AlarmActions:       a.AlarmActions,
+				OKActions:          a.OKActions,
+				SNSTopicArns:       snsTopicArns(a.AlarmActions, a.OKActions, a.InsufficientDataActions),
+			}
+			type metricDimension struct {
+				namespace string
+				cwtypes.Dimension
+			}
+			var dimensions []metricDimension
+			for _, d := range a.Dimensions {
+				dimensions = append(dimensions, metricDimension{aws.ToString(a.Namespace), d})
+			}
+			// Alarms on metric math expressions carry their namespace and
+			// dimensions per query.
+			for _, q := range a.Metrics {
+				if q.MetricStat != nil && q.MetricStat.Metric != nil {
+					for _, d := range q.MetricStat.Metric.Dimensions {
+						dimensions = append(dimensions, metricDimension{aws.ToString(q.MetricStat.Metric.Namespace), d})
+					}
+				}
+			}
+			seen := make(map[AlarmedResource]bool)
+			for _, d := range dimensions {
+				name, value := aws.ToString(d.Name), aws.ToString(d.Value)
+				alarm.Dimensions[name] = value
+				if resourceType, ok := alarmDimensionResources[alarmDimension{d.namespace, name}]; ok {
+					// ELBv2 dimensions look like "app/name/id"; the inventory keys load balancers by name.
+					if parts := strings.Split(value, "/"); name == "LoadBalancer" && len(parts) == 3 {
+						value = parts[1]
+					}
+					ref := AlarmedResource{ResourceType: resourceType, ResourceID: value}
+					if !seen[ref] {
+						seen[ref] = true
+						alarm.Resources = append(alarm.Resources, ref)
+					}
+				}
+			}
+			metricAlarms = append(metricAlarms, alarm)
+		}
+		for _, a := range page.CompositeAlarms {
+			compositeAlarms = append(compositeAlarms, CompositeAlarm{
+				AlarmName:      aws.ToString(a.AlarmName),
+				AlarmArn:       aws.ToString(a.AlarmArn),
+				StateValue:     string(a.StateValue),
+				AlarmRule:      aws.ToString(a.AlarmRule),
+				ActionsEnabled: aws.ToBool(a.ActionsEnabled),
+				AlarmActions:   a.AlarmActions,
+				OKActions:      a.OKActions,
+				SNSTopicArns:   snsTopicArns(a.AlarmActions, a.OKActions, a.InsufficientDataActions),
+			})
+		}
+	}
+	return metricAlarms, compositeAlarms, nil
+}
+
+// snsTopicArns returns the distinct SNS topics among the given alarm actions.
+func snsTopicArns(actionLists ...[]string) []string {
+	var topics []string
+	seen := make(map[string]bool)
+	for _, actions := range actionLists {
+		for _, arn := range actions {
+			if strings.HasPrefix(arn, "arn:") && strings.Contains(arn, ":sns:") && !seen[arn] {
+				seen[arn] = true
+				topics = append(topics, arn)
+			}
+		}
+	}
+	return topics
+}
@@ -0,0 +1,101 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+)
+
+// LogGroup represents a CloudWatch Logs log group.
+type LogGroup struct {
+	LogGroupName        string               `json:"LogGroupName"`
+	Arn                 string               `json:"Arn"`
+	RetentionInDays     int32                `json:"RetentionInDays"` // 0 means logs never expire.
+	KmsKeyID            string               `json:"KmsKeyId"`
+	StoredBytes         int64                `json:"StoredBytes"`
+	LogGroupClass       string               `json:"LogGroupClass"`
+	SubscriptionFilters []SubscriptionFilter `json:"SubscriptionFilters"`
+	MetricFilters       []MetricFilter       `json:"MetricFilters"`
+}
+
+// SubscriptionFilter represents a log group subscription filter.
+type SubscriptionFilter struct {
+	FilterName     string `json:"FilterName"`
+	FilterPattern  string `json:"FilterPattern"`
+	DestinationArn string `json:"DestinationArn"`
+}
+
+// MetricFilter represents a log group metric filter.
+type MetricFilter struct {
+	FilterName      string `json:"FilterName"`
+	FilterPattern   string `json:"FilterPattern"`
+	MetricName      string `json:"MetricName"`
+	MetricNamespace string `json:"MetricNamespace"`
+}
+
+// FetchLogGroups retrieves log groups with their subscription and metric filters.
+func FetchLogGroups(ctx context.Context, cfg aws.Config) ([]LogGroup, error) {
+	client := cloudwatchlogs.NewFromConfig(cfg)
+
+	// Metric filters can be listed for all log groups at once.
+	metricFilters := make(map[string][]MetricFilter)
+	mfPaginator := cloudwatchlogs.NewDescribeMetricFiltersPaginator(client, &cloudwatchlogs.DescribeMetricFiltersInput{})
+	for mfPaginator.HasMorePages() {
+		page, err := mfPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching metric filters: %w", err)
+		}
+		for _, f := range page.MetricFilters {
+			for _, t := range f.MetricTransformations {
+				name := aws.ToString(f.LogGroupName)
+				metricFilters[name] = append(metricFilters[name], MetricFilter{
+					FilterName:      aws.ToString(f.FilterName),
+					FilterPattern:   aws.ToString(f.FilterPattern),
+					MetricName:      aws.ToString(t.MetricName),
+					MetricNamespace: aws.ToString(t.MetricNamespace),
+				})
+			}
+		}
+	}
+
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(client, &cloudwatchlogs.DescribeLogGroupsInput{})
+	var groups []LogGroup
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching log groups: %w", err)
+		}
+		for _, g := range page.LogGroups {
+			group := LogGroup{
+				LogGroupName:    aws.ToString(g.LogGroupName),
+				Arn:             aws.ToString(g.LogGroupArn),
+				RetentionInDays: aws.ToInt32(g.RetentionInDays),
+				KmsKeyID:        aws.ToString(g.KmsKeyId),
+				StoredBytes:     aws.ToInt64(g.StoredBytes),
+				LogGroupClass:   string(g.LogGroupClass),
+			}
+			group.MetricFilters = metricFilters[group.LogGroupName]
+
+			subPaginator := cloudwatchlogs.NewDescribeSubscriptionFiltersPaginator(client, &cloudwatchlogs.DescribeSubscriptionFiltersInput{
+				LogGroupName: g.LogGroupName,
+			})
+			for subPaginator.HasMorePages() {
+				subPage, err := subPaginator.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("error fetching subscription filters for log group %s: %w", group.LogGroupName, err)
+				}
+				for _, f := range subPage.SubscriptionFilters {
+					group.SubscriptionFilters = append(group.SubscriptionFilters, SubscriptionFilter{
+						FilterName:     aws.ToString(f.FilterName),
+						FilterPattern:  aws.ToString(f.FilterPattern),
+						DestinationArn: aws.ToString(f.DestinationArn),
+					})
+				}
+			}
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
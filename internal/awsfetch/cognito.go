@@ -0,0 +1,217 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentity"
+	"github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider"
+	ciptypes "github.com/aws/aws-sdk-go-v2/service/cognitoidentityprovider/types"
+)
+
+// CognitoUserPool represents a Cognito user pool with its app clients.
+// LambdaTriggers maps trigger names (e.g. "PreSignUp") to Lambda function ARNs.
+type CognitoUserPool struct {
+	ID                     string             `json:"Id"`
+	Name                   string             `json:"Name"`
+	Arn                    string             `json:"Arn"`
+	Status                 string             `json:"Status"`
+	MfaConfiguration       string             `json:"MfaConfiguration"`
+	AdvancedSecurityMode   string             `json:"AdvancedSecurityMode"`
+	DeletionProtection     string             `json:"DeletionProtection"`
+	Domain                 string             `json:"Domain"`
+	CustomDomain           string             `json:"CustomDomain"`
+	EstimatedNumberOfUsers int32              `json:"EstimatedNumberOfUsers"`
+	LambdaTriggers         map[string]string  `json:"LambdaTriggers"`
+	AppClients             []CognitoAppClient `json:"AppClients"`
+	Tags                   map[string]string  `json:"Tags"`
+}
+
+// CognitoAppClient represents a user pool app client. The client secret is
+// never collected; HasClientSecret only records whether one exists.
+type CognitoAppClient struct {
+	ClientID                   string   `json:"ClientId"`
+	ClientName                 string   `json:"ClientName"`
+	HasClientSecret            bool     `json:"HasClientSecret"`
+	ExplicitAuthFlows          []string `json:"ExplicitAuthFlows"`
+	AllowedOAuthFlows          []string `json:"AllowedOAuthFlows"`
+	AllowedOAuthScopes         []string `json:"AllowedOAuthScopes"`
+	CallbackURLs               []string `json:"CallbackURLs"`
+	SupportedIdentityProviders []string `json:"SupportedIdentityProviders"`
+}
+
+// CognitoIdentityPool represents a Cognito identity pool and the IAM roles it hands out.
+type CognitoIdentityPool struct {
+	IdentityPoolID                 string            `json:"IdentityPoolId"`
+	IdentityPoolName               string            `json:"IdentityPoolName"`
+	AllowUnauthenticatedIdentities bool              `json:"AllowUnauthenticatedIdentities"`
+	AllowClassicFlow               bool              `json:"AllowClassicFlow"`
+	UserPoolProviders              []string          `json:"CognitoIdentityProviders"`
+	SupportedLoginProviders        []string          `json:"SupportedLoginProviders"`
+	OpenIDConnectProviderARNs      []string          `json:"OpenIdConnectProviderARNs"`
+	SAMLProviderARNs               []string          `json:"SamlProviderARNs"`
+	Roles                          map[string]string `json:"Roles"` // "authenticated" / "unauthenticated" to role ARN.
+}
+
+// FetchCognitoUserPools retrieves user pools with their Lambda triggers and app clients.
+func FetchCognitoUserPools(ctx context.Context, cfg aws.Config) ([]CognitoUserPool, error) {
+	client := cognitoidentityprovider.NewFromConfig(cfg)
+	paginator := cognitoidentityprovider.NewListUserPoolsPaginator(client, &cognitoidentityprovider.ListUserPoolsInput{
+		MaxResults: aws.Int32(60),
+	})
+	var pools []CognitoUserPool
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Cognito user pools: %w", err)
+		}
+		for _, summary := range page.UserPools {
+			out, err := client.DescribeUserPool(ctx, &cognitoidentityprovider.DescribeUserPoolInput{UserPoolId: summary.Id})
+			if err != nil {
+				return nil, fmt.Errorf("error describing Cognito user pool %s: %w", aws.ToString(summary.Id), err)
+			}
+			p := out.UserPool
+			pool := CognitoUserPool{
+				ID:                     aws.ToString(p.Id),
+				Name:                   aws.ToString(p.Name),
+				Arn:                    aws.ToString(p.Arn),
+				Status:                 string(p.Status),
+				MfaConfiguration:       string(p.MfaConfiguration),
+				DeletionProtection:     string(p.DeletionProtection),
+				Domain:                 aws.ToString(p.Domain),
+				CustomDomain:           aws.ToString(p.CustomDomain),
+				EstimatedNumberOfUsers: p.EstimatedNumberOfUsers,
+				LambdaTriggers:         userPoolLambdaTriggers(p.LambdaConfig),
+				Tags:                   p.UserPoolTags,
+			}
+			if p.UserPoolAddOns != nil {
+				pool.AdvancedSecurityMode = string(p.UserPoolAddOns.AdvancedSecurityMode)
+			}
+			pool.AppClients, err = fetchUserPoolClients(ctx, client, pool.ID)
+			if err != nil {
+				return nil, err
+			}
+			pools = append(pools, pool)
+		}
+	}
+	return pools, nil
+}
+
+func userPoolLambdaTriggers(c *ciptypes.LambdaConfigType) map[string]string {
+	triggers := make(map[string]string)
+	if c == nil {
+		return triggers
+	}
+	for name, arn := range map[string]*string{
+		"PreSignUp":                   c.PreSignUp,
+		"CustomMessage":               c.CustomMessage,
+		"PostConfirmation":            c.PostConfirmation,
+		"PreAuthentication":           c.PreAuthentication,
+		"PostAuthentication":          c.PostAuthentication,
+		"DefineAuthChallenge":         c.DefineAuthChallenge,
+		"CreateAuthChallenge":         c.CreateAuthChallenge,
+		"VerifyAuthChallengeResponse": c.VerifyAuthChallengeResponse,
+		"PreTokenGeneration":          c.PreTokenGeneration,
+		"UserMigration":               c.UserMigration,
+	} {
+		if arn != nil {
+			triggers[name] = *arn
+		}
+	}
+	// Versioned trigger configurations take precedence over the legacy fields.
+	if c.PreTokenGenerationConfig != nil && c.PreTokenGenerationConfig.LambdaArn != nil {
+		triggers["PreTokenGeneration"] = *c.PreTokenGenerationConfig.LambdaArn
+	}
+	if c.CustomSMSSender != nil && c.CustomSMSSender.LambdaArn != nil {
+		triggers["CustomSMSSender"] = *c.CustomSMSSender.LambdaArn
+	}
+	if c.CustomEmailSender != nil && c.CustomEmailSender.LambdaArn != nil {
+		triggers["CustomEmailSender"] = *c.CustomEmailSender.LambdaArn
+	}
+	return triggers
+}
+
+func fetchUserPoolClients(ctx context.Context, client *cognitoidentityprovider.Client, userPoolID string) ([]CognitoAppClient, error) {
+	paginator := cognitoidentityprovider.NewListUserPoolClientsPaginator(client, &cognitoidentityprovider.ListUserPoolClientsInput{
+		UserPoolId: &userPoolID,
+	})
+	var clients []CognitoAppClient
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing app clients of user pool %s: %w", userPoolID, err)
+		}
+		for _, summary := range page.UserPoolClients {
+			out, err := client.DescribeUserPoolClient(ctx, &cognitoidentityprovider.DescribeUserPoolClientInput{
+				UserPoolId: &userPoolID,
+				ClientId:   summary.ClientId,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error describing app client %s: %w", aws.ToString(summary.ClientId), err)
+			}
+			c := out.UserPoolClient
+			appClient := CognitoAppClient{
+				ClientID:                   aws.ToString(c.ClientId),
+				ClientName:                 aws.ToString(c.ClientName),
+				HasClientSecret:            aws.ToString(c.ClientSecret) != "",
+				AllowedOAuthScopes:         c.AllowedOAuthScopes,
+				CallbackURLs:               c.CallbackURLs,
+				SupportedIdentityProviders: c.SupportedIdentityProviders,
+			}
+			for _, f := range c.ExplicitAuthFlows {
+				appClient.ExplicitAuthFlows = append(appClient.ExplicitAuthFlows, string(f))
+			}
+			for _, f := range c.AllowedOAuthFlows {
+				appClient.AllowedOAuthFlows = append(appClient.AllowedOAuthFlows, string(f))
+			}
+			clients = append(clients, appClient)
+		}
+	}
+	return clients, nil
+}
+
+// FetchCognitoIdentityPools retrieves identity pools with their identity providers and roles.
+func FetchCognitoIdentityPools(ctx context.Context, cfg aws.Config) ([]CognitoIdentityPool, error) {
+	client := cognitoidentity.NewFromConfig(cfg)
+	paginator := cognitoidentity.NewListIdentityPoolsPaginator(client, &cognitoidentity.ListIdentityPoolsInput{
+		MaxResults: aws.Int32(60),
+	})
+	var pools []CognitoIdentityPool
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Cognito identity pools: %w", err)
+		}
+		for _, summary := range page.IdentityPools {
+			out, err := client.DescribeIdentityPool(ctx, &cognitoidentity.DescribeIdentityPoolInput{IdentityPoolId: summary.IdentityPoolId})
+			if err != nil {
+				return nil, fmt.Errorf("error describing Cognito identity pool %s: %w", aws.ToString(summary.IdentityPoolId), err)
+			}
+			pool := CognitoIdentityPool{
+				IdentityPoolID:                 aws.ToString(out.IdentityPoolId),
+				IdentityPoolName:               aws.ToString(out.IdentityPoolName),
+				AllowUnauthenticatedIdentities: out.AllowUnauthenticatedIdentities,
+				AllowClassicFlow:               aws.ToBool(out.AllowClassicFlow),
+				OpenIDConnectProviderARNs:      out.OpenIdConnectProviderARNs,
+				SAMLProviderARNs:               out.SamlProviderARNs,
+			}
+			for _, p := range out.CognitoIdentityProviders {
+				pool.UserPoolProviders = append(pool.UserPoolProviders, aws.ToString(p.ProviderName))
+			}
+			for provider := range out.SupportedLoginProviders {
+				pool.SupportedLoginProviders = append(pool.SupportedLoginProviders, provider)
+			}
+
+			roles, err := client.GetIdentityPoolRoles(ctx, &cognitoidentity.GetIdentityPoolRolesInput{IdentityPoolId: out.IdentityPoolId})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching roles of Cognito identity pool %s: %w", pool.IdentityPoolID, err)
+			}
+			pool.Roles = roles.Roles
+			pools = append(pools, pool)
+		}
+	}
+	return pools, nil
+}
@@ -0,0 +1,120 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/directconnect"
+)
+
+// DirectConnectVirtualInterface represents a Direct Connect virtual interface.
+type DirectConnectVirtualInterface struct {
+	VirtualInterfaceID     string `json:"VirtualInterfaceId"`
+	VirtualInterfaceName   string `json:"VirtualInterfaceName"`
+	VirtualInterfaceType   string `json:"VirtualInterfaceType"`
+	State                  string `json:"State"`
+	ConnectionID           string `json:"ConnectionId"`
+	Vlan                   int32  `json:"Vlan"`
+	Asn                    int32  `json:"Asn"`
+	AmazonSideAsn          int64  `json:"AmazonSideAsn"`
+	VirtualGatewayID       string `json:"VirtualGatewayId"`
+	DirectConnectGatewayID string `json:"DirectConnectGatewayId"`
+	Region                 string `json:"Region"`
+}
+
+// DirectConnectGateway represents a Direct Connect gateway and the gateways it is associated with.
+type DirectConnectGateway struct {
+	DirectConnectGatewayID   string                            `json:"DirectConnectGatewayId"`
+	DirectConnectGatewayName string                            `json:"DirectConnectGatewayName"`
+	State                    string                            `json:"State"`
+	AmazonSideAsn            int64                             `json:"AmazonSideAsn"`
+	Associations             []DirectConnectGatewayAssociation `json:"Associations"`
+}
+
+// DirectConnectGatewayAssociation links a Direct Connect gateway to a virtual private or Transit Gateway.
+type DirectConnectGatewayAssociation struct {
+	AssociatedGatewayID   string `json:"AssociatedGatewayId"`
+	AssociatedGatewayType string `json:"AssociatedGatewayType"`
+	State                 string `json:"State"`
+}
+
+// FetchDirectConnectData retrieves Direct Connect virtual interfaces and Direct Connect gateways.
+func FetchDirectConnectData(ctx context.Context, cfg aws.Config) ([]DirectConnectVirtualInterface, []DirectConnectGateway, error) {
+	client := directconnect.NewFromConfig(cfg)
+
+	// Fetch virtual interfaces
+	vifOut, err := client.DescribeVirtualInterfaces(ctx, &directconnect.DescribeVirtualInterfacesInput{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("error fetching Direct Connect virtual interfaces: %w", err)
+	}
+	var vifs []DirectConnectVirtualInterface
+	for _, v := range vifOut.VirtualInterfaces {
+		vifs = append(vifs, DirectConnectVirtualInterface{
+			VirtualInterfaceID:     aws.ToString(v.VirtualInterfaceId),
+			VirtualInterfaceName:   aws.ToString(v.VirtualInterfaceName),
+			VirtualInterfaceType:   aws.ToString(v.VirtualInterfaceType),
+			State:                  string(v.VirtualInterfaceState),
+			ConnectionID:           aws.ToString(v.ConnectionId),
+			Vlan:                   v.Vlan,
+			Asn:                    v.Asn,
+			AmazonSideAsn:          aws.ToInt64(v.AmazonSideAsn),
+			VirtualGatewayID:       aws.ToString(v.VirtualGatewayId),
+			DirectConnectGatewayID: aws.ToString(v.DirectConnectGatewayId),
+			Region:                 aws.ToString(v.Region),
+		})
+	}
+
+	// Fetch Direct Connect gateways and their associations
+	var gateways []DirectConnectGateway
+	input := &directconnect.DescribeDirectConnectGatewaysInput{}
+	for {
+		out, err := client.DescribeDirectConnectGateways(ctx, input)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error fetching Direct Connect gateways: %w", err)
+		}
+		for _, g := range out.DirectConnectGateways {
+			gateway := DirectConnectGateway{
+				DirectConnectGatewayID:   aws.ToString(g.DirectConnectGatewayId),
+				DirectConnectGatewayName: aws.ToString(g.DirectConnectGatewayName),
+				State:                    string(g.DirectConnectGatewayState),
+				AmazonSideAsn:            aws.ToInt64(g.AmazonSideAsn),
+			}
+			gateway.Associations, err = fetchDirectConnectGatewayAssociations(ctx, client, gateway.DirectConnectGatewayID)
+			if err != nil {
+				return nil, nil, err
+			}
+			gateways = append(gateways, gateway)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+
+	return vifs, gateways, nil
+}
+
+func fetchDirectConnectGatewayAssociations(ctx context.Context, client *directconnect.Client, gatewayID string) ([]DirectConnectGatewayAssociation, error) {
+	var associations []DirectConnectGatewayAssociation
+	input := &directconnect.DescribeDirectConnectGatewayAssociationsInput{DirectConnectGatewayId: &gatewayID}
+	for {
+		out, err := client.DescribeDirectConnectGatewayAssociations(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching associations for Direct Connect gateway %s: %w", gatewayID, err)
+		}
+		for _, a := range out.DirectConnectGatewayAssociations {
+			assoc := DirectConnectGatewayAssociation{State: string(a.AssociationState)}
+			if a.AssociatedGateway != nil {
+				assoc.AssociatedGatewayID = aws.ToString(a.AssociatedGateway.Id)
+				assoc.AssociatedGatewayType = string(a.AssociatedGateway.Type)
+			}
+			associations = append(associations, assoc)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return associations, nil
+}
@@ -0,0 +1,74 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+)
+
+// DynamoDBTable represents a DynamoDB table.
+type DynamoDBTable struct {
+	TableName      string            `json:"TableName"`
+	TableArn       string            `json:"TableArn"`
+	TableStatus    string            `json:"TableStatus"`
+	BillingMode    string            `json:"BillingMode"`
+	ItemCount      int64             `json:"ItemCount"`
+	TableSizeBytes int64             `json:"TableSizeBytes"`
+	Encryption     string            `json:"Encryption"` // SSE type; empty for AWS owned keys.
+	Tags           map[string]string `json:"Tags"`
+	Findings       *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
+}
+
+// FetchDynamoDBTables retrieves all DynamoDB tables with their tags.
+func FetchDynamoDBTables(ctx context.Context, cfg aws.Config) ([]DynamoDBTable, error) {
+	client := dynamodb.NewFromConfig(cfg)
+	paginator := dynamodb.NewListTablesPaginator(client, &dynamodb.ListTablesInput{})
+	var tables []DynamoDBTable
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing DynamoDB tables: %w", err)
+		}
+		for _, name := range page.TableNames {
+			out, err := client.DescribeTable(ctx, &dynamodb.DescribeTableInput{TableName: &name})
+			if err != nil {
+				return nil, fmt.Errorf("error describing DynamoDB table %s: %w", name, err)
+			}
+			t := out.Table
+			table := DynamoDBTable{
+				TableName:      aws.ToString(t.TableName),
+				TableArn:       aws.ToString(t.TableArn),
+				TableStatus:    string(t.TableStatus),
+				ItemCount:      aws.ToInt64(t.ItemCount),
+				TableSizeBytes: aws.ToInt64(t.TableSizeBytes),
+				Tags:           make(map[string]string),
+			}
+			if t.BillingModeSummary != nil {
+				table.BillingMode = string(t.BillingModeSummary.BillingMode)
+			}
+			if t.SSEDescription != nil {
+				table.Encryption = string(t.SSEDescription.SSEType)
+			}
+
+			input := &dynamodb.ListTagsOfResourceInput{ResourceArn: t.TableArn}
+			for {
+				tagOut, err := client.ListTagsOfResource(ctx, input)
+				if err != nil {
+					return nil, fmt.Errorf("error fetching tags of DynamoDB table %s: %w", name, err)
+				}
+				for _, tag := range tagOut.Tags {
+					table.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+				}
+				if tagOut.NextToken == nil {
+					break
+				}
+				input.NextToken = tagOut.NextToken
+			}
+			tables = append(tables, table)
+		}
+	}
+	return tables, nil
+}
@@ -0,0 +1,56 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// EBSVolume represents an EBS volume.
+type EBSVolume struct {
+	VolumeID         string            `json:"VolumeId"`
+	VolumeType       string            `json:"VolumeType"`
+	Size             int32             `json:"Size"` // GiB.
+	State            string            `json:"State"`
+	AvailabilityZone string            `json:"AvailabilityZone"`
+	Encrypted        bool              `json:"Encrypted"`
+	KmsKeyID         string            `json:"KmsKeyId"`
+	SnapshotID       string            `json:"SnapshotId"`
+	InstanceIDs      []string          `json:"InstanceIds"` // Instances the volume is attached to.
+	Tags             map[string]string `json:"Tags"`
+	Findings         *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
+}
+
+// FetchEBSVolumes retrieves all EBS volumes.
+func FetchEBSVolumes(ctx context.Context, cfg aws.Config) ([]EBSVolume, error) {
+	client := ec2.NewFromConfig(cfg)
+	paginator := ec2.NewDescribeVolumesPaginator(client, &ec2.DescribeVolumesInput{})
+	var volumes []EBSVolume
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching EBS volumes: %w", err)
+		}
+		for _, v := range page.Volumes {
+			volume := EBSVolume{
+				VolumeID:         aws.ToString(v.VolumeId),
+				VolumeType:       string(v.VolumeType),
+				Size:             aws.ToInt32(v.Size),
+				State:            string(v.State),
+				AvailabilityZone: aws.ToString(v.AvailabilityZone),
+				Encrypted:        aws.ToBool(v.Encrypted),
+				KmsKeyID:         aws.ToString(v.KmsKeyId),
+				SnapshotID:       aws.ToString(v.SnapshotId),
+				Tags:             ec2TagsToMap(v.Tags),
+			}
+			for _, a := range v.Attachments {
+				volume.InstanceIDs = append(volume.InstanceIDs, aws.ToString(a.InstanceId))
+			}
+			volumes = append(volumes, volume)
+		}
+	}
+	return volumes, nil
+}
@@ -6,14 +6,22 @@ import (
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
 // EC2Instance represents a simplified EC2 instance.
 type EC2Instance struct {
-	InstanceID string            `json:"InstanceId"`
-	VpcID      string            `json:"VpcId"`
-	SubnetID   string            `json:"SubnetId"`
-	Tags       map[string]string `json:"Tags"`
+	InstanceID          string              `json:"InstanceId"`
+	VpcID               string              `json:"VpcId"`
+	SubnetID            string              `json:"SubnetId"`
+	InstanceType        string              `json:"InstanceType"`
+	State               string              `json:"State"`
+	InstanceLifecycle   string              `json:"InstanceLifecycle"` // Empty for On-Demand instances.
+	VCPUs               int32               `json:"VCpus"`
+	NetworkInterfaceIDs []string            `json:"NetworkInterfaceIds"`
+	SSM                 *SSMManagedInstance `json:"Ssm"` // Set by LinkSSMManagedInstances; nil when not managed by Systems Manager.
+	Tags                map[string]string   `json:"Tags"`
+	Findings            *ResourceFindings   `json:"Findings"` // Set by LinkSecurityFindings.
 }
 
 // FetchEC2Instances retrieves all EC2 instances.
@@ -30,7 +38,15 @@ func FetchEC2Instances(ctx context.Context, cfg aws.Config) ([]EC2Instance, erro
 		for _, reservation := range page.Reservations {
 			for _, inst := range reservation.Instances {
 				instance := EC2Instance{
-					InstanceID: *inst.InstanceId,
+					InstanceID:        *inst.InstanceId,
+					InstanceType:      string(inst.InstanceType),
+					InstanceLifecycle: string(inst.InstanceLifecycle),
+				}
+				if inst.State != nil {
+					instance.State = string(inst.State.Name)
+				}
+				if inst.CpuOptions != nil {
+					instance.VCPUs = aws.ToInt32(inst.CpuOptions.CoreCount) * aws.ToInt32(inst.CpuOptions.ThreadsPerCore)
 				}
 				if inst.VpcId != nil {
 					instance.VpcID = *inst.VpcId
@@ -38,16 +54,26 @@ func FetchEC2Instances(ctx context.Context, cfg aws.Config) ([]EC2Instance, erro
 				if inst.SubnetId != nil {
 					instance.SubnetID = *inst.SubnetId
 				}
-				tagsMap := make(map[string]string)
-				for _, tag := range inst.Tags {
-					if tag.Key != nil && tag.Value != nil {
-						tagsMap[*tag.Key] = *tag.Value
+				for _, ni := range inst.NetworkInterfaces {
+					if ni.NetworkInterfaceId != nil {
+						instance.NetworkInterfaceIDs = append(instance.NetworkInterfaceIDs, *ni.NetworkInterfaceId)
 					}
 				}
-				instance.Tags = tagsMap
+				instance.Tags = ec2TagsToMap(inst.Tags)
 				instances = append(instances, instance)
 			}
 		}
 	}
 	return instances, nil
 }
+
+// ec2TagsToMap converts EC2 resource tags into a key/value map.
+func ec2TagsToMap(tags []ec2types.Tag) map[string]string {
+	tagsMap := make(map[string]string)
+	for _, tag := range tags {
+		if tag.Key != nil && tag.Value != nil {
+			tagsMap[*tag.Key] = *tag.Value
+		}
+	}
+	return tagsMap
+}
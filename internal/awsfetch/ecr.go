@@ -0,0 +1,390 @@
+package awsfetch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ecr"
+	ecrtypes "github.com/aws/aws-sdk-go-v2/service/ecr/types"
+	"github.com/aws/aws-sdk-go-v2/service/ecs"
+	ecstypes "github.com/aws/aws-sdk-go-v2/service/ecs/types"
+	"github.com/aws/aws-sdk-go-v2/service/lambda"
+	lambdatypes "github.com/aws/aws-sdk-go-v2/service/lambda/types"
+)
+
+// ECRRepository represents an ECR repository and the images it holds.
+type ECRRepository struct {
+	RepositoryName       string            `json:"RepositoryName"`
+	RepositoryArn        string            `json:"RepositoryArn"`
+	RepositoryURI        string            `json:"RepositoryUri"`
+	ScanOnPush           bool              `json:"ScanOnPush"`
+	ImageTagMutability   string            `json:"ImageTagMutability"`
+	EncryptionType       string            `json:"EncryptionType"`
+	KmsKey               string            `json:"KmsKey"`
+	HasLifecyclePolicy   bool              `json:"HasLifecyclePolicy"`
+	LifecyclePolicy      string            `json:"LifecyclePolicy"`
+	RepositoryPrincipals []string          `json:"RepositoryPolicyPrincipals"`
+	Images               []ECRImage        `json:"Images"`
+	Findings             *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
+}
+
+// ECRImage represents an image in an ECR repository.
+type ECRImage struct {
+	ImageDigest           string                   `json:"ImageDigest"`
+	ImageTags             []string                 `json:"ImageTags"`
+	ImagePushedAt         *time.Time               `json:"ImagePushedAt"`
+	ImageSizeInBytes      int64                    `json:"ImageSizeInBytes"`
+	LastRecordedPullTime  *time.Time               `json:"LastRecordedPullTime"`
+	ScanStatus            string                   `json:"ScanStatus"`
+	FindingSeverityCounts map[string]int32         `json:"FindingSeverityCounts"`
+	UsedBy                []ContainerImageConsumer `json:"UsedBy"`
+}
+
+// ContainerImageConsumer represents a workload that runs a container image.
+type ContainerImageConsumer struct {
+	ResourceType string `json:"ResourceType"`
+	ResourceArn  string `json:"ResourceArn"`
+	// Workload names an EKS workload within the cluster ResourceArn, as
+	// "namespace/Kind/name"; empty for other resource types.
+	Workload string `json:"Workload"`
+	ImageURI string `json:"ImageUri"`
+}
+
+// FetchECRRepositories retrieves ECR repositories with their policies and images.
+func FetchECRRepositories(ctx context.Context, cfg aws.Config) ([]ECRRepository, error) {
+	client := ecr.NewFromConfig(cfg)
+	paginator := ecr.NewDescribeRepositoriesPaginator(client, &ecr.DescribeRepositoriesInput{})
+	var repos []ECRRepository
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching ECR repositories: %w", err)
+		}
+		for _, r := range page.Repositories {
+			repo := ECRRepository{
+				RepositoryName:     aws.ToString(r.RepositoryName),
+				RepositoryArn:      aws.ToString(r.RepositoryArn),
+				RepositoryURI:      aws.ToString(r.RepositoryUri),
+				ImageTagMutability: string(r.ImageTagMutability),
+			}
+			if r.ImageScanningConfiguration != nil {
+				repo.ScanOnPush = r.ImageScanningConfiguration.ScanOnPush
+			}
+			if r.EncryptionConfiguration != nil {
+				repo.EncryptionType = string(r.EncryptionConfiguration.EncryptionType)
+				repo.KmsKey = aws.ToString(r.EncryptionConfiguration.KmsKey)
+			}
+
+			lifecycle, err := client.GetLifecyclePolicy(ctx, &ecr.GetLifecyclePolicyInput{RepositoryName: r.RepositoryName})
+			var noLifecycle *ecrtypes.LifecyclePolicyNotFoundException
+			switch {
+			case errors.As(err, &noLifecycle):
+			case err != nil:
+				return nil, fmt.Errorf("error fetching lifecycle policy for ECR repository %s: %w", repo.RepositoryName, err)
+			default:
+				repo.HasLifecyclePolicy = true
+				repo.LifecyclePolicy = aws.ToString(lifecycle.LifecyclePolicyText)
+			}
+
+			policy, err := client.GetRepositoryPolicy(ctx, &ecr.GetRepositoryPolicyInput{RepositoryName: r.RepositoryName})
+			var noPolicy *ecrtypes.RepositoryPolicyNotFoundException
+			switch {
+			case errors.As(err, &noPolicy):
+			case err != nil:
+				return nil, fmt.Errorf("error fetching repository policy for ECR repository %s: %w", repo.RepositoryName, err)
+			default:
+				repo.RepositoryPrincipals = policyPrincipals(aws.ToString(policy.PolicyText))
+			}
+
+			repo.Images, err = fetchECRImages(ctx, client, repo.RepositoryName)
+			if err != nil {
+				return nil, err
+			}
+			repos = append(repos, repo)
+		}
+	}
+	return repos, nil
+}
+
+func fetchECRImages(ctx context.Context, client *ecr.Client, repositoryName string) ([]ECRImage, error) {
+	paginator := ecr.NewDescribeImagesPaginator(client, &ecr.DescribeImagesInput{RepositoryName: &repositoryName})
+	var images []ECRImage
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching images for ECR repository %s: %w", repositoryName, err)
+		}
+		for _, d := range page.ImageDetails {
+			image := ECRImage{
+				ImageDigest:          aws.ToString(d.ImageDigest),
+				ImageTags:            d.ImageTags,
+				ImagePushedAt:        d.ImagePushedAt,
+				ImageSizeInBytes:     aws.ToInt64(d.ImageSizeInBytes),
+				LastRecordedPullTime: d.LastRecordedPullTime,
+			}
+			if d.ImageScanStatus != nil {
+				image.ScanStatus = string(d.ImageScanStatus.Status)
+			}
+			if d.ImageScanFindingsSummary != nil {
+				image.FindingSeverityCounts = d.ImageScanFindingsSummary.FindingSeverityCounts
+			}
+			images = append(images, image)
+		}
+	}
+	return images, nil
+}
+
+// FetchContainerImageConsumers retrieves the container images referenced by
+// Lambda container functions, by ECS services through the task definitions
+// their deployments run, by the latest active revision of every ECS task
+// definition family (for tasks started outside services), and by the
+// workloads of EKS clusters. EKS clusters whose pods cannot be listed are
+// returned separately.
+func FetchContainerImageConsumers(ctx context.Context, cfg aws.Config) ([]ContainerImageConsumer, []EKSWorkloadError, error) {
+	var consumers []ContainerImageConsumer
+
+	// Lambda container functions
+	lambdaClient := lambda.NewFromConfig(cfg)
+	fnPaginator := lambda.NewListFunctionsPaginator(lambdaClient, &lambda.ListFunctionsInput{})
+	for fnPaginator.HasMorePages() {
+		page, err := fnPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing Lambda functions: %w", err)
+		}
+		for _, fn := range page.Functions {
+			if fn.PackageType != lambdatypes.PackageTypeImage {
+				continue
+			}
+			out, err := lambdaClient.GetFunction(ctx, &lambda.GetFunctionInput{FunctionName: fn.FunctionArn})
+			if err != nil {
+				return nil, nil, fmt.Errorf("error fetching Lambda function %s: %w", aws.ToString(fn.FunctionName), err)
+			}
+			if out.Code == nil {
+				continue
+			}
+			// The resolved URI pins the digest, which survives tag moves.
+			imageURI := aws.ToString(out.Code.ResolvedImageUri)
+			if imageURI == "" {
+				imageURI = aws.ToString(out.Code.ImageUri)
+			}
+			consumers = append(consumers, ContainerImageConsumer{
+				ResourceType: "lambda-function",
+				ResourceArn:  aws.ToString(fn.FunctionArn),
+				ImageURI:     imageURI,
+			})
+		}
+	}
+
+	// ECS services and task definitions
+	ecsClient := ecs.NewFromConfig(cfg)
+	ecsConsumers, err := fetchECSImageConsumers(ctx, ecsClient)
+	if err != nil {
+		return nil, nil, err
+	}
+	consumers = append(consumers, ecsConsumers...)
+
+	// EKS workloads
+	eksConsumers, eksErrors, err := fetchEKSImageConsumers(ctx, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	consumers = append(consumers, eksConsumers...)
+
+	return consumers, eksErrors, nil
+}
+
+// fetchECSImageConsumers returns the images of the task definitions every
+// ECS service's deployments run, which during and after a rollout need not be
+// the latest revision, and of the latest active revision of every family.
+func fetchECSImageConsumers(ctx context.Context, client *ecs.Client) ([]ContainerImageConsumer, error) {
+	var consumers []ContainerImageConsumer
+	taskDefinitions := make(map[string]*ecstypes.TaskDefinition)
+	describe := func(arn string) (*ecstypes.TaskDefinition, error) {
+		if td, ok := taskDefinitions[arn]; ok {
+			return td, nil
+		}
+		out, err := client.DescribeTaskDefinition(ctx, &ecs.DescribeTaskDefinitionInput{TaskDefinition: &arn})
+		if err != nil {
+			return nil, fmt.Errorf("error describing ECS task definition %s: %w", arn, err)
+		}
+		taskDefinitions[arn] = out.TaskDefinition
+		return out.TaskDefinition, nil
+	}
+	addImages := func(resourceType, resourceArn string, td *ecstypes.TaskDefinition) {
+		if td == nil {
+			return
+		}
+		for _, c := range td.ContainerDefinitions {
+			consumers = append(consumers, ContainerImageConsumer{
+				ResourceType: resourceType,
+				ResourceArn:  resourceArn,
+				ImageURI:     aws.ToString(c.Image),
+			})
+		}
+	}
+
+	clusterPaginator := ecs.NewListClustersPaginator(client, &ecs.ListClustersInput{})
+	for clusterPaginator.HasMorePages() {
+		page, err := clusterPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ECS clusters: %w", err)
+		}
+		for _, cluster := range page.ClusterArns {
+			servicePaginator := ecs.NewListServicesPaginator(client, &ecs.ListServicesInput{Cluster: &cluster})
+			for servicePaginator.HasMorePages() {
+				servicePage, err := servicePaginator.NextPage(ctx)
+				if err != nil {
+					return nil, fmt.Errorf("error listing ECS services of cluster %s: %w", cluster, err)
+				}
+				if len(servicePage.ServiceArns) == 0 {
+					continue
+				}
+				// ListServices pages hold at most 10 services, the DescribeServices limit.
+				out, err := client.DescribeServices(ctx, &ecs.DescribeServicesInput{Cluster: &cluster, Services: servicePage.ServiceArns})
+				if err != nil {
+					return nil, fmt.Errorf("error describing ECS services of cluster %s: %w", cluster, err)
+				}
+				for _, service := range out.Services {
+					arns := []string{aws.ToString(service.TaskDefinition)}
+					for _, d := range service.Deployments {
+						arns = append(arns, aws.ToString(d.TaskDefinition))
+					}
+					seen := make(map[string]bool)
+					for _, arn := range arns {
+						if arn == "" || seen[arn] {
+							continue
+						}
+						seen[arn] = true
+						td, err := describe(arn)
+						if err != nil {
+							return nil, err
+						}
+						addImages("ecs-service", aws.ToString(service.ServiceArn), td)
+					}
+				}
+			}
+		}
+	}
+
+	famPaginator := ecs.NewListTaskDefinitionFamiliesPaginator(client, &ecs.ListTaskDefinitionFamiliesInput{
+		Status: ecstypes.TaskDefinitionFamilyStatusActive,
+	})
+	for famPaginator.HasMorePages() {
+		page, err := famPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing ECS task definition families: %w", err)
+		}
+		for _, family := range page.Families {
+			td, err := describe(family)
+			if err != nil {
+				return nil, err
+			}
+			if td != nil {
+				addImages("ecs-task-definition", aws.ToString(td.TaskDefinitionArn), td)
+			}
+		}
+	}
+	return consumers, nil
+}
+
+// LinkECRImageConsumers records on each ECR image the workloads that run it.
+// References are matched by repository URI and either digest or tag.
+func LinkECRImageConsumers(repos []ECRRepository, consumers []ContainerImageConsumer) {
+	byURI := make(map[string]*ECRRepository)
+	for i := range repos {
+		byURI[repos[i].RepositoryURI] = &repos[i]
+	}
+	for _, c := range consumers {
+		repoURI, tag, digest := parseImageURI(c.ImageURI)
+		repo, ok := byURI[repoURI]
+		if !ok {
+			continue
+		}
+		for i := range repo.Images {
+			image := &repo.Images[i]
+			if (digest != "" && image.ImageDigest == digest) || (digest == "" && containsString(image.ImageTags, tag)) {
+				image.UsedBy = append(image.UsedBy, c)
+				break
+			}
+		}
+	}
+}
+
+// parseImageURI splits "repo:tag" or "repo@sha256:..." into its parts.
+// A missing tag defaults to "latest".
+func parseImageURI(uri string) (repo, tag, digest string) {
+	if i := strings.Index(uri, "@"); i >= 0 {
+		return uri[:i], "", uri[i+1:]
+	}
+	if i := strings.LastIndex(uri, ":"); i > strings.LastIndex(uri, "/") {
+		return uri[:i], uri[i+1:], ""
+	}
+	return uri, "latest", ""
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// policyPrincipals returns the distinct principals granted access by a resource policy.
+func policyPrincipals(policy string) []string {
+	type statement struct {
+		Principal json.RawMessage `json:"Principal"`
+	}
+	var doc struct {
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(policy), &doc); err != nil {
+		return nil
+	}
+	// Statement may be a single object or a list of them.
+	var statements []statement
+	if json.Unmarshal(doc.Statement, &statements) != nil {
+		var single statement
+		if json.Unmarshal(doc.Statement, &single) != nil {
+			return nil
+		}
+		statements = []statement{single}
+	}
+	seen := make(map[string]bool)
+	for _, stmt := range statements {
+		var wildcard string
+		if json.Unmarshal(stmt.Principal, &wildcard) == nil {
+			seen[wildcard] = true
+			continue
+		}
+		var byType map[string]json.RawMessage
+		if json.Unmarshal(stmt.Principal, &byType) != nil {
+			continue
+		}
+		for _, raw := range byType {
+			var one string
+			var many []string
+			if json.Unmarshal(raw, &one) == nil {
+				seen[one] = true
+			} else if json.Unmarshal(raw, &many) == nil {
+				for _, p := range many {
+					seen[p] = true
+				}
+			}
+		}
+	}
+	var principals []string
+	for p := range seen {
+		principals = append(principals, p)
+	}
+	sort.Strings(principals)
+	return principals
+}
@@ -0,0 +1,174 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/efs"
+)
+
+// EFSFileSystem represents an EFS file system with its mount targets and access points.
+type EFSFileSystem struct {
+	FileSystemID                 string            `json:"FileSystemId"`
+	FileSystemArn                string            `json:"FileSystemArn"`
+	Name                         string            `json:"Name"`
+	LifeCycleState               string            `json:"LifeCycleState"`
+	Encrypted                    bool              `json:"Encrypted"`
+	KmsKeyID                     string            `json:"KmsKeyId"`
+	PerformanceMode              string            `json:"PerformanceMode"`
+	ThroughputMode               string            `json:"ThroughputMode"`
+	ProvisionedThroughputInMibps float64           `json:"ProvisionedThroughputInMibps"`
+	SizeInBytes                  int64             `json:"SizeInBytes"`
+	AvailabilityZoneName         string            `json:"AvailabilityZoneName"` // Set for One Zone file systems only.
+	TransitionToIA               string            `json:"TransitionToIA"`
+	TransitionToArchive          string            `json:"TransitionToArchive"`
+	TransitionToPrimary          string            `json:"TransitionToPrimaryStorageClass"`
+	Tags                         map[string]string `json:"Tags"`
+	MountTargets                 []EFSMountTarget  `json:"MountTargets"`
+	AccessPoints                 []EFSAccessPoint  `json:"AccessPoints"`
+	Findings                     *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
+}
+
+// EFSMountTarget represents a mount target of an EFS file system in a subnet.
+type EFSMountTarget struct {
+	MountTargetID      string   `json:"MountTargetId"`
+	SubnetID           string   `json:"SubnetId"`
+	VpcID              string   `json:"VpcId"`
+	AvailabilityZone   string   `json:"AvailabilityZoneName"`
+	IPAddress          string   `json:"IpAddress"`
+	NetworkInterfaceID string   `json:"NetworkInterfaceId"`
+	SecurityGroupIDs   []string `json:"SecurityGroupIds"`
+}
+
+// EFSAccessPoint represents an EFS access point.
+type EFSAccessPoint struct {
+	AccessPointID  string `json:"AccessPointId"`
+	AccessPointArn string `json:"AccessPointArn"`
+	Name           string `json:"Name"`
+	RootDirectory  string `json:"RootDirectory"`
+	PosixUID       int64  `json:"PosixUid"`
+	PosixGID       int64  `json:"PosixGid"`
+}
+
+// FetchEFSFileSystems retrieves EFS file systems with their lifecycle policies,
+// mount targets and access points.
+func FetchEFSFileSystems(ctx context.Context, cfg aws.Config) ([]EFSFileSystem, error) {
+	client := efs.NewFromConfig(cfg)
+	paginator := efs.NewDescribeFileSystemsPaginator(client, &efs.DescribeFileSystemsInput{})
+	var fileSystems []EFSFileSystem
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching EFS file systems: %w", err)
+		}
+		for _, f := range page.FileSystems {
+			fs := EFSFileSystem{
+				FileSystemID:                 aws.ToString(f.FileSystemId),
+				FileSystemArn:                aws.ToString(f.FileSystemArn),
+				Name:                         aws.ToString(f.Name),
+				LifeCycleState:               string(f.LifeCycleState),
+				Encrypted:                    aws.ToBool(f.Encrypted),
+				KmsKeyID:                     aws.ToString(f.KmsKeyId),
+				PerformanceMode:              string(f.PerformanceMode),
+				ThroughputMode:               string(f.ThroughputMode),
+				ProvisionedThroughputInMibps: aws.ToFloat64(f.ProvisionedThroughputInMibps),
+				AvailabilityZoneName:         aws.ToString(f.AvailabilityZoneName),
+				Tags:                         make(map[string]string),
+			}
+			if f.SizeInBytes != nil {
+				fs.SizeInBytes = f.SizeInBytes.Value
+			}
+			for _, t := range f.Tags {
+				fs.Tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+			}
+
+			lifecycle, err := client.DescribeLifecycleConfiguration(ctx, &efs.DescribeLifecycleConfigurationInput{
+				FileSystemId: f.FileSystemId,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching lifecycle configuration for EFS file system %s: %w", fs.FileSystemID, err)
+			}
+			// Each transition is returned as a separate policy entry.
+			for _, p := range lifecycle.LifecyclePolicies {
+				if p.TransitionToIA != "" {
+					fs.TransitionToIA = string(p.TransitionToIA)
+				}
+				if p.TransitionToArchive != "" {
+					fs.TransitionToArchive = string(p.TransitionToArchive)
+				}
+				if p.TransitionToPrimaryStorageClass != "" {
+					fs.TransitionToPrimary = string(p.TransitionToPrimaryStorageClass)
+				}
+			}
+
+			fs.MountTargets, err = fetchEFSMountTargets(ctx, client, fs.FileSystemID)
+			if err != nil {
+				return nil, err
+			}
+			fs.AccessPoints, err = fetchEFSAccessPoints(ctx, client, fs.FileSystemID)
+			if err != nil {
+				return nil, err
+			}
+			fileSystems = append(fileSystems, fs)
+		}
+	}
+	return fileSystems, nil
+}
+
+func fetchEFSMountTargets(ctx context.Context, client *efs.Client, fileSystemID string) ([]EFSMountTarget, error) {
+	paginator := efs.NewDescribeMountTargetsPaginator(client, &efs.DescribeMountTargetsInput{FileSystemId: &fileSystemID})
+	var targets []EFSMountTarget
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching mount targets for EFS file system %s: %w", fileSystemID, err)
+		}
+		for _, mt := range page.MountTargets {
+			sgs, err := client.DescribeMountTargetSecurityGroups(ctx, &efs.DescribeMountTargetSecurityGroupsInput{
+				MountTargetId: mt.MountTargetId,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching security groups for EFS mount target %s: %w", aws.ToString(mt.MountTargetId), err)
+			}
+			targets = append(targets, EFSMountTarget{
+				MountTargetID:      aws.ToString(mt.MountTargetId),
+				SubnetID:           aws.ToString(mt.SubnetId),
+				VpcID:              aws.ToString(mt.VpcId),
+				AvailabilityZone:   aws.ToString(mt.AvailabilityZoneName),
+				IPAddress:          aws.ToString(mt.IpAddress),
+				NetworkInterfaceID: aws.ToString(mt.NetworkInterfaceId),
+				SecurityGroupIDs:   sgs.SecurityGroups,
+			})
+		}
+	}
+	return targets, nil
+}
+
+func fetchEFSAccessPoints(ctx context.Context, client *efs.Client, fileSystemID string) ([]EFSAccessPoint, error) {
+	paginator := efs.NewDescribeAccessPointsPaginator(client, &efs.DescribeAccessPointsInput{FileSystemId: &fileSystemID})
+	var points []EFSAccessPoint
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching access points for EFS file system %s: %w", fileSystemID, err)
+		}
+		for _, ap := range page.AccessPoints {
+			point := EFSAccessPoint{
+				AccessPointID:  aws.ToString(ap.AccessPointId),
+				AccessPointArn: aws.ToString(ap.AccessPointArn),
+				Name:           aws.ToString(ap.Name),
+			}
+			if ap.RootDirectory != nil {
+				point.RootDirectory = aws.ToString(ap.RootDirectory.Path)
+			}
+			if ap.PosixUser != nil {
+				point.PosixUID = aws.ToInt64(ap.PosixUser.Uid)
+				point.PosixGID = aws.ToInt64(ap.PosixUser.Gid)
+			}
+			points = append(points, point)
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,51 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+)
+
+// ElasticIP represents an Elastic IP address. Unassociated addresses are
+// billed while idle, which Associated makes easy to spot.
+type ElasticIP struct {
+	AllocationID            string            `json:"AllocationId"`
+	PublicIP                string            `json:"PublicIp"`
+	Domain                  string            `json:"Domain"`
+	PublicIpv4Pool          string            `json:"PublicIpv4Pool"`
+	Associated              bool              `json:"Associated"`
+	AssociationID           string            `json:"AssociationId"`
+	InstanceID              string            `json:"InstanceId"`
+	NetworkInterfaceID      string            `json:"NetworkInterfaceId"`
+	NetworkInterfaceOwnerID string            `json:"NetworkInterfaceOwnerId"`
+	PrivateIPAddress        string            `json:"PrivateIpAddress"`
+	Tags                    map[string]string `json:"Tags"`
+}
+
+// FetchElasticIPs retrieves all Elastic IP addresses.
+func FetchElasticIPs(ctx context.Context, cfg aws.Config) ([]ElasticIP, error) {
+	client := ec2.NewFromConfig(cfg)
+	out, err := client.DescribeAddresses(ctx, &ec2.DescribeAddressesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching Elastic IPs: %w", err)
+	}
+	var addresses []ElasticIP
+	for _, a := range out.Addresses {
+		addresses = append(addresses, ElasticIP{
+			AllocationID:            aws.ToString(a.AllocationId),
+			PublicIP:                aws.ToString(a.PublicIp),
+			Domain:                  string(a.Domain),
+			PublicIpv4Pool:          aws.ToString(a.PublicIpv4Pool),
+			Associated:              a.AssociationId != nil || a.InstanceId != nil,
+			AssociationID:           aws.ToString(a.AssociationId),
+			InstanceID:              aws.ToString(a.InstanceId),
+			NetworkInterfaceID:      aws.ToString(a.NetworkInterfaceId),
+			NetworkInterfaceOwnerID: aws.ToString(a.NetworkInterfaceOwnerId),
+			PrivateIPAddress:        aws.ToString(a.PrivateIpAddress),
+			Tags:                    ec2TagsToMap(a.Tags),
+		})
+	}
+	return addresses, nil
+}
@@ -10,7 +10,8 @@ import (
 
 // EKSCluster represents an EKS cluster.
 type EKSCluster struct {
-	Name string `json:"name"`
+	Name     string            `json:"name"`
+	Findings *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
 	// Additional fields such as version, status, and resourcesVpcConfig can be added.
 }
 
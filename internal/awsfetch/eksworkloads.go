@@ -0,0 +1,224 @@
+package awsfetch
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eks"
+	ekstypes "github.com/aws/aws-sdk-go-v2/service/eks/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// eksRequestTimeout bounds each Kubernetes API request. Clusters with a
+// private endpoint are unreachable from the crawler and would otherwise hang.
+const eksRequestTimeout = 10 * time.Second
+
+// EKSWorkloadError records an EKS cluster whose pods could not be listed, so
+// that its workloads are known to be missing rather than absent.
+type EKSWorkloadError struct {
+	ClusterArn string `json:"ClusterArn"`
+	Error      string `json:"Error"`
+}
+
+// k8sPodList is the part of a Kubernetes PodList the crawler reads.
+type k8sPodList struct {
+	Metadata struct {
+		Continue string `json:"continue"`
+	} `json:"metadata"`
+	Items []k8sPod `json:"items"`
+}
+
+type k8sPod struct {
+	Metadata struct {
+		Name            string            `json:"name"`
+		Namespace       string            `json:"namespace"`
+		Labels          map[string]string `json:"labels"`
+		OwnerReferences []struct {
+			Kind string `json:"kind"`
+			Name string `json:"name"`
+		} `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		Containers     []k8sContainer `json:"containers"`
+		InitContainers []k8sContainer `json:"initContainers"`
+	} `json:"spec"`
+	Status struct {
+		ContainerStatuses     []k8sContainerStatus `json:"containerStatuses"`
+		InitContainerStatuses []k8sContainerStatus `json:"initContainerStatuses"`
+	} `json:"status"`
+}
+
+type k8sContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type k8sContainerStatus struct {
+	Name    string `json:"name"`
+	ImageID string `json:"imageID"`
+}
+
+// fetchEKSImageConsumers returns the images run by the pods of every EKS
+// cluster, one consumer per workload and image. Listing pods needs Kubernetes
+// read access for the crawler's role, such as an EKS access entry with
+// AmazonEKSViewPolicy; clusters that deny it or whose endpoint is private are
+// skipped and returned as errors.
+func fetchEKSImageConsumers(ctx context.Context, cfg aws.Config) ([]ContainerImageConsumer, []EKSWorkloadError, error) {
+	client := eks.NewFromConfig(cfg)
+	presigner := sts.NewPresignClient(sts.NewFromConfig(cfg))
+	paginator := eks.NewListClustersPaginator(client, &eks.ListClustersInput{})
+	var consumers []ContainerImageConsumer
+	var skipped []EKSWorkloadError
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error listing EKS clusters: %w", err)
+		}
+		for _, name := range page.Clusters {
+			desc, err := client.DescribeCluster(ctx, &eks.DescribeClusterInput{Name: &name})
+			if err != nil {
+				return nil, nil, fmt.Errorf("error describing EKS cluster %s: %w", name, err)
+			}
+			cluster := desc.Cluster
+			if cluster == nil || aws.ToString(cluster.Endpoint) == "" || cluster.CertificateAuthority == nil {
+				continue
+			}
+			pods, err := listEKSPods(ctx, presigner, cluster)
+			if err != nil {
+				// Unreachable or unauthorised; the cluster's workloads stay unlinked.
+				skipped = append(skipped, EKSWorkloadError{ClusterArn: aws.ToString(cluster.Arn), Error: err.Error()})
+				continue
+			}
+			consumers = append(consumers, eksPodConsumers(aws.ToString(cluster.Arn), pods)...)
+		}
+	}
+	return consumers, skipped, nil
+}
+
+// listEKSPods lists the pods of every namespace of cluster.
+func listEKSPods(ctx context.Context, presigner *sts.PresignClient, cluster *ekstypes.Cluster) ([]k8sPod, error) {
+	token, err := eksToken(ctx, presigner, aws.ToString(cluster.Name))
+	if err != nil {
+		return nil, err
+	}
+	ca, err := base64.StdEncoding.DecodeString(aws.ToString(cluster.CertificateAuthority.Data))
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate authority for EKS cluster %s: %w", aws.ToString(cluster.Name), err)
+	}
+	pool := x509.NewCertPool()
+	pool.AppendCertsFromPEM(ca)
+	httpClient := &http.Client{
+		Timeout:   eksRequestTimeout,
+		Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}},
+	}
+	defer httpClient.CloseIdleConnections()
+
+	var pods []k8sPod
+	query := url.Values{"limit": {"500"}}
+	for {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, aws.ToString(cluster.Endpoint)+"/api/v1/pods?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept", "application/json")
+		resp, err := httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("error listing pods of EKS cluster %s: %w", aws.ToString(cluster.Name), err)
+		}
+		var list k8sPodList
+		if resp.StatusCode == http.StatusOK {
+			err = json.NewDecoder(resp.Body).Decode(&list)
+		} else {
+			err = fmt.Errorf("status %s", resp.Status)
+		}
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error listing pods of EKS cluster %s: %w", aws.ToString(cluster.Name), err)
+		}
+		pods = append(pods, list.Items...)
+		if list.Metadata.Continue == "" {
+			return pods, nil
+		}
+		query.Set("continue", list.Metadata.Continue)
+	}
+}
+
+// eksToken returns a bearer token for the Kubernetes API of cluster: a
+// presigned STS GetCallerIdentity URL bound to the cluster name, as issued by
+// "aws eks get-token".
+func eksToken(ctx context.Context, presigner *sts.PresignClient, cluster string) (string, error) {
+	req, err := presigner.PresignGetCallerIdentity(ctx, &sts.GetCallerIdentityInput{}, func(o *sts.PresignOptions) {
+		o.ClientOptions = append(o.ClientOptions, func(o *sts.Options) {
+			o.APIOptions = append(o.APIOptions,
+				smithyhttp.SetHeaderValue("x-k8s-aws-id", cluster),
+				smithyhttp.SetHeaderValue("X-Amz-Expires", "60"),
+			)
+		})
+	})
+	if err != nil {
+		return "", fmt.Errorf("error presigning EKS token for cluster %s: %w", cluster, err)
+	}
+	return "k8s-aws-v1." + base64.RawURLEncoding.EncodeToString([]byte(req.URL)), nil
+}
+
+// eksPodConsumers returns one consumer per workload and image among pods.
+// Pods are attributed to the workload that owns them, so the replicas of a
+// Deployment count once.
+func eksPodConsumers(clusterArn string, pods []k8sPod) []ContainerImageConsumer {
+	var consumers []ContainerImageConsumer
+	seen := make(map[ContainerImageConsumer]bool)
+	for _, pod := range pods {
+		workload := podWorkload(pod)
+		resolved := make(map[string]string)
+		for _, s := range append(pod.Status.ContainerStatuses, pod.Status.InitContainerStatuses...) {
+			// The runtime reports the digest it pulled, which survives tag moves.
+			if id := strings.TrimPrefix(s.ImageID, "docker-pullable://"); strings.Contains(id, "@sha256:") {
+				resolved[s.Name] = id
+			}
+		}
+		for _, c := range append(pod.Spec.Containers, pod.Spec.InitContainers...) {
+			image := c.Image
+			if id, ok := resolved[c.Name]; ok {
+				image = id
+			}
+			consumer := ContainerImageConsumer{
+				ResourceType: "eks-workload",
+				ResourceArn:  clusterArn,
+				Workload:     workload,
+				ImageURI:     image,
+			}
+			if !seen[consumer] {
+				seen[consumer] = true
+				consumers = append(consumers, consumer)
+			}
+		}
+	}
+	return consumers
+}
+
+// podWorkload names the workload owning pod as "namespace/Kind/name". Pods of
+// a Deployment are owned by a ReplicaSet named after it plus the pod template
+// hash; pods without an owner are their own workload.
+func podWorkload(pod k8sPod) string {
+	kind, name := "Pod", pod.Metadata.Name
+	if len(pod.Metadata.OwnerReferences) > 0 {
+		owner := pod.Metadata.OwnerReferences[0]
+		kind, name = owner.Kind, owner.Name
+		if hash := pod.Metadata.Labels["pod-template-hash"]; kind == "ReplicaSet" && hash != "" && strings.HasSuffix(name, "-"+hash) {
+			kind, name = "Deployment", strings.TrimSuffix(name, "-"+hash)
+		}
+	}
+	return pod.Metadata.Namespace + "/" + kind + "/" + name
+}
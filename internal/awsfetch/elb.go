@@ -12,7 +12,15 @@ import (
 // LoadBalancer represents a load balancer (both classic and modern).
 type LoadBalancer struct {
 	LoadBalancerName string `json:"LoadBalancerName"`
-	// Add additional fields (e.g., VpcId, Type) as needed.
+	LoadBalancerArn  string `json:"LoadBalancerArn"` // Empty for classic load balancers.
+	Type             string `json:"Type"`            // application, network, gateway or classic.
+	Scheme           string `json:"Scheme"`
+	WebACLArn        string `json:"WebAclArn"` // Set by LinkLoadBalancerWebACLs.
+	// CertificateArns are the ACM certificates the load balancer's listeners
+	// use. Set by LinkACMCertificates.
+	CertificateArns []string          `json:"CertificateArns"`
+	Findings        *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
+	// Add additional fields (e.g., VpcId) as needed.
 }
 
 // FetchLoadBalancers retrieves load balancers from both ELB and ELBv2.
@@ -26,7 +34,12 @@ func FetchLoadBalancers(ctx context.Context, cfg aws.Config) ([]LoadBalancer, er
 		return nil, fmt.Errorf("error fetching modern load balancers: %w", err)
 	}
 	for _, lb := range outV2.LoadBalancers {
-		lbs = append(lbs, LoadBalancer{LoadBalancerName: *lb.LoadBalancerName})
+		lbs = append(lbs, LoadBalancer{
+			LoadBalancerName: *lb.LoadBalancerName,
+			LoadBalancerArn:  aws.ToString(lb.LoadBalancerArn),
+			Type:             string(lb.Type),
+			Scheme:           string(lb.Scheme),
+		})
 	}
 
 	// Classic load balancers using ELB
@@ -36,7 +49,11 @@ func FetchLoadBalancers(ctx context.Context, cfg aws.Config) ([]LoadBalancer, er
 		return nil, fmt.Errorf("error fetching classic load balancers: %w", err)
 	}
 	for _, lb := range outClassic.LoadBalancerDescriptions {
-		lbs = append(lbs, LoadBalancer{LoadBalancerName: *lb.LoadBalancerName})
+		lbs = append(lbs, LoadBalancer{
+			LoadBalancerName: *lb.LoadBalancerName,
+			Type:             "classic",
+			Scheme:           aws.ToString(lb.Scheme),
+		})
 	}
 
 	return lbs, nil
@@ -0,0 +1,165 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// NetworkInterface represents an elastic network interface (ENI).
+// OwnerResourceType and OwnerResourceID identify the resource the interface
+// belongs to, derived from its attachment, interface type and description.
+type NetworkInterface struct {
+	NetworkInterfaceID string            `json:"NetworkInterfaceId"`
+	InterfaceType      string            `json:"InterfaceType"`
+	Description        string            `json:"Description"`
+	Status             string            `json:"Status"`
+	VpcID              string            `json:"VpcId"`
+	SubnetID           string            `json:"SubnetId"`
+	AvailabilityZone   string            `json:"AvailabilityZone"`
+	PrivateIPAddress   string            `json:"PrivateIpAddress"`
+	PrivateIPAddresses []string          `json:"PrivateIpAddresses"`
+	PublicIP           string            `json:"PublicIp"`
+	SecurityGroupIDs   []string          `json:"SecurityGroupIds"`
+	OwnerID            string            `json:"OwnerId"`
+	RequesterID        string            `json:"RequesterId"`
+	RequesterManaged   bool              `json:"RequesterManaged"`
+	AttachmentID       string            `json:"AttachmentId"`
+	AttachmentStatus   string            `json:"AttachmentStatus"`
+	AttachedInstanceID string            `json:"AttachedInstanceId"`
+	DeviceIndex        int32             `json:"DeviceIndex"`
+	OwnerResourceType  string            `json:"OwnerResourceType"`
+	OwnerResourceID    string            `json:"OwnerResourceId"`
+	Tags               map[string]string `json:"Tags"`
+}
+
+// FetchNetworkInterfaces retrieves all network interfaces.
+func FetchNetworkInterfaces(ctx context.Context, cfg aws.Config) ([]NetworkInterface, error) {
+	client := ec2.NewFromConfig(cfg)
+	paginator := ec2.NewDescribeNetworkInterfacesPaginator(client, &ec2.DescribeNetworkInterfacesInput{})
+	var interfaces []NetworkInterface
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching network interfaces: %w", err)
+		}
+		for _, n := range page.NetworkInterfaces {
+			eni := NetworkInterface{
+				NetworkInterfaceID: aws.ToString(n.NetworkInterfaceId),
+				InterfaceType:      string(n.InterfaceType),
+				Description:        aws.ToString(n.Description),
+				Status:             string(n.Status),
+				VpcID:              aws.ToString(n.VpcId),
+				SubnetID:           aws.ToString(n.SubnetId),
+				AvailabilityZone:   aws.ToString(n.AvailabilityZone),
+				PrivateIPAddress:   aws.ToString(n.PrivateIpAddress),
+				OwnerID:            aws.ToString(n.OwnerId),
+				RequesterID:        aws.ToString(n.RequesterId),
+				RequesterManaged:   aws.ToBool(n.RequesterManaged),
+				Tags:               ec2TagsToMap(n.TagSet),
+			}
+			for _, ip := range n.PrivateIpAddresses {
+				eni.PrivateIPAddresses = append(eni.PrivateIPAddresses, aws.ToString(ip.PrivateIpAddress))
+			}
+			if n.Association != nil {
+				eni.PublicIP = aws.ToString(n.Association.PublicIp)
+			}
+			for _, g := range n.Groups {
+				eni.SecurityGroupIDs = append(eni.SecurityGroupIDs, aws.ToString(g.GroupId))
+			}
+			if a := n.Attachment; a != nil {
+				eni.AttachmentID = aws.ToString(a.AttachmentId)
+				eni.AttachmentStatus = string(a.Status)
+				eni.AttachedInstanceID = aws.ToString(a.InstanceId)
+				eni.DeviceIndex = aws.ToInt32(a.DeviceIndex)
+			}
+			eni.OwnerResourceType, eni.OwnerResourceID = networkInterfaceOwner(n)
+			interfaces = append(interfaces, eni)
+		}
+	}
+	return interfaces, nil
+}
+
+// LinkRDSNetworkInterfaces resolves the DB instance owning each RDS network
+// interface. RDS does not name the instance anywhere on the interface, so it is
+// the one instance whose subnet group holds the interface's subnet and whose
+// security groups are the interface's. Interfaces that match no instance, or
+// several, are left without an owner.
+func LinkRDSNetworkInterfaces(interfaces []NetworkInterface, instances []RDSInstance) {
+	for i := range interfaces {
+		eni := &interfaces[i]
+		if eni.OwnerResourceType != "rds-instance" || eni.OwnerResourceID != "" {
+			continue
+		}
+		var owners []string
+		for _, db := range instances {
+			if db.VpcID == eni.VpcID && containsString(db.SubnetIDs, eni.SubnetID) && sameStrings(db.SecurityGroupIDs, eni.SecurityGroupIDs) {
+				owners = append(owners, db.DBInstanceIdentifier)
+			}
+		}
+		if len(owners) == 1 {
+			eni.OwnerResourceID = owners[0]
+		} else {
+			eni.OwnerResourceType = ""
+		}
+	}
+}
+
+// sameStrings reports whether a and b hold the same strings, in any order.
+func sameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, s := range a {
+		if !containsString(b, s) {
+			return false
+		}
+	}
+	return true
+}
+
+// networkInterfaceOwner works out which resource a network interface belongs to.
+// AWS-managed interfaces only name their owner in the description, so the
+// well-known description formats are parsed here.
+func networkInterfaceOwner(n ec2types.NetworkInterface) (string, string) {
+	desc := aws.ToString(n.Description)
+
+	switch n.InterfaceType {
+	case ec2types.NetworkInterfaceTypeNatGateway:
+		return "nat-gateway", strings.TrimPrefix(desc, "Interface for NAT Gateway ")
+	case ec2types.NetworkInterfaceTypeLambda:
+		name := strings.TrimPrefix(desc, "AWS Lambda VPC ENI-")
+		// The description ends with "-<uuid>"; strip it to get the function name.
+		if len(name) > 37 && name[len(name)-37] == '-' {
+			name = name[:len(name)-37]
+		}
+		return "lambda-function", name
+	case ec2types.NetworkInterfaceTypeVpcEndpoint:
+		return "vpc-endpoint", strings.TrimPrefix(desc, "VPC Endpoint Interface ")
+	case ec2types.NetworkInterfaceTypeTransitGateway:
+		return "transit-gateway-attachment", strings.TrimPrefix(desc, "Network Interface for Transit Gateway Attachment ")
+	}
+
+	switch {
+	case strings.HasPrefix(desc, "ELB "):
+		// "ELB app/name/id", "ELB net/name/id" or "ELB name" for classic load balancers.
+		name := strings.TrimPrefix(desc, "ELB ")
+		if parts := strings.Split(name, "/"); len(parts) == 3 {
+			name = parts[1]
+		}
+		return "load-balancer", name
+	case strings.HasPrefix(desc, "Amazon EKS "):
+		return "eks-cluster", strings.TrimPrefix(desc, "Amazon EKS ")
+	case desc == "RDSNetworkInterface":
+		// Resolved against the crawled instances by LinkRDSNetworkInterfaces.
+		return "rds-instance", ""
+	case n.Attachment != nil && n.Attachment.InstanceId != nil:
+		return "ec2-instance", *n.Attachment.InstanceId
+	}
+	return "", ""
+}
@@ -0,0 +1,33 @@
+package awsfetch
+
+import "testing"
+
+func TestLinkRDSNetworkInterfaces(t *testing.T) {
+	instances := []RDSInstance{
+		{DBInstanceIdentifier: "orders", VpcID: "vpc-1", SubnetIDs: []string{"subnet-a", "subnet-b"}, SecurityGroupIDs: []string{"sg-1", "sg-2"}},
+		{DBInstanceIdentifier: "users", VpcID: "vpc-1", SubnetIDs: []string{"subnet-a"}, SecurityGroupIDs: []string{"sg-3"}},
+		{DBInstanceIdentifier: "users-replica", VpcID: "vpc-1", SubnetIDs: []string{"subnet-a"}, SecurityGroupIDs: []string{"sg-3"}},
+	}
+	rds := func(subnet string, groups ...string) NetworkInterface {
+		return NetworkInterface{OwnerResourceType: "rds-instance", VpcID: "vpc-1", SubnetID: subnet, SecurityGroupIDs: groups}
+	}
+	tests := []struct {
+		name      string
+		eni       NetworkInterface
+		wantType  string
+		wantOwner string
+	}{
+		{"unique match, groups in any order", rds("subnet-b", "sg-2", "sg-1"), "rds-instance", "orders"},
+		{"ambiguous", rds("subnet-a", "sg-3"), "", ""},
+		{"subnet outside the subnet group", rds("subnet-c", "sg-1", "sg-2"), "", ""},
+		{"different security groups", rds("subnet-a", "sg-1"), "", ""},
+		{"not an RDS interface", NetworkInterface{OwnerResourceType: "ec2-instance", OwnerResourceID: "i-1"}, "ec2-instance", "i-1"},
+	}
+	for _, tt := range tests {
+		enis := []NetworkInterface{tt.eni}
+		LinkRDSNetworkInterfaces(enis, instances)
+		if enis[0].OwnerResourceType != tt.wantType || enis[0].OwnerResourceID != tt.wantOwner {
+			t.Errorf("%s: owner = %q %q, want %q %q", tt.name, enis[0].OwnerResourceType, enis[0].OwnerResourceID, tt.wantType, tt.wantOwner)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/eventbridge"
+	ebtypes "github.com/aws/aws-sdk-go-v2/service/eventbridge/types"
+)
+
+// EventBus represents an EventBridge event bus and its rules.
+type EventBus struct {
+	Name             string      `json:"Name"`
+	Arn              string      `json:"Arn"`
+	PolicyPrincipals []string    `json:"PolicyPrincipals"`
+	Rules            []EventRule `json:"Rules"`
+}
+
+// EventRule represents an EventBridge rule with its targets.
+type EventRule struct {
+	Name               string        `json:"Name"`
+	Arn                string        `json:"Arn"`
+	State              string        `json:"State"`
+	EventPattern       string        `json:"EventPattern"`
+	ScheduleExpression string        `json:"ScheduleExpression"`
+	RoleArn            string        `json:"RoleArn"`
+	ManagedBy          string        `json:"ManagedBy"`
+	Targets            []EventTarget `json:"Targets"`
+}
+
+// EventTarget represents a target of an EventBridge rule. Constant inputs
+// are not collected as they may embed credentials.
+type EventTarget struct {
+	ID                   string `json:"Id"`
+	Arn                  string `json:"Arn"`
+	Service              string `json:"Service"`
+	RoleArn              string `json:"RoleArn"`
+	DeadLetterArn        string `json:"DeadLetterArn"`
+	ECSTaskDefinitionArn string `json:"EcsTaskDefinitionArn"`
+}
+
+// APIDestination represents an EventBridge API destination.
+type APIDestination struct {
+	Name               string `json:"Name"`
+	Arn                string `json:"Arn"`
+	State              string `json:"State"`
+	ConnectionArn      string `json:"ConnectionArn"`
+	InvocationEndpoint string `json:"InvocationEndpoint"`
+	HTTPMethod         string `json:"HttpMethod"`
+	RateLimitPerSecond int32  `json:"InvocationRateLimitPerSecond"`
+}
+
+// EventConnection represents an EventBridge connection. Credentials live in
+// the referenced Secrets Manager secret; parameter values marked secret are redacted.
+type EventConnection struct {
+	Name                  string            `json:"Name"`
+	Arn                   string            `json:"Arn"`
+	State                 string            `json:"State"`
+	AuthorizationType     string            `json:"AuthorizationType"`
+	SecretArn             string            `json:"SecretArn"`
+	APIKeyName            string            `json:"ApiKeyName"`
+	OAuthEndpoint         string            `json:"OAuthAuthorizationEndpoint"`
+	InvocationHeaders     map[string]string `json:"InvocationHeaders"`
+	InvocationQueryParams map[string]string `json:"InvocationQueryStringParameters"`
+	InvocationBodyParams  map[string]string `json:"InvocationBodyParameters"`
+}
+
+// FetchEventBridgeData retrieves event buses with their rules and targets,
+// API destinations and connections.
+func FetchEventBridgeData(ctx context.Context, cfg aws.Config) ([]EventBus, []APIDestination, []EventConnection, error) {
+	client := eventbridge.NewFromConfig(cfg)
+
+	buses, err := fetchEventBuses(ctx, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	destinations, err := fetchAPIDestinations(ctx, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	connections, err := fetchEventConnections(ctx, client)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return buses, destinations, connections, nil
+}
+
+func fetchEventBuses(ctx context.Context, client *eventbridge.Client) ([]EventBus, error) {
+	var buses []EventBus
+	input := &eventbridge.ListEventBusesInput{}
+	for {
+		out, err := client.ListEventBuses(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing event buses: %w", err)
+		}
+		for _, b := range out.EventBuses {
+			bus := EventBus{
+				Name:             aws.ToString(b.Name),
+				Arn:              aws.ToString(b.Arn),
+				PolicyPrincipals: policyPrincipals(aws.ToString(b.Policy)),
+			}
+			bus.Rules, err = fetchEventRules(ctx, client, bus.Name)
+			if err != nil {
+				return nil, err
+			}
+			buses = append(buses, bus)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return buses, nil
+}
+
+func fetchEventRules(ctx context.Context, client *eventbridge.Client, busName string) ([]EventRule, error) {
+	var rules []EventRule
+	input := &eventbridge.ListRulesInput{EventBusName: &busName}
+	for {
+		out, err := client.ListRules(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing rules of event bus %s: %w", busName, err)
+		}
+		for _, r := range out.Rules {
+			rule := EventRule{
+				Name:               aws.ToString(r.Name),
+				Arn:                aws.ToString(r.Arn),
+				State:              string(r.State),
+				EventPattern:       aws.ToString(r.EventPattern),
+				ScheduleExpression: aws.ToString(r.ScheduleExpression),
+				RoleArn:            aws.ToString(r.RoleArn),
+				ManagedBy:          aws.ToString(r.ManagedBy),
+			}
+			rule.Targets, err = fetchEventTargets(ctx, client, busName, rule.Name)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(rules, rule)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return rules, nil
+}
+
+func fetchEventTargets(ctx context.Context, client *eventbridge.Client, busName, ruleName string) ([]EventTarget, error) {
+	var targets []EventTarget
+	input := &eventbridge.ListTargetsByRuleInput{EventBusName: &busName, Rule: &ruleName}
+	for {
+		out, err := client.ListTargetsByRule(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing targets of rule %s: %w", ruleName, err)
+		}
+		for _, t := range out.Targets {
+			target := EventTarget{
+				ID:      aws.ToString(t.Id),
+				Arn:     aws.ToString(t.Arn),
+				Service: arnService(aws.ToString(t.Arn)),
+				RoleArn: aws.ToString(t.RoleArn),
+			}
+			if t.DeadLetterConfig != nil {
+				target.DeadLetterArn = aws.ToString(t.DeadLetterConfig.Arn)
+			}
+			if t.EcsParameters != nil {
+				target.ECSTaskDefinitionArn = aws.ToString(t.EcsParameters.TaskDefinitionArn)
+			}
+			targets = append(targets, target)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return targets, nil
+}
+
+func fetchAPIDestinations(ctx context.Context, client *eventbridge.Client) ([]APIDestination, error) {
+	var destinations []APIDestination
+	input := &eventbridge.ListApiDestinationsInput{}
+	for {
+		out, err := client.ListApiDestinations(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing API destinations: %w", err)
+		}
+		for _, d := range out.ApiDestinations {
+			destinations = append(destinations, APIDestination{
+				Name:               aws.ToString(d.Name),
+				Arn:                aws.ToString(d.ApiDestinationArn),
+				State:              string(d.ApiDestinationState),
+				ConnectionArn:      aws.ToString(d.ConnectionArn),
+				InvocationEndpoint: aws.ToString(d.InvocationEndpoint),
+				HTTPMethod:         string(d.HttpMethod),
+				RateLimitPerSecond: aws.ToInt32(d.InvocationRateLimitPerSecond),
+			})
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return destinations, nil
+}
+
+func fetchEventConnections(ctx context.Context, client *eventbridge.Client) ([]EventConnection, error) {
+	var connections []EventConnection
+	input := &eventbridge.ListConnectionsInput{}
+	for {
+		out, err := client.ListConnections(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing EventBridge connections: %w", err)
+		}
+		for _, c := range out.Connections {
+			desc, err := client.DescribeConnection(ctx, &eventbridge.DescribeConnectionInput{Name: c.Name})
+			if err != nil {
+				return nil, fmt.Errorf("error describing EventBridge connection %s: %w", aws.ToString(c.Name), err)
+			}
+			conn := EventConnection{
+				Name:              aws.ToString(desc.Name),
+				Arn:               aws.ToString(desc.ConnectionArn),
+				State:             string(desc.ConnectionState),
+				AuthorizationType: string(desc.AuthorizationType),
+				SecretArn:         aws.ToString(desc.SecretArn),
+			}
+			if p := desc.AuthParameters; p != nil {
+				if p.ApiKeyAuthParameters != nil {
+					conn.APIKeyName = aws.ToString(p.ApiKeyAuthParameters.ApiKeyName)
+				}
+				if p.OAuthParameters != nil {
+					conn.OAuthEndpoint = aws.ToString(p.OAuthParameters.AuthorizationEndpoint)
+				}
+				if h := p.InvocationHttpParameters; h != nil {
+					conn.InvocationHeaders = connectionParameters(h.HeaderParameters, func(p ebtypes.ConnectionHeaderParameter) (string, string, bool) {
+						return aws.ToString(p.Key), aws.ToString(p.Value), p.IsValueSecret
+					})
+					conn.InvocationQueryParams = connectionParameters(h.QueryStringParameters, func(p ebtypes.ConnectionQueryStringParameter) (string, string, bool) {
+						return aws.ToString(p.Key), aws.ToString(p.Value), p.IsValueSecret
+					})
+					conn.InvocationBodyParams = connectionParameters(h.BodyParameters, func(p ebtypes.ConnectionBodyParameter) (string, string, bool) {
+						return aws.ToString(p.Key), aws.ToString(p.Value), p.IsValueSecret
+					})
+				}
+			}
+			connections = append(connections, conn)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return connections, nil
+}
+
+// connectionParameters converts connection HTTP parameters to a map,
+// redacting values marked secret.
+func connectionParameters[T any](params []T, kv func(T) (key, value string, secret bool)) map[string]string {
+	if len(params) == 0 {
+		return nil
+	}
+	m := make(map[string]string, len(params))
+	for _, p := range params {
+		key, value, secret := kv(p)
+		if secret {
+			value = redactedValue
+		}
+		m[key] = value
+	}
+	return m
+}
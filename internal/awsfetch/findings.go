@@ -0,0 +1,178 @@
+package awsfetch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SecurityFinding represents an active finding reported by Security Hub,
+// GuardDuty, Inspector or IAM Access Analyzer.
+type SecurityFinding struct {
+	Source       string     `json:"Source"` // securityhub, guardduty, inspector or accessanalyzer.
+	ID           string     `json:"Id"`
+	Title        string     `json:"Title"`
+	Type         string     `json:"Type"`
+	Severity     string     `json:"Severity"` // CRITICAL, HIGH, MEDIUM, LOW or INFORMATIONAL; empty when the source does not rate findings.
+	Status       string     `json:"Status"`
+	ResourceType string     `json:"ResourceType"`
+	ResourceArns []string   `json:"ResourceArns"` // ARNs, or resource IDs where the source reports no ARN.
+	FirstSeen    *time.Time `json:"FirstSeen"`
+	LastSeen     *time.Time `json:"LastSeen"`
+}
+
+// ResourceFindings summarises the findings reported against one resource.
+type ResourceFindings struct {
+	FindingIDs      []string `json:"FindingIds"`
+	HighestSeverity string   `json:"HighestSeverity"`
+}
+
+var severityRank = map[string]int{
+	"INFORMATIONAL": 1,
+	"LOW":           2,
+	"MEDIUM":        3,
+	"HIGH":          4,
+	"CRITICAL":      5,
+}
+
+// securityFindingIndex maps resource ARNs to the findings reported against
+// them. EC2-style ARNs (…:instance/i-0abc) are also indexed by resource ID,
+// since some sources report instances by ID only.
+func securityFindingIndex(findings []SecurityFinding) map[string]ResourceFindings {
+	index := make(map[string]ResourceFindings)
+	add := func(key string, f SecurityFinding) {
+		entry := index[key]
+		if containsString(entry.FindingIDs, f.ID) {
+			return
+		}
+		entry.FindingIDs = append(entry.FindingIDs, f.ID)
+		if severityRank[f.Severity] > severityRank[entry.HighestSeverity] {
+			entry.HighestSeverity = f.Severity
+		}
+		index[key] = entry
+	}
+	for _, f := range findings {
+		for _, arn := range f.ResourceArns {
+			if arn == "" {
+				continue
+			}
+			add(arn, f)
+			if id := arnResourceID(arn); id != "" && id != arn {
+				add(id, f)
+			}
+		}
+	}
+	return index
+}
+
+// FindingInventory is the part of the crawled inventory that findings are
+// attached to. Account supplies the partition, region and account ID of the
+// ARNs of resources crawled without one.
+type FindingInventory struct {
+	Account         AccountInfo
+	EC2Instances    []EC2Instance
+	EBSVolumes      []EBSVolume
+	S3Buckets       []S3Bucket
+	IAMUsers        []IAMUser
+	EKSClusters     []EKSCluster
+	RDSInstances    []RDSInstance
+	LoadBalancers   []LoadBalancer
+	ECRRepositories []ECRRepository
+	DynamoDBTables  []DynamoDBTable
+	EFSFileSystems  []EFSFileSystem
+}
+
+// LinkSecurityFindings attaches to each resource of inv a summary of the
+// findings reported against it. Findings about resources that were not
+// crawled stay in the findings list only.
+func LinkSecurityFindings(findings []SecurityFinding, inv FindingInventory) {
+	index := securityFindingIndex(findings)
+	// lookup merges the findings indexed under any of a resource's keys.
+	lookup := func(keys ...string) *ResourceFindings {
+		var merged *ResourceFindings
+		for _, key := range keys {
+			entry, ok := index[key]
+			if !ok {
+				continue
+			}
+			if merged == nil {
+				merged = &ResourceFindings{}
+			}
+			for _, id := range entry.FindingIDs {
+				if !containsString(merged.FindingIDs, id) {
+					merged.FindingIDs = append(merged.FindingIDs, id)
+				}
+			}
+			if severityRank[entry.HighestSeverity] > severityRank[merged.HighestSeverity] {
+				merged.HighestSeverity = entry.HighestSeverity
+			}
+		}
+		return merged
+	}
+	acct := inv.Account
+	arn := func(service, region, resource string) string {
+		return fmt.Sprintf("arn:%s:%s:%s:%s:%s", acct.Partition, service, region, acct.AccountID, resource)
+	}
+
+	for i := range inv.EC2Instances {
+		id := inv.EC2Instances[i].InstanceID
+		inv.EC2Instances[i].Findings = lookup(arn("ec2", acct.Region, "instance/"+id), id)
+	}
+	for i := range inv.EBSVolumes {
+		id := inv.EBSVolumes[i].VolumeID
+		inv.EBSVolumes[i].Findings = lookup(arn("ec2", acct.Region, "volume/"+id), id)
+	}
+	for i := range inv.S3Buckets {
+		inv.S3Buckets[i].Findings = lookup(fmt.Sprintf("arn:%s:s3:::%s", acct.Partition, inv.S3Buckets[i].Name))
+	}
+	for i := range inv.IAMUsers {
+		inv.IAMUsers[i].Findings = lookup(arn("iam", "", "user/"+inv.IAMUsers[i].UserName))
+	}
+	for i := range inv.EKSClusters {
+		inv.EKSClusters[i].Findings = lookup(arn("eks", acct.Region, "cluster/"+inv.EKSClusters[i].Name))
+	}
+	for i := range inv.RDSInstances {
+		inv.RDSInstances[i].Findings = lookup(inv.RDSInstances[i].DBInstanceArn)
+	}
+	for i := range inv.LoadBalancers {
+		inv.LoadBalancers[i].Findings = lookup(inv.LoadBalancers[i].LoadBalancerArn)
+	}
+	for i := range inv.ECRRepositories {
+		inv.ECRRepositories[i].Findings = lookup(inv.ECRRepositories[i].RepositoryArn)
+	}
+	for i := range inv.DynamoDBTables {
+		inv.DynamoDBTables[i].Findings = lookup(inv.DynamoDBTables[i].TableArn)
+	}
+	for i := range inv.EFSFileSystems {
+		inv.EFSFileSystems[i].Findings = lookup(inv.EFSFileSystems[i].FileSystemArn)
+	}
+}
+
+// arnResourceID returns the trailing resource ID of an ARN whose resource is
+// written as "type/id", or "" for other ARNs.
+func arnResourceID(arn string) string {
+	if !strings.HasPrefix(arn, "arn:") {
+		return ""
+	}
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 {
+		return ""
+	}
+	_, id, ok := strings.Cut(parts[5], "/")
+	if !ok || strings.Contains(id, "/") {
+		return ""
+	}
+	return id
+}
+
+// parseFindingTime parses the ISO 8601 timestamps used by Security Hub and GuardDuty.
+func parseFindingTime(s *string) *time.Time {
+	if s == nil {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
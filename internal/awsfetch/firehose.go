@@ -0,0 +1,175 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/firehose"
+	firehosetypes "github.com/aws/aws-sdk-go-v2/service/firehose/types"
+)
+
+// FirehoseDeliveryStream represents a Firehose delivery stream with its source and destinations.
+type FirehoseDeliveryStream struct {
+	DeliveryStreamName   string                `json:"DeliveryStreamName"`
+	DeliveryStreamARN    string                `json:"DeliveryStreamARN"`
+	DeliveryStreamStatus string                `json:"DeliveryStreamStatus"`
+	DeliveryStreamType   string                `json:"DeliveryStreamType"`
+	EncryptionStatus     string                `json:"EncryptionStatus"`
+	EncryptionKeyARN     string                `json:"EncryptionKeyARN"`
+	SourceType           string                `json:"SourceType"`
+	SourceARN            string                `json:"SourceARN"` // Kinesis stream or MSK cluster; empty for direct put.
+	Destinations         []FirehoseDestination `json:"Destinations"`
+}
+
+// FirehoseDestination represents a delivery stream destination.
+// DestinationARN holds the bucket, domain or catalog ARN when the destination
+// is an AWS resource; Endpoint holds the URL of external destinations.
+// Credentials such as Splunk HEC tokens are never collected.
+type FirehoseDestination struct {
+	DestinationID    string   `json:"DestinationId"`
+	DestinationType  string   `json:"DestinationType"`
+	DestinationARN   string   `json:"DestinationARN"`
+	Endpoint         string   `json:"Endpoint"`
+	RoleARN          string   `json:"RoleARN"`
+	BackupBucketARN  string   `json:"BackupBucketARN"`
+	VpcID            string   `json:"VpcId"`
+	SubnetIDs        []string `json:"SubnetIds"`
+	SecurityGroupIDs []string `json:"SecurityGroupIds"`
+}
+
+// FetchFirehoseDeliveryStreams retrieves all Firehose delivery streams.
+func FetchFirehoseDeliveryStreams(ctx context.Context, cfg aws.Config) ([]FirehoseDeliveryStream, error) {
+	client := firehose.NewFromConfig(cfg)
+	var streams []FirehoseDeliveryStream
+
+	input := &firehose.ListDeliveryStreamsInput{}
+	for {
+		page, err := client.ListDeliveryStreams(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Firehose delivery streams: %w", err)
+		}
+		for _, name := range page.DeliveryStreamNames {
+			out, err := client.DescribeDeliveryStream(ctx, &firehose.DescribeDeliveryStreamInput{DeliveryStreamName: aws.String(name)})
+			if err != nil {
+				return nil, fmt.Errorf("error describing Firehose delivery stream %s: %w", name, err)
+			}
+			d := out.DeliveryStreamDescription
+			stream := FirehoseDeliveryStream{
+				DeliveryStreamName:   aws.ToString(d.DeliveryStreamName),
+				DeliveryStreamARN:    aws.ToString(d.DeliveryStreamARN),
+				DeliveryStreamStatus: string(d.DeliveryStreamStatus),
+				DeliveryStreamType:   string(d.DeliveryStreamType),
+				SourceType:           "DirectPut",
+			}
+			if e := d.DeliveryStreamEncryptionConfiguration; e != nil {
+				stream.EncryptionStatus = string(e.Status)
+				stream.EncryptionKeyARN = aws.ToString(e.KeyARN)
+			}
+			if src := d.Source; src != nil {
+				switch {
+				case src.KinesisStreamSourceDescription != nil:
+					stream.SourceType = "KinesisStream"
+					stream.SourceARN = aws.ToString(src.KinesisStreamSourceDescription.KinesisStreamARN)
+				case src.MSKSourceDescription != nil:
+					stream.SourceType = "MSK"
+					stream.SourceARN = aws.ToString(src.MSKSourceDescription.MSKClusterARN)
+				case src.DatabaseSourceDescription != nil:
+					stream.SourceType = "Database"
+				}
+			}
+			for _, dest := range d.Destinations {
+				stream.Destinations = append(stream.Destinations, firehoseDestination(dest))
+			}
+			streams = append(streams, stream)
+		}
+		if !aws.ToBool(page.HasMoreDeliveryStreams) || len(page.DeliveryStreamNames) == 0 {
+			break
+		}
+		input.ExclusiveStartDeliveryStreamName = aws.String(page.DeliveryStreamNames[len(page.DeliveryStreamNames)-1])
+	}
+	return streams, nil
+}
+
+func firehoseDestination(d firehosetypes.DestinationDescription) FirehoseDestination {
+	dest := FirehoseDestination{DestinationID: aws.ToString(d.DestinationId)}
+	var backup *firehosetypes.S3DestinationDescription
+	var vpc *firehosetypes.VpcConfigurationDescription
+
+	switch {
+	case d.ExtendedS3DestinationDescription != nil:
+		s := d.ExtendedS3DestinationDescription
+		dest.DestinationType = "S3"
+		dest.DestinationARN = aws.ToString(s.BucketARN)
+		dest.RoleARN = aws.ToString(s.RoleARN)
+		backup = s.S3BackupDescription
+	case d.S3DestinationDescription != nil:
+		s := d.S3DestinationDescription
+		dest.DestinationType = "S3"
+		dest.DestinationARN = aws.ToString(s.BucketARN)
+		dest.RoleARN = aws.ToString(s.RoleARN)
+	case d.RedshiftDestinationDescription != nil:
+		r := d.RedshiftDestinationDescription
+		dest.DestinationType = "Redshift"
+		dest.Endpoint = aws.ToString(r.ClusterJDBCURL)
+		dest.RoleARN = aws.ToString(r.RoleARN)
+		backup = r.S3DestinationDescription
+	case d.AmazonopensearchserviceDestinationDescription != nil:
+		o := d.AmazonopensearchserviceDestinationDescription
+		dest.DestinationType = "OpenSearch"
+		dest.DestinationARN = aws.ToString(o.DomainARN)
+		dest.Endpoint = aws.ToString(o.ClusterEndpoint)
+		dest.RoleARN = aws.ToString(o.RoleARN)
+		backup, vpc = o.S3DestinationDescription, o.VpcConfigurationDescription
+	case d.ElasticsearchDestinationDescription != nil:
+		e := d.ElasticsearchDestinationDescription
+		dest.DestinationType = "Elasticsearch"
+		dest.DestinationARN = aws.ToString(e.DomainARN)
+		dest.Endpoint = aws.ToString(e.ClusterEndpoint)
+		dest.RoleARN = aws.ToString(e.RoleARN)
+		backup, vpc = e.S3DestinationDescription, e.VpcConfigurationDescription
+	case d.AmazonOpenSearchServerlessDestinationDescription != nil:
+		o := d.AmazonOpenSearchServerlessDestinationDescription
+		dest.DestinationType = "OpenSearchServerless"
+		dest.Endpoint = aws.ToString(o.CollectionEndpoint)
+		dest.RoleARN = aws.ToString(o.RoleARN)
+		backup, vpc = o.S3DestinationDescription, o.VpcConfigurationDescription
+	case d.HttpEndpointDestinationDescription != nil:
+		h := d.HttpEndpointDestinationDescription
+		dest.DestinationType = "HttpEndpoint"
+		if h.EndpointConfiguration != nil {
+			dest.Endpoint = aws.ToString(h.EndpointConfiguration.Url)
+		}
+		dest.RoleARN = aws.ToString(h.RoleARN)
+		backup = h.S3DestinationDescription
+	case d.SplunkDestinationDescription != nil:
+		s := d.SplunkDestinationDescription
+		dest.DestinationType = "Splunk"
+		dest.Endpoint = aws.ToString(s.HECEndpoint)
+		backup = s.S3DestinationDescription
+	case d.SnowflakeDestinationDescription != nil:
+		s := d.SnowflakeDestinationDescription
+		dest.DestinationType = "Snowflake"
+		dest.Endpoint = aws.ToString(s.AccountUrl)
+		dest.RoleARN = aws.ToString(s.RoleARN)
+		backup = s.S3DestinationDescription
+	case d.IcebergDestinationDescription != nil:
+		i := d.IcebergDestinationDescription
+		dest.DestinationType = "Iceberg"
+		if i.CatalogConfiguration != nil {
+			dest.DestinationARN = aws.ToString(i.CatalogConfiguration.CatalogARN)
+		}
+		dest.RoleARN = aws.ToString(i.RoleARN)
+		backup = i.S3DestinationDescription
+	}
+
+	if backup != nil {
+		dest.BackupBucketARN = aws.ToString(backup.BucketARN)
+	}
+	if vpc != nil {
+		dest.VpcID = aws.ToString(vpc.VpcId)
+		dest.SubnetIDs = vpc.SubnetIds
+		dest.SecurityGroupIDs = vpc.SecurityGroupIds
+	}
+	return dest
+}
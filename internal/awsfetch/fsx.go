@@ -0,0 +1,87 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/fsx"
+)
+
+// FSxFileSystem represents an FSx file system of any type (Windows, Lustre,
+// ONTAP or OpenZFS). Security groups are not returned by FSx; they can be
+// read from the file system's network interfaces.
+type FSxFileSystem struct {
+	FileSystemID                 string            `json:"FileSystemId"`
+	ResourceARN                  string            `json:"ResourceARN"`
+	FileSystemType               string            `json:"FileSystemType"`
+	Lifecycle                    string            `json:"Lifecycle"`
+	DeploymentType               string            `json:"DeploymentType"`
+	StorageType                  string            `json:"StorageType"`
+	StorageCapacity              int32             `json:"StorageCapacity"`
+	ThroughputCapacity           int32             `json:"ThroughputCapacity"`
+	KmsKeyID                     string            `json:"KmsKeyId"`
+	DNSName                      string            `json:"DNSName"`
+	VpcID                        string            `json:"VpcId"`
+	SubnetIDs                    []string          `json:"SubnetIds"`
+	NetworkInterfaceIDs          []string          `json:"NetworkInterfaceIds"`
+	AutomaticBackupRetentionDays int32             `json:"AutomaticBackupRetentionDays"`
+	Tags                         map[string]string `json:"Tags"`
+}
+
+// FetchFSxFileSystems retrieves all FSx file systems in the region.
+func FetchFSxFileSystems(ctx context.Context, cfg aws.Config) ([]FSxFileSystem, error) {
+	client := fsx.NewFromConfig(cfg)
+	paginator := fsx.NewDescribeFileSystemsPaginator(client, &fsx.DescribeFileSystemsInput{})
+	var fileSystems []FSxFileSystem
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching FSx file systems: %w", err)
+		}
+		for _, f := range page.FileSystems {
+			fs := FSxFileSystem{
+				FileSystemID:        aws.ToString(f.FileSystemId),
+				ResourceARN:         aws.ToString(f.ResourceARN),
+				FileSystemType:      string(f.FileSystemType),
+				Lifecycle:           string(f.Lifecycle),
+				StorageType:         string(f.StorageType),
+				StorageCapacity:     aws.ToInt32(f.StorageCapacity),
+				KmsKeyID:            aws.ToString(f.KmsKeyId),
+				DNSName:             aws.ToString(f.DNSName),
+				VpcID:               aws.ToString(f.VpcId),
+				SubnetIDs:           f.SubnetIds,
+				NetworkInterfaceIDs: f.NetworkInterfaceIds,
+				Tags:                make(map[string]string),
+			}
+			// Only the type-specific configuration matching FileSystemType is set.
+			switch {
+			case f.WindowsConfiguration != nil:
+				c := f.WindowsConfiguration
+				fs.DeploymentType = string(c.DeploymentType)
+				fs.ThroughputCapacity = aws.ToInt32(c.ThroughputCapacity)
+				fs.AutomaticBackupRetentionDays = aws.ToInt32(c.AutomaticBackupRetentionDays)
+			case f.LustreConfiguration != nil:
+				c := f.LustreConfiguration
+				fs.DeploymentType = string(c.DeploymentType)
+				fs.AutomaticBackupRetentionDays = aws.ToInt32(c.AutomaticBackupRetentionDays)
+			case f.OntapConfiguration != nil:
+				c := f.OntapConfiguration
+				fs.DeploymentType = string(c.DeploymentType)
+				fs.ThroughputCapacity = aws.ToInt32(c.ThroughputCapacity)
+				fs.AutomaticBackupRetentionDays = aws.ToInt32(c.AutomaticBackupRetentionDays)
+			case f.OpenZFSConfiguration != nil:
+				c := f.OpenZFSConfiguration
+				fs.DeploymentType = string(c.DeploymentType)
+				fs.ThroughputCapacity = aws.ToInt32(c.ThroughputCapacity)
+				fs.AutomaticBackupRetentionDays = aws.ToInt32(c.AutomaticBackupRetentionDays)
+			}
+			for _, t := range f.Tags {
+				fs.Tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+			}
+			fileSystems = append(fileSystems, fs)
+		}
+	}
+	return fileSystems, nil
+}
@@ -0,0 +1,150 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/guardduty"
+	gdtypes "github.com/aws/aws-sdk-go-v2/service/guardduty/types"
+)
+
+// guardDutyBatchSize is the maximum number of findings GetFindings accepts.
+const guardDutyBatchSize = 50
+
+// FetchGuardDutyFindings retrieves unarchived findings from every GuardDuty
+// detector in the region. Regions without a detector have no findings.
+func FetchGuardDutyFindings(ctx context.Context, cfg aws.Config) ([]SecurityFinding, error) {
+	client := guardduty.NewFromConfig(cfg)
+	detectors := guardduty.NewListDetectorsPaginator(client, &guardduty.ListDetectorsInput{})
+	var findings []SecurityFinding
+
+	for detectors.HasMorePages() {
+		page, err := detectors.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing GuardDuty detectors: %w", err)
+		}
+		for _, detectorID := range page.DetectorIds {
+			detectorFindings, err := fetchDetectorFindings(ctx, client, detectorID)
+			if err != nil {
+				return nil, err
+			}
+			findings = append(findings, detectorFindings...)
+		}
+	}
+	return findings, nil
+}
+
+func fetchDetectorFindings(ctx context.Context, client *guardduty.Client, detectorID string) ([]SecurityFinding, error) {
+	paginator := guardduty.NewListFindingsPaginator(client, &guardduty.ListFindingsInput{
+		DetectorId: &detectorID,
+		FindingCriteria: &gdtypes.FindingCriteria{
+			Criterion: map[string]gdtypes.Condition{
+				"service.archived": {Equals: []string{"false"}},
+			},
+		},
+		MaxResults: aws.Int32(guardDutyBatchSize),
+	})
+	var findings []SecurityFinding
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing GuardDuty findings of detector %s: %w", detectorID, err)
+		}
+		if len(page.FindingIds) == 0 {
+			continue
+		}
+		out, err := client.GetFindings(ctx, &guardduty.GetFindingsInput{
+			DetectorId: &detectorID,
+			FindingIds: page.FindingIds,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error fetching GuardDuty findings of detector %s: %w", detectorID, err)
+		}
+		for _, f := range out.Findings {
+			finding := SecurityFinding{
+				Source:    "guardduty",
+				ID:        aws.ToString(f.Id),
+				Title:     aws.ToString(f.Title),
+				Type:      aws.ToString(f.Type),
+				Severity:  guardDutySeverity(aws.ToFloat64(f.Severity)),
+				Status:    guardDutyStatus(f),
+				FirstSeen: parseFindingTime(f.CreatedAt),
+				LastSeen:  parseFindingTime(f.UpdatedAt),
+			}
+			if f.Service != nil {
+				if t := parseFindingTime(f.Service.EventFirstSeen); t != nil {
+					finding.FirstSeen = t
+				}
+				if t := parseFindingTime(f.Service.EventLastSeen); t != nil {
+					finding.LastSeen = t
+				}
+			}
+			if f.Resource != nil {
+				finding.ResourceType = aws.ToString(f.Resource.ResourceType)
+				finding.ResourceArns = guardDutyResourceArns(f)
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
+
+// guardDutyStatus reports whether a finding is ACTIVE or has been ARCHIVED.
+func guardDutyStatus(f gdtypes.Finding) string {
+	if f.Service != nil && aws.ToBool(f.Service.Archived) {
+		return "ARCHIVED"
+	}
+	return "ACTIVE"
+}
+
+// guardDutySeverity maps GuardDuty's numeric severity onto the shared labels.
+func guardDutySeverity(severity float64) string {
+	switch {
+	case severity >= 9:
+		return "CRITICAL"
+	case severity >= 7:
+		return "HIGH"
+	case severity >= 4:
+		return "MEDIUM"
+	default:
+		return "LOW"
+	}
+}
+
+// guardDutyResourceArns returns the ARNs of the resources a finding is about.
+// GuardDuty reports EC2 instances and IAM access keys by ID and name only, so
+// their ARNs are built from the finding's partition, region and account.
+func guardDutyResourceArns(f gdtypes.Finding) []string {
+	r := f.Resource
+	partition := aws.ToString(f.Partition)
+	if partition == "" {
+		partition = "aws"
+	}
+	var arns []string
+	if r.InstanceDetails != nil && r.InstanceDetails.InstanceId != nil {
+		arns = append(arns, fmt.Sprintf("arn:%s:ec2:%s:%s:instance/%s",
+			partition, aws.ToString(f.Region), aws.ToString(f.AccountId), *r.InstanceDetails.InstanceId))
+	}
+	if r.AccessKeyDetails != nil && aws.ToString(r.AccessKeyDetails.UserType) == "IAMUser" {
+		arns = append(arns, fmt.Sprintf("arn:%s:iam::%s:user/%s",
+			partition, aws.ToString(f.AccountId), aws.ToString(r.AccessKeyDetails.UserName)))
+	}
+	for _, b := range r.S3BucketDetails {
+		arns = append(arns, aws.ToString(b.Arn))
+	}
+	if r.EksClusterDetails != nil {
+		arns = append(arns, aws.ToString(r.EksClusterDetails.Arn))
+	}
+	if r.EcsClusterDetails != nil {
+		arns = append(arns, aws.ToString(r.EcsClusterDetails.Arn))
+	}
+	if r.LambdaDetails != nil {
+		arns = append(arns, aws.ToString(r.LambdaDetails.FunctionArn))
+	}
+	if r.RdsDbInstanceDetails != nil {
+		arns = append(arns, aws.ToString(r.RdsDbInstanceDetails.DbInstanceArn))
+	}
+	return arns
+}
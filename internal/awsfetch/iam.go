@@ -10,7 +10,8 @@ import (
 
 // IAMUser represents an IAM user.
 type IAMUser struct {
-	UserName string `json:"UserName"`
+	UserName string            `json:"UserName"`
+	Findings *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
 	// Additional fields can be added.
 }
 
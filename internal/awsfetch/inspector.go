@@ -0,0 +1,69 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/inspector2"
+	inspectortypes "github.com/aws/aws-sdk-go-v2/service/inspector2/types"
+)
+
+// FetchInspectorFindings retrieves active Amazon Inspector findings. Accounts
+// where Inspector is not enabled have no findings.
+func FetchInspectorFindings(ctx context.Context, cfg aws.Config) ([]SecurityFinding, error) {
+	client := inspector2.NewFromConfig(cfg)
+
+	status, err := client.BatchGetAccountStatus(ctx, &inspector2.BatchGetAccountStatusInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error fetching Inspector account status: %w", err)
+	}
+	enabled := false
+	for _, a := range status.Accounts {
+		if a.State != nil && a.State.Status == inspectortypes.StatusEnabled {
+			enabled = true
+		}
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	paginator := inspector2.NewListFindingsPaginator(client, &inspector2.ListFindingsInput{
+		FilterCriteria: &inspectortypes.FilterCriteria{
+			FindingStatus: []inspectortypes.StringFilter{
+				{Comparison: inspectortypes.StringComparisonEquals, Value: aws.String(string(inspectortypes.FindingStatusActive))},
+			},
+		},
+	})
+	var findings []SecurityFinding
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Inspector findings: %w", err)
+		}
+		for _, f := range page.Findings {
+			finding := SecurityFinding{
+				Source:    "inspector",
+				ID:        aws.ToString(f.FindingArn),
+				Title:     aws.ToString(f.Title),
+				Type:      string(f.Type),
+				Status:    string(f.Status),
+				FirstSeen: f.FirstObservedAt,
+				LastSeen:  f.LastObservedAt,
+			}
+			// Inspector rates findings it cannot score as UNTRIAGED.
+			if f.Severity != inspectortypes.SeverityUntriaged {
+				finding.Severity = string(f.Severity)
+			}
+			for _, r := range f.Resources {
+				if finding.ResourceType == "" {
+					finding.ResourceType = string(r.Type)
+				}
+				finding.ResourceArns = append(finding.ResourceArns, aws.ToString(r.Id))
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
@@ -0,0 +1,100 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kinesis"
+)
+
+// KinesisStream represents a Kinesis data stream and its shards.
+type KinesisStream struct {
+	StreamName           string         `json:"StreamName"`
+	StreamARN            string         `json:"StreamARN"`
+	StreamStatus         string         `json:"StreamStatus"`
+	StreamMode           string         `json:"StreamMode"`
+	CreationTimestamp    *time.Time     `json:"StreamCreationTimestamp"`
+	RetentionPeriodHours int32          `json:"RetentionPeriodHours"`
+	EncryptionType       string         `json:"EncryptionType"`
+	KeyID                string         `json:"KeyId"`
+	OpenShardCount       int32          `json:"OpenShardCount"`
+	ConsumerCount        int32          `json:"ConsumerCount"`
+	Shards               []KinesisShard `json:"Shards"`
+}
+
+// KinesisShard represents a shard of a Kinesis data stream.
+type KinesisShard struct {
+	ShardID       string `json:"ShardId"`
+	ParentShardID string `json:"ParentShardId"`
+	Closed        bool   `json:"Closed"` // Closed shards were split or merged and no longer accept writes.
+}
+
+// FetchKinesisStreams retrieves Kinesis data streams with their shards.
+func FetchKinesisStreams(ctx context.Context, cfg aws.Config) ([]KinesisStream, error) {
+	client := kinesis.NewFromConfig(cfg)
+	paginator := kinesis.NewListStreamsPaginator(client, &kinesis.ListStreamsInput{})
+	var streams []KinesisStream
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing Kinesis streams: %w", err)
+		}
+		for _, summary := range page.StreamSummaries {
+			out, err := client.DescribeStreamSummary(ctx, &kinesis.DescribeStreamSummaryInput{StreamARN: summary.StreamARN})
+			if err != nil {
+				return nil, fmt.Errorf("error describing Kinesis stream %s: %w", aws.ToString(summary.StreamName), err)
+			}
+			s := out.StreamDescriptionSummary
+			stream := KinesisStream{
+				StreamName:           aws.ToString(s.StreamName),
+				StreamARN:            aws.ToString(s.StreamARN),
+				StreamStatus:         string(s.StreamStatus),
+				CreationTimestamp:    s.StreamCreationTimestamp,
+				RetentionPeriodHours: aws.ToInt32(s.RetentionPeriodHours),
+				EncryptionType:       string(s.EncryptionType),
+				KeyID:                aws.ToString(s.KeyId),
+				OpenShardCount:       aws.ToInt32(s.OpenShardCount),
+				ConsumerCount:        aws.ToInt32(s.ConsumerCount),
+			}
+			if s.StreamModeDetails != nil {
+				stream.StreamMode = string(s.StreamModeDetails.StreamMode)
+			}
+			stream.Shards, err = fetchKinesisShards(ctx, client, stream.StreamARN)
+			if err != nil {
+				return nil, err
+			}
+			streams = append(streams, stream)
+		}
+	}
+	return streams, nil
+}
+
+func fetchKinesisShards(ctx context.Context, client *kinesis.Client, streamARN string) ([]KinesisShard, error) {
+	var shards []KinesisShard
+	input := &kinesis.ListShardsInput{StreamARN: &streamARN}
+	for {
+		out, err := client.ListShards(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing shards of Kinesis stream %s: %w", streamARN, err)
+		}
+		for _, s := range out.Shards {
+			shard := KinesisShard{
+				ShardID:       aws.ToString(s.ShardId),
+				ParentShardID: aws.ToString(s.ParentShardId),
+			}
+			if s.SequenceNumberRange != nil {
+				shard.Closed = s.SequenceNumberRange.EndingSequenceNumber != nil
+			}
+			shards = append(shards, shard)
+		}
+		if out.NextToken == nil {
+			break
+		}
+		// The stream must not be specified together with a continuation token.
+		input = &kinesis.ListShardsInput{NextToken: out.NextToken}
+	}
+	return shards, nil
+}
@@ -0,0 +1,142 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kafka"
+	kafkatypes "github.com/aws/aws-sdk-go-v2/service/kafka/types"
+)
+
+// MSKCluster represents an Amazon MSK cluster, either provisioned or serverless.
+type MSKCluster struct {
+	ClusterName            string            `json:"ClusterName"`
+	ClusterArn             string            `json:"ClusterArn"`
+	ClusterType            string            `json:"ClusterType"`
+	State                  string            `json:"State"`
+	KafkaVersion           string            `json:"KafkaVersion"`
+	InstanceType           string            `json:"InstanceType"`
+	NumberOfBrokerNodes    int32             `json:"NumberOfBrokerNodes"`
+	VolumeSizeGiB          int32             `json:"VolumeSizeGiB"`
+	PublicAccess           string            `json:"PublicAccess"`
+	SubnetIDs              []string          `json:"SubnetIds"`
+	SecurityGroupIDs       []string          `json:"SecurityGroupIds"`
+	AuthIAM                bool              `json:"AuthIAM"`
+	AuthSCRAM              bool              `json:"AuthSCRAM"`
+	AuthTLS                bool              `json:"AuthTLS"`
+	AuthUnauthenticated    bool              `json:"AuthUnauthenticated"`
+	EncryptionInTransit    string            `json:"EncryptionInTransitClientBroker"`
+	EncryptionAtRestKmsKey string            `json:"EncryptionAtRestKmsKeyId"`
+	Tags                   map[string]string `json:"Tags"`
+	BrokerNodes            []MSKBrokerNode   `json:"BrokerNodes"`
+}
+
+// MSKBrokerNode represents a broker of a provisioned MSK cluster.
+type MSKBrokerNode struct {
+	BrokerID           float64  `json:"BrokerId"`
+	ClientSubnet       string   `json:"ClientSubnet"`
+	ClientVpcIPAddress string   `json:"ClientVpcIpAddress"`
+	NetworkInterfaceID string   `json:"NetworkInterfaceId"`
+	Endpoints          []string `json:"Endpoints"`
+}
+
+// FetchMSKClusters retrieves MSK clusters with their broker nodes.
+func FetchMSKClusters(ctx context.Context, cfg aws.Config) ([]MSKCluster, error) {
+	client := kafka.NewFromConfig(cfg)
+	paginator := kafka.NewListClustersV2Paginator(client, &kafka.ListClustersV2Input{})
+	var clusters []MSKCluster
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching MSK clusters: %w", err)
+		}
+		for _, c := range page.ClusterInfoList {
+			cluster := MSKCluster{
+				ClusterName: aws.ToString(c.ClusterName),
+				ClusterArn:  aws.ToString(c.ClusterArn),
+				ClusterType: string(c.ClusterType),
+				State:       string(c.State),
+				Tags:        c.Tags,
+			}
+			if p := c.Provisioned; p != nil {
+				setProvisionedMSKInfo(&cluster, p)
+				cluster.BrokerNodes, err = fetchMSKBrokerNodes(ctx, client, cluster.ClusterArn)
+				if err != nil {
+					return nil, err
+				}
+			}
+			if s := c.Serverless; s != nil {
+				for _, v := range s.VpcConfigs {
+					cluster.SubnetIDs = append(cluster.SubnetIDs, v.SubnetIds...)
+					cluster.SecurityGroupIDs = append(cluster.SecurityGroupIDs, v.SecurityGroupIds...)
+				}
+				if a := s.ClientAuthentication; a != nil && a.Sasl != nil && a.Sasl.Iam != nil {
+					cluster.AuthIAM = aws.ToBool(a.Sasl.Iam.Enabled)
+				}
+			}
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+func setProvisionedMSKInfo(cluster *MSKCluster, p *kafkatypes.Provisioned) {
+	cluster.NumberOfBrokerNodes = aws.ToInt32(p.NumberOfBrokerNodes)
+	if p.CurrentBrokerSoftwareInfo != nil {
+		cluster.KafkaVersion = aws.ToString(p.CurrentBrokerSoftwareInfo.KafkaVersion)
+	}
+	if b := p.BrokerNodeGroupInfo; b != nil {
+		cluster.InstanceType = aws.ToString(b.InstanceType)
+		cluster.SubnetIDs = b.ClientSubnets
+		cluster.SecurityGroupIDs = b.SecurityGroups
+		if b.StorageInfo != nil && b.StorageInfo.EbsStorageInfo != nil {
+			cluster.VolumeSizeGiB = aws.ToInt32(b.StorageInfo.EbsStorageInfo.VolumeSize)
+		}
+		if b.ConnectivityInfo != nil && b.ConnectivityInfo.PublicAccess != nil {
+			cluster.PublicAccess = aws.ToString(b.ConnectivityInfo.PublicAccess.Type)
+		}
+	}
+	if a := p.ClientAuthentication; a != nil {
+		if a.Sasl != nil {
+			cluster.AuthIAM = a.Sasl.Iam != nil && aws.ToBool(a.Sasl.Iam.Enabled)
+			cluster.AuthSCRAM = a.Sasl.Scram != nil && aws.ToBool(a.Sasl.Scram.Enabled)
+		}
+		cluster.AuthTLS = a.Tls != nil && aws.ToBool(a.Tls.Enabled)
+		cluster.AuthUnauthenticated = a.Unauthenticated != nil && aws.ToBool(a.Unauthenticated.Enabled)
+	}
+	if e := p.EncryptionInfo; e != nil {
+		if e.EncryptionInTransit != nil {
+			cluster.EncryptionInTransit = string(e.EncryptionInTransit.ClientBroker)
+		}
+		if e.EncryptionAtRest != nil {
+			cluster.EncryptionAtRestKmsKey = aws.ToString(e.EncryptionAtRest.DataVolumeKMSKeyId)
+		}
+	}
+}
+
+func fetchMSKBrokerNodes(ctx context.Context, client *kafka.Client, clusterArn string) ([]MSKBrokerNode, error) {
+	paginator := kafka.NewListNodesPaginator(client, &kafka.ListNodesInput{ClusterArn: &clusterArn})
+	var nodes []MSKBrokerNode
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing nodes of MSK cluster %s: %w", clusterArn, err)
+		}
+		for _, n := range page.NodeInfoList {
+			b := n.BrokerNodeInfo
+			if b == nil {
+				continue
+			}
+			nodes = append(nodes, MSKBrokerNode{
+				BrokerID:           aws.ToFloat64(b.BrokerId),
+				ClientSubnet:       aws.ToString(b.ClientSubnet),
+				ClientVpcIPAddress: aws.ToString(b.ClientVpcIpAddress),
+				NetworkInterfaceID: aws.ToString(b.AttachedENIId),
+				Endpoints:          b.Endpoints,
+			})
+		}
+	}
+	return nodes, nil
+}
@@ -0,0 +1,108 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/opensearch"
+)
+
+// describeDomainsBatchSize is the maximum number of domains DescribeDomains accepts.
+const describeDomainsBatchSize = 5
+
+// OpenSearchDomain represents an OpenSearch Service domain.
+type OpenSearchDomain struct {
+	DomainName               string   `json:"DomainName"`
+	DomainID                 string   `json:"DomainId"`
+	ARN                      string   `json:"ARN"`
+	EngineVersion            string   `json:"EngineVersion"`
+	Endpoint                 string   `json:"Endpoint"` // Empty for VPC domains, see VpcEndpoint.
+	VpcEndpoint              string   `json:"VpcEndpoint"`
+	InstanceType             string   `json:"InstanceType"`
+	InstanceCount            int32    `json:"InstanceCount"`
+	DedicatedMasterEnabled   bool     `json:"DedicatedMasterEnabled"`
+	DedicatedMasterType      string   `json:"DedicatedMasterType"`
+	DedicatedMasterCount     int32    `json:"DedicatedMasterCount"`
+	WarmEnabled              bool     `json:"WarmEnabled"`
+	WarmCount                int32    `json:"WarmCount"`
+	ZoneAwarenessEnabled     bool     `json:"ZoneAwarenessEnabled"`
+	EncryptionAtRestEnabled  bool     `json:"EncryptionAtRestEnabled"`
+	KmsKeyID                 string   `json:"KmsKeyId"`
+	NodeToNodeEncryption     bool     `json:"NodeToNodeEncryptionEnabled"`
+	EnforceHTTPS             bool     `json:"EnforceHTTPS"`
+	TLSSecurityPolicy        string   `json:"TLSSecurityPolicy"`
+	FineGrainedAccessControl bool     `json:"FineGrainedAccessControlEnabled"`
+	InternalUserDatabase     bool     `json:"InternalUserDatabaseEnabled"`
+	AnonymousAuthEnabled     bool     `json:"AnonymousAuthEnabled"`
+	VpcID                    string   `json:"VpcId"`
+	SubnetIDs                []string `json:"SubnetIds"`
+	SecurityGroupIDs         []string `json:"SecurityGroupIds"`
+	AccessPolicyPrincipals   []string `json:"AccessPolicyPrincipals"`
+}
+
+// FetchOpenSearchDomains retrieves all OpenSearch Service domains in the region.
+func FetchOpenSearchDomains(ctx context.Context, cfg aws.Config) ([]OpenSearchDomain, error) {
+	client := opensearch.NewFromConfig(cfg)
+	list, err := client.ListDomainNames(ctx, &opensearch.ListDomainNamesInput{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing OpenSearch domains: %w", err)
+	}
+	var names []string
+	for _, d := range list.DomainNames {
+		names = append(names, aws.ToString(d.DomainName))
+	}
+
+	var domains []OpenSearchDomain
+	for start := 0; start < len(names); start += describeDomainsBatchSize {
+		end := min(start+describeDomainsBatchSize, len(names))
+		out, err := client.DescribeDomains(ctx, &opensearch.DescribeDomainsInput{DomainNames: names[start:end]})
+		if err != nil {
+			return nil, fmt.Errorf("error describing OpenSearch domains: %w", err)
+		}
+		for _, d := range out.DomainStatusList {
+			domain := OpenSearchDomain{
+				DomainName:             aws.ToString(d.DomainName),
+				DomainID:               aws.ToString(d.DomainId),
+				ARN:                    aws.ToString(d.ARN),
+				EngineVersion:          aws.ToString(d.EngineVersion),
+				Endpoint:               aws.ToString(d.Endpoint),
+				VpcEndpoint:            d.Endpoints["vpc"],
+				AccessPolicyPrincipals: policyPrincipals(aws.ToString(d.AccessPolicies)),
+			}
+			if c := d.ClusterConfig; c != nil {
+				domain.InstanceType = string(c.InstanceType)
+				domain.InstanceCount = aws.ToInt32(c.InstanceCount)
+				domain.DedicatedMasterEnabled = aws.ToBool(c.DedicatedMasterEnabled)
+				domain.DedicatedMasterType = string(c.DedicatedMasterType)
+				domain.DedicatedMasterCount = aws.ToInt32(c.DedicatedMasterCount)
+				domain.WarmEnabled = aws.ToBool(c.WarmEnabled)
+				domain.WarmCount = aws.ToInt32(c.WarmCount)
+				domain.ZoneAwarenessEnabled = aws.ToBool(c.ZoneAwarenessEnabled)
+			}
+			if e := d.EncryptionAtRestOptions; e != nil {
+				domain.EncryptionAtRestEnabled = aws.ToBool(e.Enabled)
+				domain.KmsKeyID = aws.ToString(e.KmsKeyId)
+			}
+			if n := d.NodeToNodeEncryptionOptions; n != nil {
+				domain.NodeToNodeEncryption = aws.ToBool(n.Enabled)
+			}
+			if e := d.DomainEndpointOptions; e != nil {
+				domain.EnforceHTTPS = aws.ToBool(e.EnforceHTTPS)
+				domain.TLSSecurityPolicy = string(e.TLSSecurityPolicy)
+			}
+			if s := d.AdvancedSecurityOptions; s != nil {
+				domain.FineGrainedAccessControl = aws.ToBool(s.Enabled)
+				domain.InternalUserDatabase = aws.ToBool(s.InternalUserDatabaseEnabled)
+				domain.AnonymousAuthEnabled = aws.ToBool(s.AnonymousAuthEnabled)
+			}
+			if v := d.VPCOptions; v != nil {
+				domain.VpcID = aws.ToString(v.VPCId)
+				domain.SubnetIDs = v.SubnetIds
+				domain.SecurityGroupIDs = v.SecurityGroupIds
+			}
+			domains = append(domains, domain)
+		}
+	}
+	return domains, nil
+}
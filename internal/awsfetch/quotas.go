@@ -0,0 +1,290 @@
+package awsfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+	sqtypes "github.com/aws/aws-sdk-go-v2/service/servicequotas/types"
+)
+
+// ServiceQuota represents an applied (or, failing that, default) service
+// quota. Usage and UtilizationPercent are set by ApplyQuotaUsage for quotas
+// whose usage can be derived from the crawled inventory and are nil otherwise.
+type ServiceQuota struct {
+	ServiceCode        string   `json:"ServiceCode"`
+	QuotaCode          string   `json:"QuotaCode"`
+	QuotaName          string   `json:"QuotaName"`
+	QuotaArn           string   `json:"QuotaArn"`
+	Value              float64  `json:"Value"`
+	Unit               string   `json:"Unit"`
+	Adjustable         bool     `json:"Adjustable"`
+	GlobalQuota        bool     `json:"GlobalQuota"`
+	Usage              *float64 `json:"Usage"`
+	UtilizationPercent *float64 `json:"UtilizationPercent"`
+}
+
+// quotaServiceCodes are the Service Quotas service codes of the crawled services.
+var quotaServiceCodes = []string{
+	"ec2",
+	"vpc",
+	"elasticloadbalancing",
+	"autoscaling",
+	"eks",
+	"rds",
+	"lambda",
+	"elasticache",
+	"cloudformation",
+	"ecr",
+	"elasticfilesystem",
+	"apigateway",
+	"kinesis",
+	"states",
+	"events",
+}
+
+// Quotas whose usage is derived from the inventory, keyed by "service/quota code".
+const (
+	quotaStandardOnDemandVCPUs = "ec2/L-1216C47A"
+	quotaElasticIPs            = "ec2/L-0263D0A3"
+	quotaVPCs                  = "vpc/L-F678F1CE"
+	quotaInternetGateways      = "vpc/L-A4707A72"
+	quotaEgressOnlyGateways    = "vpc/L-45FE3B85"
+	quotaNATGatewaysPerAZ      = "vpc/L-FE5A380F"
+	quotaNetworkInterfaces     = "vpc/L-DF5E4CA3"
+	quotaApplicationLBs        = "elasticloadbalancing/L-53DA6B97"
+	quotaNetworkLBs            = "elasticloadbalancing/L-69A177A2"
+	quotaAutoScalingGroups     = "autoscaling/L-CDE20ADC"
+	quotaEKSClusters           = "eks/L-1194D53C"
+	quotaDBInstances           = "rds/L-7B6409FD"
+	quotaStacks                = "cloudformation/L-0485CB21"
+	quotaECRRepositories       = "ecr/L-CFEB8E8D"
+	quotaEFSFileSystems        = "elasticfilesystem/L-848C634D"
+)
+
+// trackedQuotas maps each quota whose usage is derived from the inventory to
+// the crawled service its usage is counted from.
+var trackedQuotas = map[string]string{
+	quotaStandardOnDemandVCPUs: "ec2_instances",
+	quotaElasticIPs:            "elastic_ips",
+	quotaVPCs:                  "vpc",
+	quotaInternetGateways:      "vpc",
+	quotaEgressOnlyGateways:    "egress_only_internet_gateways",
+	quotaNATGatewaysPerAZ:      "vpc",
+	quotaNetworkInterfaces:     "network_interfaces",
+	quotaApplicationLBs:        "load_balancers",
+	quotaNetworkLBs:            "load_balancers",
+	quotaAutoScalingGroups:     "autoscaling_groups",
+	quotaEKSClusters:           "eks_clusters",
+	quotaDBInstances:           "rds_instances",
+	quotaStacks:                "cloudformation_stacks",
+	quotaECRRepositories:       "ecr_repositories",
+	quotaEFSFileSystems:        "efs_file_systems",
+}
+
+// FetchServiceQuotas retrieves the applied quotas of the crawled services.
+// Tracked quotas that have no applied value are filled in with their AWS
+// default so that usage can still be reported against them.
+func FetchServiceQuotas(ctx context.Context, cfg aws.Config) ([]ServiceQuota, error) {
+	client := servicequotas.NewFromConfig(cfg)
+	var quotas []ServiceQuota
+	seen := make(map[string]bool)
+
+	for _, serviceCode := range quotaServiceCodes {
+		paginator := servicequotas.NewListServiceQuotasPaginator(client, &servicequotas.ListServiceQuotasInput{
+			ServiceCode: aws.String(serviceCode),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			var noSuchService *sqtypes.NoSuchResourceException
+			if errors.As(err, &noSuchService) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error listing %s service quotas: %w", serviceCode, err)
+			}
+			for _, q := range page.Quotas {
+				quota := serviceQuota(q)
+				seen[quota.ServiceCode+"/"+quota.QuotaCode] = true
+				quotas = append(quotas, quota)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(trackedQuotas))
+	for key := range trackedQuotas {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		serviceCode, quotaCode, _ := strings.Cut(key, "/")
+		out, err := client.GetAWSDefaultServiceQuota(ctx, &servicequotas.GetAWSDefaultServiceQuotaInput{
+			ServiceCode: aws.String(serviceCode),
+			QuotaCode:   aws.String(quotaCode),
+		})
+		var noSuchQuota *sqtypes.NoSuchResourceException
+		if errors.As(err, &noSuchQuota) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error fetching default service quota %s: %w", key, err)
+		}
+		quotas = append(quotas, serviceQuota(*out.Quota))
+	}
+	return quotas, nil
+}
+
+func serviceQuota(q sqtypes.ServiceQuota) ServiceQuota {
+	return ServiceQuota{
+		ServiceCode: aws.ToString(q.ServiceCode),
+		QuotaCode:   aws.ToString(q.QuotaCode),
+		QuotaName:   aws.ToString(q.QuotaName),
+		QuotaArn:    aws.ToString(q.QuotaArn),
+		Value:       aws.ToFloat64(q.Value),
+		Unit:        aws.ToString(q.Unit),
+		Adjustable:  q.Adjustable,
+		GlobalQuota: q.GlobalQuota,
+	}
+}
+
+// QuotaInventory is the part of the crawled inventory that quota usage is
+// derived from.
+type QuotaInventory struct {
+	// Services are the crawled services, or nil when every service was
+	// crawled. Usage counted from a service outside them is left unset, as
+	// its inventory is missing rather than empty.
+	Services map[string]bool
+
+	EC2Instances               []EC2Instance
+	ElasticIPs                 []ElasticIP
+	VPCs                       []VPC
+	Subnets                    []Subnet
+	InternetGateways           []InternetGateway
+	EgressOnlyInternetGateways []EgressOnlyInternetGateway
+	NATGateways                []NATGateway
+	NetworkInterfaces          []NetworkInterface
+	LoadBalancers              []LoadBalancer
+	AutoScalingGroups          []AutoScalingGroup
+	EKSClusters                []EKSCluster
+	RDSInstances               []RDSInstance
+	CloudFormationStacks       []CloudFormationStack
+	ECRRepositories            []ECRRepository
+	EFSFileSystems             []EFSFileSystem
+}
+
+// ApplyQuotaUsage sets the usage and percent utilization of the quotas whose
+// usage can be derived from inv.
+func ApplyQuotaUsage(quotas []ServiceQuota, inv QuotaInventory) {
+	usage := quotaUsage(inv)
+	for i := range quotas {
+		key := quotas[i].ServiceCode + "/" + quotas[i].QuotaCode
+		used, ok := usage[key]
+		if !ok || (inv.Services != nil && !inv.Services[trackedQuotas[key]]) {
+			continue
+		}
+		quotas[i].Usage = aws.Float64(used)
+		if quotas[i].Value > 0 {
+			quotas[i].UtilizationPercent = aws.Float64(math.Round(used/quotas[i].Value*10000) / 100)
+		}
+	}
+}
+
+func quotaUsage(inv QuotaInventory) map[string]float64 {
+	usage := map[string]float64{
+		quotaVPCs:               float64(len(inv.VPCs)),
+		quotaInternetGateways:   float64(len(inv.InternetGateways)),
+		quotaEgressOnlyGateways: float64(len(inv.EgressOnlyInternetGateways)),
+		quotaNetworkInterfaces:  float64(len(inv.NetworkInterfaces)),
+		quotaAutoScalingGroups:  float64(len(inv.AutoScalingGroups)),
+		quotaEKSClusters:        float64(len(inv.EKSClusters)),
+		quotaDBInstances:        float64(len(inv.RDSInstances)),
+		quotaECRRepositories:    float64(len(inv.ECRRepositories)),
+		quotaEFSFileSystems:     float64(len(inv.EFSFileSystems)),
+	}
+
+	var vcpus float64
+	for _, inst := range inv.EC2Instances {
+		if (inst.State == "running" || inst.State == "pending") && inst.InstanceLifecycle == "" && isStandardInstanceType(inst.InstanceType) {
+			vcpus += float64(inst.VCPUs)
+		}
+	}
+	usage[quotaStandardOnDemandVCPUs] = vcpus
+
+	var eips float64
+	for _, eip := range inv.ElasticIPs {
+		if eip.Domain == "vpc" {
+			eips++
+		}
+	}
+	usage[quotaElasticIPs] = eips
+
+	// The NAT gateway quota applies per Availability Zone; report the busiest one.
+	subnetAZ := make(map[string]string)
+	for _, s := range inv.Subnets {
+		subnetAZ[s.SubnetID] = s.AvailabilityZone
+	}
+	natPerAZ := make(map[string]float64)
+	var maxNAT float64
+	for _, nat := range inv.NATGateways {
+		if nat.State == "deleted" || nat.State == "deleting" || nat.State == "failed" {
+			continue
+		}
+		az := subnetAZ[nat.SubnetID]
+		natPerAZ[az]++
+		maxNAT = max(maxNAT, natPerAZ[az])
+	}
+	usage[quotaNATGatewaysPerAZ] = maxNAT
+
+	var albs, nlbs float64
+	for _, lb := range inv.LoadBalancers {
+		switch lb.Type {
+		case "application":
+			albs++
+		case "network":
+			nlbs++
+		}
+	}
+	usage[quotaApplicationLBs] = albs
+	usage[quotaNetworkLBs] = nlbs
+
+	var stacks float64
+	for _, s := range inv.CloudFormationStacks {
+		if s.StackStatus != "DELETE_COMPLETE" {
+			stacks++
+		}
+	}
+	usage[quotaStacks] = stacks
+	return usage
+}
+
+// standardInstanceClasses are the instance classes, the letters a family
+// name starts with, counted towards the Running On-Demand Standard (A, C, D,
+// H, I, M, R, T, Z) instances quota. New families of these classes, such as
+// "m8g" or "c7i-flex", are counted as they launch. Other classes, such as F,
+// G, P, Inf, Trn, DL, HPC, Mac, VT, X and high memory (U), have quotas of
+// their own.
+var standardInstanceClasses = map[string]bool{
+	"a": true, "c": true, "d": true, "h": true, "i": true, "m": true, "r": true, "t": true, "z": true,
+	// Storage optimized families of the I class.
+	"im": true, "is": true,
+}
+
+// isStandardInstanceType reports whether an instance type, such as
+// "m5.large", counts towards the Running On-Demand Standard instances quota.
+func isStandardInstanceType(instanceType string) bool {
+	// The class ends at the generation digit ("m" of "m5"), or at the "-" of
+	// high memory types ("u" of "u-6tb1").
+	end := strings.IndexFunc(instanceType, func(r rune) bool { return r < 'a' || r > 'z' })
+	if end <= 0 {
+		return false
+	}
+	return standardInstanceClasses[instanceType[:end]]
+}
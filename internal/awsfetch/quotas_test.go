@@ -0,0 +1,72 @@
+package awsfetch
+
+import "testing"
+
+func TestIsStandardInstanceType(t *testing.T) {
+	tests := []struct {
+		instanceType string
+		want         bool
+	}{
+		{"m5.large", true},
+		{"t3a.micro", true},
+		{"c7i-flex.xlarge", true},
+		{"r8g.metal-24xl", true},
+		{"m9zz.large", true}, // A family that does not exist yet.
+		{"a1.medium", true},
+		{"d3en.xlarge", true},
+		{"h1.2xlarge", true},
+		{"i4i.large", true},
+		{"im4gn.large", true},
+		{"is4gen.medium", true},
+		{"z1d.large", true},
+		{"inf2.xlarge", false},
+		{"trn1.2xlarge", false},
+		{"dl1.24xlarge", false},
+		{"hpc7g.4xlarge", false},
+		{"mac2.metal", false},
+		{"mac2-m2pro.metal", false},
+		{"p5.48xlarge", false},
+		{"g6.xlarge", false},
+		{"gr6.4xlarge", false},
+		{"f1.2xlarge", false},
+		{"vt1.3xlarge", false},
+		{"x2idn.16xlarge", false},
+		{"u-6tb1.metal", false},
+		{"u7i-12tb.224xlarge", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := isStandardInstanceType(tt.instanceType); got != tt.want {
+			t.Errorf("isStandardInstanceType(%q) = %v, want %v", tt.instanceType, got, tt.want)
+		}
+	}
+}
+
+func TestApplyQuotaUsage(t *testing.T) {
+	quotas := []ServiceQuota{
+		{ServiceCode: "ec2", QuotaCode: "L-1216C47A", Value: 32},
+		{ServiceCode: "vpc", QuotaCode: "L-F678F1CE", Value: 5},
+		{ServiceCode: "ec2", QuotaCode: "L-OTHER", Value: 10},
+	}
+	inv := QuotaInventory{
+		Services: map[string]bool{"account": true, "ec2_instances": true},
+		EC2Instances: []EC2Instance{
+			{InstanceType: "m5.large", State: "running", VCPUs: 2},
+			{InstanceType: "c7i.xlarge", State: "pending", VCPUs: 4},
+			{InstanceType: "m5.large", State: "stopped", VCPUs: 2},
+			{InstanceType: "m5.large", State: "running", VCPUs: 2, InstanceLifecycle: "spot"},
+			{InstanceType: "p5.48xlarge", State: "running", VCPUs: 192},
+		},
+	}
+	ApplyQuotaUsage(quotas, inv)
+
+	if u, p := quotas[0].Usage, quotas[0].UtilizationPercent; u == nil || *u != 6 || p == nil || *p != 18.75 {
+		t.Errorf("vCPU quota usage = %v, %v, want 6, 18.75", u, p)
+	}
+	if quotas[1].Usage != nil {
+		t.Errorf("VPC quota usage = %v, want unset: vpc was not crawled", *quotas[1].Usage)
+	}
+	if quotas[2].Usage != nil {
+		t.Errorf("untracked quota usage = %v, want unset", *quotas[2].Usage)
+	}
+}
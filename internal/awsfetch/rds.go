@@ -10,20 +10,46 @@ import (
 
 // RDSInstance represents an RDS instance.
 type RDSInstance struct {
-	DBInstanceIdentifier string `json:"DBInstanceIdentifier"`
+	DBInstanceIdentifier string            `json:"DBInstanceIdentifier"`
+	DBInstanceArn        string            `json:"DBInstanceArn"`
+	VpcID                string            `json:"VpcId"`
+	SubnetIDs            []string          `json:"SubnetIds"` // Of the DB subnet group.
+	SecurityGroupIDs     []string          `json:"SecurityGroupIds"`
+	Tags                 map[string]string `json:"Tags"`
+	Findings             *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
 	// Add additional fields as needed.
 }
 
 // FetchRDSInstances retrieves all RDS instances.
 func FetchRDSInstances(ctx context.Context, cfg aws.Config) ([]RDSInstance, error) {
 	client := rds.NewFromConfig(cfg)
-	out, err := client.DescribeDBInstances(ctx, &rds.DescribeDBInstancesInput{})
-	if err != nil {
-		return nil, fmt.Errorf("error fetching RDS instances: %w", err)
-	}
+	paginator := rds.NewDescribeDBInstancesPaginator(client, &rds.DescribeDBInstancesInput{})
 	var instances []RDSInstance
-	for _, db := range out.DBInstances {
-		instances = append(instances, RDSInstance{DBInstanceIdentifier: *db.DBInstanceIdentifier})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching RDS instances: %w", err)
+		}
+		for _, db := range page.DBInstances {
+			instance := RDSInstance{
+				DBInstanceIdentifier: aws.ToString(db.DBInstanceIdentifier),
+				DBInstanceArn:        aws.ToString(db.DBInstanceArn),
+				Tags:                 make(map[string]string),
+			}
+			if g := db.DBSubnetGroup; g != nil {
+				instance.VpcID = aws.ToString(g.VpcId)
+				for _, subnet := range g.Subnets {
+					instance.SubnetIDs = append(instance.SubnetIDs, aws.ToString(subnet.SubnetIdentifier))
+				}
+			}
+			for _, sg := range db.VpcSecurityGroups {
+				instance.SecurityGroupIDs = append(instance.SecurityGroupIDs, aws.ToString(sg.VpcSecurityGroupId))
+			}
+			for _, tag := range db.TagList {
+				instance.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+			}
+			instances = append(instances, instance)
+		}
 	}
 	return instances, nil
 }
@@ -0,0 +1,180 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/redshift"
+	"github.com/aws/aws-sdk-go-v2/service/redshiftserverless"
+)
+
+// RedshiftCluster represents a provisioned Redshift cluster.
+type RedshiftCluster struct {
+	ClusterIdentifier  string            `json:"ClusterIdentifier"`
+	ClusterNamespace   string            `json:"ClusterNamespaceArn"`
+	ClusterStatus      string            `json:"ClusterStatus"`
+	ClusterVersion     string            `json:"ClusterVersion"`
+	NodeType           string            `json:"NodeType"`
+	NumberOfNodes      int32             `json:"NumberOfNodes"`
+	DBName             string            `json:"DBName"`
+	EndpointAddress    string            `json:"EndpointAddress"`
+	EndpointPort       int32             `json:"EndpointPort"`
+	PubliclyAccessible bool              `json:"PubliclyAccessible"`
+	EnhancedVpcRouting bool              `json:"EnhancedVpcRouting"`
+	Encrypted          bool              `json:"Encrypted"`
+	KmsKeyID           string            `json:"KmsKeyId"`
+	VpcID              string            `json:"VpcId"`
+	SubnetGroupName    string            `json:"ClusterSubnetGroupName"`
+	SubnetIDs          []string          `json:"SubnetIds"`
+	SecurityGroupIDs   []string          `json:"VpcSecurityGroupIds"`
+	IamRoleArns        []string          `json:"IamRoleArns"`
+	Tags               map[string]string `json:"Tags"`
+}
+
+// RedshiftServerlessWorkgroup represents a Redshift Serverless workgroup.
+// Database settings and encryption belong to the workgroup's namespace.
+type RedshiftServerlessWorkgroup struct {
+	WorkgroupName      string   `json:"WorkgroupName"`
+	WorkgroupArn       string   `json:"WorkgroupArn"`
+	Status             string   `json:"Status"`
+	NamespaceName      string   `json:"NamespaceName"`
+	NamespaceArn       string   `json:"NamespaceArn"`
+	DBName             string   `json:"DbName"`
+	KmsKeyID           string   `json:"KmsKeyId"`
+	IamRoleArns        []string `json:"IamRoleArns"`
+	BaseCapacity       int32    `json:"BaseCapacity"`
+	MaxCapacity        int32    `json:"MaxCapacity"`
+	EndpointAddress    string   `json:"EndpointAddress"`
+	EndpointPort       int32    `json:"EndpointPort"`
+	PubliclyAccessible bool     `json:"PubliclyAccessible"`
+	EnhancedVpcRouting bool     `json:"EnhancedVpcRouting"`
+	SubnetIDs          []string `json:"SubnetIds"`
+	SecurityGroupIDs   []string `json:"SecurityGroupIds"`
+}
+
+// FetchRedshiftClusters retrieves provisioned Redshift clusters. Subnets are
+// resolved through each cluster's subnet group.
+func FetchRedshiftClusters(ctx context.Context, cfg aws.Config) ([]RedshiftCluster, error) {
+	client := redshift.NewFromConfig(cfg)
+
+	subnetGroups := make(map[string][]string)
+	sgPaginator := redshift.NewDescribeClusterSubnetGroupsPaginator(client, &redshift.DescribeClusterSubnetGroupsInput{})
+	for sgPaginator.HasMorePages() {
+		page, err := sgPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Redshift subnet groups: %w", err)
+		}
+		for _, g := range page.ClusterSubnetGroups {
+			name := aws.ToString(g.ClusterSubnetGroupName)
+			for _, s := range g.Subnets {
+				subnetGroups[name] = append(subnetGroups[name], aws.ToString(s.SubnetIdentifier))
+			}
+		}
+	}
+
+	paginator := redshift.NewDescribeClustersPaginator(client, &redshift.DescribeClustersInput{})
+	var clusters []RedshiftCluster
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Redshift clusters: %w", err)
+		}
+		for _, c := range page.Clusters {
+			cluster := RedshiftCluster{
+				ClusterIdentifier:  aws.ToString(c.ClusterIdentifier),
+				ClusterNamespace:   aws.ToString(c.ClusterNamespaceArn),
+				ClusterStatus:      aws.ToString(c.ClusterStatus),
+				ClusterVersion:     aws.ToString(c.ClusterVersion),
+				NodeType:           aws.ToString(c.NodeType),
+				NumberOfNodes:      aws.ToInt32(c.NumberOfNodes),
+				DBName:             aws.ToString(c.DBName),
+				PubliclyAccessible: aws.ToBool(c.PubliclyAccessible),
+				EnhancedVpcRouting: aws.ToBool(c.EnhancedVpcRouting),
+				Encrypted:          aws.ToBool(c.Encrypted),
+				KmsKeyID:           aws.ToString(c.KmsKeyId),
+				VpcID:              aws.ToString(c.VpcId),
+				SubnetGroupName:    aws.ToString(c.ClusterSubnetGroupName),
+				Tags:               make(map[string]string),
+			}
+			cluster.SubnetIDs = subnetGroups[cluster.SubnetGroupName]
+			if c.Endpoint != nil {
+				cluster.EndpointAddress = aws.ToString(c.Endpoint.Address)
+				cluster.EndpointPort = aws.ToInt32(c.Endpoint.Port)
+			}
+			for _, sg := range c.VpcSecurityGroups {
+				cluster.SecurityGroupIDs = append(cluster.SecurityGroupIDs, aws.ToString(sg.VpcSecurityGroupId))
+			}
+			for _, r := range c.IamRoles {
+				cluster.IamRoleArns = append(cluster.IamRoleArns, aws.ToString(r.IamRoleArn))
+			}
+			for _, t := range c.Tags {
+				cluster.Tags[aws.ToString(t.Key)] = aws.ToString(t.Value)
+			}
+			clusters = append(clusters, cluster)
+		}
+	}
+	return clusters, nil
+}
+
+// FetchRedshiftServerlessWorkgroups retrieves Redshift Serverless workgroups
+// together with the settings of their namespaces.
+func FetchRedshiftServerlessWorkgroups(ctx context.Context, cfg aws.Config) ([]RedshiftServerlessWorkgroup, error) {
+	client := redshiftserverless.NewFromConfig(cfg)
+
+	type namespaceInfo struct {
+		arn, dbName, kmsKeyID string
+		iamRoles              []string
+	}
+	namespaces := make(map[string]namespaceInfo)
+	nsPaginator := redshiftserverless.NewListNamespacesPaginator(client, &redshiftserverless.ListNamespacesInput{})
+	for nsPaginator.HasMorePages() {
+		page, err := nsPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Redshift Serverless namespaces: %w", err)
+		}
+		for _, n := range page.Namespaces {
+			namespaces[aws.ToString(n.NamespaceName)] = namespaceInfo{
+				arn:      aws.ToString(n.NamespaceArn),
+				dbName:   aws.ToString(n.DbName),
+				kmsKeyID: aws.ToString(n.KmsKeyId),
+				iamRoles: n.IamRoles,
+			}
+		}
+	}
+
+	paginator := redshiftserverless.NewListWorkgroupsPaginator(client, &redshiftserverless.ListWorkgroupsInput{})
+	var workgroups []RedshiftServerlessWorkgroup
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Redshift Serverless workgroups: %w", err)
+		}
+		for _, w := range page.Workgroups {
+			workgroup := RedshiftServerlessWorkgroup{
+				WorkgroupName:      aws.ToString(w.WorkgroupName),
+				WorkgroupArn:       aws.ToString(w.WorkgroupArn),
+				Status:             string(w.Status),
+				NamespaceName:      aws.ToString(w.NamespaceName),
+				BaseCapacity:       aws.ToInt32(w.BaseCapacity),
+				MaxCapacity:        aws.ToInt32(w.MaxCapacity),
+				PubliclyAccessible: aws.ToBool(w.PubliclyAccessible),
+				EnhancedVpcRouting: aws.ToBool(w.EnhancedVpcRouting),
+				SubnetIDs:          w.SubnetIds,
+				SecurityGroupIDs:   w.SecurityGroupIds,
+			}
+			if w.Endpoint != nil {
+				workgroup.EndpointAddress = aws.ToString(w.Endpoint.Address)
+				workgroup.EndpointPort = aws.ToInt32(w.Endpoint.Port)
+			}
+			if ns, ok := namespaces[workgroup.NamespaceName]; ok {
+				workgroup.NamespaceArn = ns.arn
+				workgroup.DBName = ns.dbName
+				workgroup.KmsKeyID = ns.kmsKeyID
+				workgroup.IamRoleArns = ns.iamRoles
+			}
+			workgroups = append(workgroups, workgroup)
+		}
+	}
+	return workgroups, nil
+}
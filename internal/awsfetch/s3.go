@@ -10,8 +10,9 @@ import (
 
 // S3Bucket represents an S3 bucket.
 type S3Bucket struct {
-	Name     string `json:"Name"`
-	Location string `json:"Location"`
+	Name     string            `json:"Name"`
+	Location string            `json:"Location"`
+	Findings *ResourceFindings `json:"Findings"` // Set by LinkSecurityFindings.
 	// Additional fields (e.g., Tags, Policy) can be added as needed.
 }
 
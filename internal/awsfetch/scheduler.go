@@ -0,0 +1,80 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+)
+
+// Schedule represents an EventBridge Scheduler schedule and its target.
+// The target input is not collected.
+type Schedule struct {
+	Name                       string     `json:"Name"`
+	Arn                        string     `json:"Arn"`
+	GroupName                  string     `json:"GroupName"`
+	State                      string     `json:"State"`
+	ScheduleExpression         string     `json:"ScheduleExpression"`
+	ScheduleExpressionTimezone string     `json:"ScheduleExpressionTimezone"`
+	StartDate                  *time.Time `json:"StartDate"`
+	EndDate                    *time.Time `json:"EndDate"`
+	FlexibleTimeWindowMode     string     `json:"FlexibleTimeWindowMode"`
+	KmsKeyArn                  string     `json:"KmsKeyArn"`
+	TargetArn                  string     `json:"TargetArn"`
+	TargetService              string     `json:"TargetService"`
+	TargetRoleArn              string     `json:"TargetRoleArn"`
+	DeadLetterArn              string     `json:"DeadLetterArn"`
+	ECSTaskDefinitionArn       string     `json:"EcsTaskDefinitionArn"`
+}
+
+// FetchSchedules retrieves EventBridge Scheduler schedules across all schedule groups.
+func FetchSchedules(ctx context.Context, cfg aws.Config) ([]Schedule, error) {
+	client := scheduler.NewFromConfig(cfg)
+	paginator := scheduler.NewListSchedulesPaginator(client, &scheduler.ListSchedulesInput{})
+	var schedules []Schedule
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing schedules: %w", err)
+		}
+		for _, summary := range page.Schedules {
+			out, err := client.GetSchedule(ctx, &scheduler.GetScheduleInput{
+				Name:      summary.Name,
+				GroupName: summary.GroupName,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching schedule %s: %w", aws.ToString(summary.Name), err)
+			}
+			schedule := Schedule{
+				Name:                       aws.ToString(out.Name),
+				Arn:                        aws.ToString(out.Arn),
+				GroupName:                  aws.ToString(out.GroupName),
+				State:                      string(out.State),
+				ScheduleExpression:         aws.ToString(out.ScheduleExpression),
+				ScheduleExpressionTimezone: aws.ToString(out.ScheduleExpressionTimezone),
+				StartDate:                  out.StartDate,
+				EndDate:                    out.EndDate,
+				KmsKeyArn:                  aws.ToString(out.KmsKeyArn),
+			}
+			if out.FlexibleTimeWindow != nil {
+				schedule.FlexibleTimeWindowMode = string(out.FlexibleTimeWindow.Mode)
+			}
+			if t := out.Target; t != nil {
+				schedule.TargetArn = aws.ToString(t.Arn)
+				schedule.TargetService = arnService(schedule.TargetArn)
+				schedule.TargetRoleArn = aws.ToString(t.RoleArn)
+				if t.DeadLetterConfig != nil {
+					schedule.DeadLetterArn = aws.ToString(t.DeadLetterConfig.Arn)
+				}
+				if t.EcsParameters != nil {
+					schedule.ECSTaskDefinitionArn = aws.ToString(t.EcsParameters.TaskDefinitionArn)
+				}
+			}
+			schedules = append(schedules, schedule)
+		}
+	}
+	return schedules, nil
+}
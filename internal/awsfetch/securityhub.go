@@ -0,0 +1,85 @@
+package awsfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/securityhub"
+	shtypes "github.com/aws/aws-sdk-go-v2/service/securityhub/types"
+)
+
+// securityHubDuplicateProducts are integrations whose findings are fetched
+// from the originating service instead of through Security Hub.
+var securityHubDuplicateProducts = []string{"GuardDuty", "Inspector", "IAM Access Analyzer"}
+
+// FetchSecurityHubFindings retrieves active, unresolved Security Hub findings.
+// Accounts where Security Hub is not enabled have no findings.
+func FetchSecurityHubFindings(ctx context.Context, cfg aws.Config) ([]SecurityFinding, error) {
+	filters := &shtypes.AwsSecurityFindingFilters{
+		RecordState: []shtypes.StringFilter{
+			{Comparison: shtypes.StringFilterComparisonEquals, Value: aws.String(string(shtypes.RecordStateActive))},
+		},
+		WorkflowStatus: []shtypes.StringFilter{
+			{Comparison: shtypes.StringFilterComparisonEquals, Value: aws.String(string(shtypes.WorkflowStatusNew))},
+			{Comparison: shtypes.StringFilterComparisonEquals, Value: aws.String(string(shtypes.WorkflowStatusNotified))},
+		},
+	}
+	for _, product := range securityHubDuplicateProducts {
+		filters.ProductName = append(filters.ProductName, shtypes.StringFilter{
+			Comparison: shtypes.StringFilterComparisonNotEquals,
+			Value:      aws.String(product),
+		})
+	}
+
+	client := securityhub.NewFromConfig(cfg)
+	paginator := securityhub.NewGetFindingsPaginator(client, &securityhub.GetFindingsInput{
+		Filters:    filters,
+		MaxResults: aws.Int32(100),
+	})
+	var findings []SecurityFinding
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		var notEnabled *shtypes.InvalidAccessException
+		if errors.As(err, &notEnabled) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Security Hub findings: %w", err)
+		}
+		for _, f := range page.Findings {
+			finding := SecurityFinding{
+				Source:    "securityhub",
+				ID:        aws.ToString(f.Id),
+				Title:     aws.ToString(f.Title),
+				FirstSeen: parseFindingTime(f.FirstObservedAt),
+				LastSeen:  parseFindingTime(f.LastObservedAt),
+			}
+			if len(f.Types) > 0 {
+				finding.Type = f.Types[0]
+			}
+			if f.Severity != nil {
+				finding.Severity = string(f.Severity.Label)
+			}
+			if f.Workflow != nil {
+				finding.Status = string(f.Workflow.Status)
+			}
+			if finding.FirstSeen == nil {
+				finding.FirstSeen = parseFindingTime(f.CreatedAt)
+			}
+			if finding.LastSeen == nil {
+				finding.LastSeen = parseFindingTime(f.UpdatedAt)
+			}
+			for _, r := range f.Resources {
+				if finding.ResourceType == "" {
+					finding.ResourceType = aws.ToString(r.Type)
+				}
+				finding.ResourceArns = append(finding.ResourceArns, aws.ToString(r.Id))
+			}
+			findings = append(findings, finding)
+		}
+	}
+	return findings, nil
+}
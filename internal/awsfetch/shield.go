@@ -0,0 +1,60 @@
+package awsfetch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/shield"
+	shieldtypes "github.com/aws/aws-sdk-go-v2/service/shield/types"
+)
+
+// ShieldProtection represents a Shield Advanced protection on a resource.
+type ShieldProtection struct {
+	ID                      string   `json:"Id"`
+	Name                    string   `json:"Name"`
+	ProtectionArn           string   `json:"ProtectionArn"`
+	ResourceArn             string   `json:"ResourceArn"`
+	HealthCheckIDs          []string `json:"HealthCheckIds"`
+	AutomaticResponseStatus string   `json:"ApplicationLayerAutomaticResponseStatus"`
+}
+
+// FetchShieldProtections retrieves Shield Advanced protections. Accounts
+// without a Shield Advanced subscription have no protections.
+func FetchShieldProtections(ctx context.Context, cfg aws.Config) ([]ShieldProtection, error) {
+	if !supportsCloudFront(cfg.Region) {
+		return nil, nil
+	}
+	// Shield is a global service served from us-east-1.
+	globalCfg := cfg.Copy()
+	globalCfg.Region = cloudFrontRegion
+	client := shield.NewFromConfig(globalCfg)
+	paginator := shield.NewListProtectionsPaginator(client, &shield.ListProtectionsInput{})
+	var protections []ShieldProtection
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		var notFound *shieldtypes.ResourceNotFoundException
+		if errors.As(err, &notFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error listing Shield protections: %w", err)
+		}
+		for _, p := range page.Protections {
+			protection := ShieldProtection{
+				ID:             aws.ToString(p.Id),
+				Name:           aws.ToString(p.Name),
+				ProtectionArn:  aws.ToString(p.ProtectionArn),
+				ResourceArn:    aws.ToString(p.ResourceArn),
+				HealthCheckIDs: p.HealthCheckIds,
+			}
+			if p.ApplicationLayerAutomaticResponseConfiguration != nil {
+				protection.AutomaticResponseStatus = string(p.ApplicationLayerAutomaticResponseConfiguration.Status)
+			}
+			protections = append(protections, protection)
+		}
+	}
+	return protections, nil
+}
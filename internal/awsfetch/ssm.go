@@ -0,0 +1,203 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+)
+
+// ssmPatchStateBatchSize is the maximum number of instance IDs
+// DescribeInstancePatchStates accepts.
+const ssmPatchStateBatchSize = 50
+
+// SSMManagedInstance represents a node managed by Systems Manager together
+// with its inventoried applications and patch compliance.
+type SSMManagedInstance struct {
+	InstanceID       string              `json:"InstanceId"` // An EC2 instance ID, or mi-… for hybrid nodes.
+	ResourceType     string              `json:"ResourceType"`
+	PingStatus       string              `json:"PingStatus"`
+	LastPingDateTime *time.Time          `json:"LastPingDateTime"`
+	AgentVersion     string              `json:"AgentVersion"`
+	IsLatestVersion  bool                `json:"IsLatestVersion"`
+	PlatformType     string              `json:"PlatformType"`
+	PlatformName     string              `json:"PlatformName"`
+	PlatformVersion  string              `json:"PlatformVersion"`
+	ComputerName     string              `json:"ComputerName"`
+	IPAddress        string              `json:"IPAddress"`
+	IamRole          string              `json:"IamRole"`
+	Applications     []SSMApplication    `json:"Applications"`
+	PatchCompliance  *SSMPatchCompliance `json:"PatchCompliance"` // Nil when the node has never been scanned.
+}
+
+// SSMApplication represents an application entry of SSM Inventory.
+type SSMApplication struct {
+	Name         string `json:"Name"`
+	Version      string `json:"Version"`
+	Release      string `json:"Release"`
+	Epoch        string `json:"Epoch"`
+	Architecture string `json:"Architecture"`
+	Publisher    string `json:"Publisher"`
+	PackageID    string `json:"PackageId"`
+}
+
+// SSMPatchCompliance summarises the last patch scan or install on a node.
+type SSMPatchCompliance struct {
+	BaselineID                  string     `json:"BaselineId"`
+	PatchGroup                  string     `json:"PatchGroup"`
+	Operation                   string     `json:"Operation"`
+	OperationEndTime            *time.Time `json:"OperationEndTime"`
+	InstalledCount              int32      `json:"InstalledCount"`
+	InstalledOtherCount         int32      `json:"InstalledOtherCount"`
+	InstalledPendingRebootCount int32      `json:"InstalledPendingRebootCount"`
+	InstalledRejectedCount      int32      `json:"InstalledRejectedCount"`
+	MissingCount                int32      `json:"MissingCount"`
+	FailedCount                 int32      `json:"FailedCount"`
+	NotApplicableCount          int32      `json:"NotApplicableCount"`
+	CriticalNonCompliantCount   int32      `json:"CriticalNonCompliantCount"`
+	SecurityNonCompliantCount   int32      `json:"SecurityNonCompliantCount"`
+	OtherNonCompliantCount      int32      `json:"OtherNonCompliantCount"`
+}
+
+// FetchSSMManagedInstances retrieves Systems Manager managed nodes with their
+// SSM Inventory applications and patch compliance.
+func FetchSSMManagedInstances(ctx context.Context, cfg aws.Config) ([]SSMManagedInstance, error) {
+	client := ssm.NewFromConfig(cfg)
+	paginator := ssm.NewDescribeInstanceInformationPaginator(client, &ssm.DescribeInstanceInformationInput{})
+	var instances []SSMManagedInstance
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching SSM managed instances: %w", err)
+		}
+		for _, info := range page.InstanceInformationList {
+			instance := SSMManagedInstance{
+				InstanceID:       aws.ToString(info.InstanceId),
+				ResourceType:     string(info.ResourceType),
+				PingStatus:       string(info.PingStatus),
+				LastPingDateTime: info.LastPingDateTime,
+				AgentVersion:     aws.ToString(info.AgentVersion),
+				IsLatestVersion:  aws.ToBool(info.IsLatestVersion),
+				PlatformType:     string(info.PlatformType),
+				PlatformName:     aws.ToString(info.PlatformName),
+				PlatformVersion:  aws.ToString(info.PlatformVersion),
+				ComputerName:     aws.ToString(info.ComputerName),
+				IPAddress:        aws.ToString(info.IPAddress),
+				IamRole:          aws.ToString(info.IamRole),
+			}
+			instance.Applications, err = fetchSSMApplications(ctx, client, instance.InstanceID)
+			if err != nil {
+				return nil, err
+			}
+			instances = append(instances, instance)
+		}
+	}
+
+	if err := fetchSSMPatchStates(ctx, client, instances); err != nil {
+		return nil, err
+	}
+	return instances, nil
+}
+
+func fetchSSMApplications(ctx context.Context, client *ssm.Client, instanceID string) ([]SSMApplication, error) {
+	var apps []SSMApplication
+	input := &ssm.ListInventoryEntriesInput{
+		InstanceId: &instanceID,
+		TypeName:   aws.String("AWS:Application"),
+		MaxResults: aws.Int32(50),
+	}
+	for {
+		out, err := client.ListInventoryEntries(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching SSM inventory applications of %s: %w", instanceID, err)
+		}
+		for _, e := range out.Entries {
+			apps = append(apps, SSMApplication{
+				Name:         e["Name"],
+				Version:      e["Version"],
+				Release:      e["Release"],
+				Epoch:        e["Epoch"],
+				Architecture: e["Architecture"],
+				Publisher:    e["Publisher"],
+				PackageID:    e["PackageId"],
+			})
+		}
+		if out.NextToken == nil {
+			break
+		}
+		input.NextToken = out.NextToken
+	}
+	return apps, nil
+}
+
+// fetchSSMPatchStates sets the patch compliance of instances, querying them in batches.
+func fetchSSMPatchStates(ctx context.Context, client *ssm.Client, instances []SSMManagedInstance) error {
+	byID := make(map[string]*SSMManagedInstance, len(instances))
+	var ids []string
+	for i := range instances {
+		byID[instances[i].InstanceID] = &instances[i]
+		ids = append(ids, instances[i].InstanceID)
+	}
+
+	for start := 0; start < len(ids); start += ssmPatchStateBatchSize {
+		batch := ids[start:min(start+ssmPatchStateBatchSize, len(ids))]
+		paginator := ssm.NewDescribeInstancePatchStatesPaginator(client, &ssm.DescribeInstancePatchStatesInput{
+			InstanceIds: batch,
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return fmt.Errorf("error fetching SSM patch states: %w", err)
+			}
+			for _, s := range page.InstancePatchStates {
+				if instance, ok := byID[aws.ToString(s.InstanceId)]; ok {
+					instance.PatchCompliance = ssmPatchCompliance(s)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func ssmPatchCompliance(s ssmtypes.InstancePatchState) *SSMPatchCompliance {
+	return &SSMPatchCompliance{
+		BaselineID:                  aws.ToString(s.BaselineId),
+		PatchGroup:                  aws.ToString(s.PatchGroup),
+		Operation:                   string(s.Operation),
+		OperationEndTime:            s.OperationEndTime,
+		InstalledCount:              s.InstalledCount,
+		InstalledOtherCount:         s.InstalledOtherCount,
+		InstalledPendingRebootCount: aws.ToInt32(s.InstalledPendingRebootCount),
+		InstalledRejectedCount:      aws.ToInt32(s.InstalledRejectedCount),
+		MissingCount:                s.MissingCount,
+		FailedCount:                 s.FailedCount,
+		NotApplicableCount:          s.NotApplicableCount,
+		CriticalNonCompliantCount:   aws.ToInt32(s.CriticalNonCompliantCount),
+		SecurityNonCompliantCount:   aws.ToInt32(s.SecurityNonCompliantCount),
+		OtherNonCompliantCount:      aws.ToInt32(s.OtherNonCompliantCount),
+	}
+}
+
+// LinkSSMManagedInstances attaches Systems Manager data to the crawled EC2
+// instances it describes and returns the managed nodes that are not crawled
+// EC2 instances, such as on-premises servers registered through hybrid activations.
+func LinkSSMManagedInstances(instances []EC2Instance, managed []SSMManagedInstance) []SSMManagedInstance {
+	byID := make(map[string]int, len(instances))
+	for i := range instances {
+		byID[instances[i].InstanceID] = i
+	}
+	var unmatched []SSMManagedInstance
+	for i := range managed {
+		idx, ok := byID[managed[i].InstanceID]
+		if !ok {
+			unmatched = append(unmatched, managed[i])
+			continue
+		}
+		instances[idx].SSM = &managed[i]
+	}
+	return unmatched
+}
@@ -0,0 +1,193 @@
+package awsfetch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sfn"
+)
+
+// StateMachine represents a Step Functions state machine and the resources
+// its Task states invoke. The definition itself is not collected.
+type StateMachine struct {
+	Name                 string             `json:"Name"`
+	StateMachineArn      string             `json:"StateMachineArn"`
+	Type                 string             `json:"Type"`
+	Status               string             `json:"Status"`
+	RoleArn              string             `json:"RoleArn"`
+	LoggingLevel         string             `json:"LoggingLevel"`
+	IncludeExecutionData bool               `json:"IncludeExecutionData"`
+	LogGroupArns         []string           `json:"LogGroupArns"`
+	TracingEnabled       bool               `json:"TracingEnabled"`
+	KmsKeyID             string             `json:"KmsKeyId"`
+	Tasks                []StateMachineTask `json:"Tasks"`
+	LambdaFunctions      []string           `json:"LambdaFunctions"`
+	ECSTaskDefinitions   []string           `json:"EcsTaskDefinitions"`
+}
+
+// StateMachineTask represents a Task state. Resource is the integration ARN
+// (for example "arn:aws:states:::lambda:invoke") and Target the name or ARN of
+// the resource it acts on, when the definition names it statically.
+type StateMachineTask struct {
+	StateName string `json:"StateName"`
+	Resource  string `json:"Resource"`
+	Service   string `json:"Service"`
+	Target    string `json:"Target"`
+	Cluster   string `json:"Cluster"` // Set for ECS tasks only.
+}
+
+// FetchStateMachines retrieves Step Functions state machines and the tasks parsed from their definitions.
+func FetchStateMachines(ctx context.Context, cfg aws.Config) ([]StateMachine, error) {
+	client := sfn.NewFromConfig(cfg)
+	paginator := sfn.NewListStateMachinesPaginator(client, &sfn.ListStateMachinesInput{})
+	var machines []StateMachine
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error listing state machines: %w", err)
+		}
+		for _, item := range page.StateMachines {
+			out, err := client.DescribeStateMachine(ctx, &sfn.DescribeStateMachineInput{StateMachineArn: item.StateMachineArn})
+			if err != nil {
+				return nil, fmt.Errorf("error describing state machine %s: %w", aws.ToString(item.Name), err)
+			}
+			sm := StateMachine{
+				Name:            aws.ToString(out.Name),
+				StateMachineArn: aws.ToString(out.StateMachineArn),
+				Type:            string(out.Type),
+				Status:          string(out.Status),
+				RoleArn:         aws.ToString(out.RoleArn),
+			}
+			if l := out.LoggingConfiguration; l != nil {
+				sm.LoggingLevel = string(l.Level)
+				sm.IncludeExecutionData = l.IncludeExecutionData
+				for _, d := range l.Destinations {
+					if d.CloudWatchLogsLogGroup != nil {
+						sm.LogGroupArns = append(sm.LogGroupArns, aws.ToString(d.CloudWatchLogsLogGroup.LogGroupArn))
+					}
+				}
+			}
+			if out.TracingConfiguration != nil {
+				sm.TracingEnabled = out.TracingConfiguration.Enabled
+			}
+			if out.EncryptionConfiguration != nil {
+				sm.KmsKeyID = aws.ToString(out.EncryptionConfiguration.KmsKeyId)
+			}
+
+			sm.Tasks = stateMachineTasks(aws.ToString(out.Definition))
+			for _, t := range sm.Tasks {
+				switch {
+				case t.Service == "lambda" && t.Target != "" && !containsString(sm.LambdaFunctions, t.Target):
+					sm.LambdaFunctions = append(sm.LambdaFunctions, t.Target)
+				case t.Service == "ecs" && t.Target != "" && !containsString(sm.ECSTaskDefinitions, t.Target):
+					sm.ECSTaskDefinitions = append(sm.ECSTaskDefinitions, t.Target)
+				}
+			}
+			machines = append(machines, sm)
+		}
+	}
+	return machines, nil
+}
+
+// aslState holds the parts of an Amazon States Language state needed to find
+// the resources it invokes. Parameters is used by JSONPath definitions and
+// Arguments by JSONata definitions.
+type aslState struct {
+	Type          string                     `json:"Type"`
+	Resource      string                     `json:"Resource"`
+	Parameters    map[string]json.RawMessage `json:"Parameters"`
+	Arguments     map[string]json.RawMessage `json:"Arguments"`
+	Branches      []aslStates                `json:"Branches"`
+	Iterator      *aslStates                 `json:"Iterator"`
+	ItemProcessor *aslStates                 `json:"ItemProcessor"`
+}
+
+type aslStates struct {
+	States map[string]aslState `json:"States"`
+}
+
+// stateMachineTargetParameters lists, per integrated service, the parameter
+// naming the resource a task acts on.
+var stateMachineTargetParameters = map[string]string{
+	"lambda":   "FunctionName",
+	"ecs":      "TaskDefinition",
+	"states":   "StateMachineArn",
+	"sns":      "TopicArn",
+	"sqs":      "QueueUrl",
+	"glue":     "JobName",
+	"batch":    "JobDefinition",
+	"dynamodb": "TableName",
+}
+
+// stateMachineTasks returns the Task states of a definition, including those
+// nested in Parallel and Map states. Malformed definitions yield no tasks.
+func stateMachineTasks(definition string) []StateMachineTask {
+	var root aslStates
+	if err := json.Unmarshal([]byte(definition), &root); err != nil {
+		return nil
+	}
+	var tasks []StateMachineTask
+	var walk func(states aslStates)
+	walk = func(states aslStates) {
+		for name, s := range states.States {
+			for _, b := range s.Branches {
+				walk(b)
+			}
+			if s.Iterator != nil {
+				walk(*s.Iterator)
+			}
+			if s.ItemProcessor != nil {
+				walk(*s.ItemProcessor)
+			}
+			if s.Type != "Task" {
+				continue
+			}
+			tasks = append(tasks, stateMachineTask(name, s))
+		}
+	}
+	walk(root)
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].StateName < tasks[j].StateName })
+	return tasks
+}
+
+func stateMachineTask(name string, s aslState) StateMachineTask {
+	task := StateMachineTask{StateName: name, Resource: s.Resource}
+	// Direct Lambda and activity ARNs are the target themselves.
+	if !strings.HasPrefix(s.Resource, "arn:") || !strings.Contains(s.Resource, ":states:::") {
+		task.Service = arnService(s.Resource)
+		task.Target = s.Resource
+		return task
+	}
+	// Service integrations look like "arn:aws:states:::ecs:runTask.sync".
+	integration := s.Resource[strings.Index(s.Resource, ":::")+3:]
+	task.Service, _, _ = strings.Cut(integration, ":")
+	params := s.Parameters
+	if params == nil {
+		params = s.Arguments
+	}
+	if key, ok := stateMachineTargetParameters[task.Service]; ok {
+		json.Unmarshal(params[key], &task.Target)
+	}
+	if task.Service == "ecs" {
+		json.Unmarshal(params["Cluster"], &task.Cluster)
+	}
+	// JSONata expressions are only resolved at run time.
+	if strings.HasPrefix(task.Target, "{%") {
+		task.Target = ""
+	}
+	return task
+}
+
+// arnService returns the service segment of an ARN, or "" if s is not an ARN.
+func arnService(s string) string {
+	parts := strings.SplitN(s, ":", 4)
+	if len(parts) < 4 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[2]
+}
@@ -3,9 +3,11 @@ package awsfetch
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 )
 
 // VPC represents a Virtual Private Cloud.
@@ -15,20 +17,40 @@ type VPC struct {
 
 // Subnet represents a VPC subnet.
 type Subnet struct {
-	SubnetID string `json:"SubnetId"`
-	VpcID    string `json:"VpcId"`
+	SubnetID         string `json:"SubnetId"`
+	VpcID            string `json:"VpcId"`
+	AvailabilityZone string `json:"AvailabilityZone"`
 }
 
 // RouteTable represents a VPC route table.
 type RouteTable struct {
-	RouteTableID string `json:"RouteTableId"`
+	RouteTableID string   `json:"RouteTableId"`
+	VpcID        string   `json:"VpcId"`
+	Main         bool     `json:"Main"`
+	SubnetIDs    []string `json:"SubnetIds"`
+	Routes       []Route  `json:"Routes"`
+}
+
+// Route represents a single route table entry. TargetType and TargetID identify
+// the resource traffic is sent to, e.g. "transit-gateway" and "tgw-0123".
+type Route struct {
+	DestinationCidrBlock     string `json:"DestinationCidrBlock"`
+	DestinationIpv6CidrBlock string `json:"DestinationIpv6CidrBlock"`
+	DestinationPrefixListID  string `json:"DestinationPrefixListId"`
+	TargetType               string `json:"TargetType"`
+	TargetID                 string `json:"TargetId"`
+	State                    string `json:"State"`
+	Origin                   string `json:"Origin"`
 }
 
 // NATGateway represents a NAT gateway.
 type NATGateway struct {
-	NatGatewayID string `json:"NatGatewayId"`
-	SubnetID     string `json:"SubnetId"`
-	VpcID        string `json:"VpcId"`
+	NatGatewayID        string   `json:"NatGatewayId"`
+	SubnetID            string   `json:"SubnetId"`
+	VpcID               string   `json:"VpcId"`
+	State               string   `json:"State"`
+	NetworkInterfaceIDs []string `json:"NetworkInterfaceIds"`
+	AllocationIDs       []string `json:"AllocationIds"`
 }
 
 // InternetGateway represents an Internet gateway.
@@ -57,7 +79,7 @@ func FetchVPCData(ctx context.Context, cfg aws.Config) ([]VPC, []Subnet, []Route
 	}
 	var subnets []Subnet
 	for _, s := range subnetOut.Subnets {
-		subnet := Subnet{SubnetID: *s.SubnetId}
+		subnet := Subnet{SubnetID: *s.SubnetId, AvailabilityZone: aws.ToString(s.AvailabilityZone)}
 		if s.VpcId != nil {
 			subnet.VpcID = *s.VpcId
 		}
@@ -71,7 +93,30 @@ func FetchVPCData(ctx context.Context, cfg aws.Config) ([]VPC, []Subnet, []Route
 	}
 	var routeTables []RouteTable
 	for _, rt := range rtOut.RouteTables {
-		routeTables = append(routeTables, RouteTable{RouteTableID: *rt.RouteTableId})
+		routeTable := RouteTable{RouteTableID: *rt.RouteTableId}
+		if rt.VpcId != nil {
+			routeTable.VpcID = *rt.VpcId
+		}
+		for _, assoc := range rt.Associations {
+			if aws.ToBool(assoc.Main) {
+				routeTable.Main = true
+			}
+			if assoc.SubnetId != nil {
+				routeTable.SubnetIDs = append(routeTable.SubnetIDs, *assoc.SubnetId)
+			}
+		}
+		for _, r := range rt.Routes {
+			route := Route{
+				DestinationCidrBlock:     aws.ToString(r.DestinationCidrBlock),
+				DestinationIpv6CidrBlock: aws.ToString(r.DestinationIpv6CidrBlock),
+				DestinationPrefixListID:  aws.ToString(r.DestinationPrefixListId),
+				State:                    string(r.State),
+				Origin:                   string(r.Origin),
+			}
+			route.TargetType, route.TargetID = routeTarget(r)
+			routeTable.Routes = append(routeTable.Routes, route)
+		}
+		routeTables = append(routeTables, routeTable)
 	}
 
 	// Fetch NAT Gateways
@@ -81,13 +126,21 @@ func FetchVPCData(ctx context.Context, cfg aws.Config) ([]VPC, []Subnet, []Route
 	}
 	var natGateways []NATGateway
 	for _, nat := range natOut.NatGateways {
-		ng := NATGateway{NatGatewayID: *nat.NatGatewayId}
+		ng := NATGateway{NatGatewayID: *nat.NatGatewayId, State: string(nat.State)}
 		if nat.SubnetId != nil {
 			ng.SubnetID = *nat.SubnetId
 		}
 		if nat.VpcId != nil {
 			ng.VpcID = *nat.VpcId
 		}
+		for _, addr := range nat.NatGatewayAddresses {
+			if addr.NetworkInterfaceId != nil {
+				ng.NetworkInterfaceIDs = append(ng.NetworkInterfaceIDs, *addr.NetworkInterfaceId)
+			}
+			if addr.AllocationId != nil {
+				ng.AllocationIDs = append(ng.AllocationIDs, *addr.AllocationId)
+			}
+		}
 		natGateways = append(natGateways, ng)
 	}
 
@@ -103,3 +156,43 @@ func FetchVPCData(ctx context.Context, cfg aws.Config) ([]VPC, []Subnet, []Route
 
 	return vpcs, subnets, routeTables, natGateways, internetGateways, nil
 }
+
+// routeTarget classifies the target of a route. Gateway IDs are shared by
+// internet gateways, virtual private gateways and gateway VPC endpoints, so
+// they are told apart by their prefix.
+func routeTarget(r ec2types.Route) (string, string) {
+	switch {
+	case r.TransitGatewayId != nil:
+		return "transit-gateway", *r.TransitGatewayId
+	case r.VpcPeeringConnectionId != nil:
+		return "vpc-peering-connection", *r.VpcPeeringConnectionId
+	case r.NatGatewayId != nil:
+		return "nat-gateway", *r.NatGatewayId
+	case r.EgressOnlyInternetGatewayId != nil:
+		return "egress-only-internet-gateway", *r.EgressOnlyInternetGatewayId
+	case r.CarrierGatewayId != nil:
+		return "carrier-gateway", *r.CarrierGatewayId
+	case r.LocalGatewayId != nil:
+		return "local-gateway", *r.LocalGatewayId
+	case r.CoreNetworkArn != nil:
+		return "core-network", *r.CoreNetworkArn
+	case r.GatewayId != nil:
+		id := *r.GatewayId
+		switch {
+		case id == "local":
+			return "local", id
+		case strings.HasPrefix(id, "igw-"):
+			return "internet-gateway", id
+		case strings.HasPrefix(id, "vgw-"):
+			return "virtual-private-gateway", id
+		case strings.HasPrefix(id, "vpce-"):
+			return "vpc-endpoint", id
+		}
+		return "gateway", id
+	case r.InstanceId != nil:
+		return "instance", *r.InstanceId
+	case r.NetworkInterfaceId != nil:
+		return "network-interface", *r.NetworkInterfaceId
+	}
+	return "", ""
+}
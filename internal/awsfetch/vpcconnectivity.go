@@ -0,0 +1,406 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+// VPCPeeringConnection represents a peering connection between two VPCs.
+type VPCPeeringConnection struct {
+	VpcPeeringConnectionID string            `json:"VpcPeeringConnectionId"`
+	Status                 string            `json:"Status"`
+	RequesterVpcID         string            `json:"RequesterVpcId"`
+	RequesterOwnerID       string            `json:"RequesterOwnerId"`
+	RequesterRegion        string            `json:"RequesterRegion"`
+	RequesterCidrBlock     string            `json:"RequesterCidrBlock"`
+	AccepterVpcID          string            `json:"AccepterVpcId"`
+	AccepterOwnerID        string            `json:"AccepterOwnerId"`
+	AccepterRegion         string            `json:"AccepterRegion"`
+	AccepterCidrBlock      string            `json:"AccepterCidrBlock"`
+	Tags                   map[string]string `json:"Tags"`
+}
+
+// TransitGateway represents a Transit Gateway with its attachments and route tables.
+type TransitGateway struct {
+	TransitGatewayID  string                     `json:"TransitGatewayId"`
+	TransitGatewayArn string                     `json:"TransitGatewayArn"`
+	OwnerID           string                     `json:"OwnerId"`
+	State             string                     `json:"State"`
+	AmazonSideAsn     int64                      `json:"AmazonSideAsn"`
+	Attachments       []TransitGatewayAttachment `json:"Attachments"`
+	RouteTables       []TransitGatewayRouteTable `json:"RouteTables"`
+	Tags              map[string]string          `json:"Tags"`
+}
+
+// TransitGatewayAttachment represents a VPC, VPN, Direct Connect gateway or peering attachment.
+type TransitGatewayAttachment struct {
+	TransitGatewayAttachmentID string `json:"TransitGatewayAttachmentId"`
+	ResourceType               string `json:"ResourceType"`
+	ResourceID                 string `json:"ResourceId"`
+	ResourceOwnerID            string `json:"ResourceOwnerId"`
+	State                      string `json:"State"`
+	AssociatedRouteTableID     string `json:"AssociatedRouteTableId"`
+}
+
+// TransitGatewayRouteTable represents a Transit Gateway route table and its routes.
+type TransitGatewayRouteTable struct {
+	TransitGatewayRouteTableID   string                `json:"TransitGatewayRouteTableId"`
+	DefaultAssociationRouteTable bool                  `json:"DefaultAssociationRouteTable"`
+	DefaultPropagationRouteTable bool                  `json:"DefaultPropagationRouteTable"`
+	Routes                       []TransitGatewayRoute `json:"Routes"`
+	// RoutesTruncated is set when the table holds more routes than the
+	// search API returns, so Routes is incomplete.
+	RoutesTruncated bool `json:"RoutesTruncated"`
+}
+
+// TransitGatewayRoute represents a route in a Transit Gateway route table.
+type TransitGatewayRoute struct {
+	DestinationCidrBlock string   `json:"DestinationCidrBlock"`
+	PrefixListID         string   `json:"PrefixListId"`
+	Type                 string   `json:"Type"`
+	State                string   `json:"State"`
+	AttachmentIDs        []string `json:"AttachmentIds"`
+}
+
+// VPCEndpoint represents an interface or gateway VPC endpoint.
+type VPCEndpoint struct {
+	VpcEndpointID     string            `json:"VpcEndpointId"`
+	VpcEndpointType   string            `json:"VpcEndpointType"`
+	VpcID             string            `json:"VpcId"`
+	ServiceName       string            `json:"ServiceName"`
+	State             string            `json:"State"`
+	PolicyDocument    string            `json:"PolicyDocument"`
+	PrivateDNSEnabled bool              `json:"PrivateDnsEnabled"`
+	RouteTableIDs     []string          `json:"RouteTableIds"`
+	SubnetIDs         []string          `json:"SubnetIds"`
+	SecurityGroupIDs  []string          `json:"SecurityGroupIds"`
+	Tags              map[string]string `json:"Tags"`
+}
+
+// VPNConnection represents a Site-to-Site VPN connection.
+type VPNConnection struct {
+	VpnConnectionID   string            `json:"VpnConnectionId"`
+	State             string            `json:"State"`
+	Type              string            `json:"Type"`
+	CustomerGatewayID string            `json:"CustomerGatewayId"`
+	VpnGatewayID      string            `json:"VpnGatewayId"`
+	TransitGatewayID  string            `json:"TransitGatewayId"`
+	Tunnels           []VPNTunnel       `json:"Tunnels"`
+	Tags              map[string]string `json:"Tags"`
+}
+
+// VPNTunnel represents the status of one tunnel of a VPN connection.
+type VPNTunnel struct {
+	OutsideIPAddress string `json:"OutsideIpAddress"`
+	Status           string `json:"Status"`
+	StatusMessage    string `json:"StatusMessage"`
+}
+
+// CustomerGateway represents the customer side of a VPN connection.
+type CustomerGateway struct {
+	CustomerGatewayID string            `json:"CustomerGatewayId"`
+	BgpAsn            string            `json:"BgpAsn"`
+	IPAddress         string            `json:"IpAddress"`
+	State             string            `json:"State"`
+	Type              string            `json:"Type"`
+	Tags              map[string]string `json:"Tags"`
+}
+
+// VPNGateway represents a virtual private gateway.
+type VPNGateway struct {
+	VpnGatewayID  string            `json:"VpnGatewayId"`
+	State         string            `json:"State"`
+	AmazonSideAsn int64             `json:"AmazonSideAsn"`
+	VpcIDs        []string          `json:"VpcIds"`
+	Tags          map[string]string `json:"Tags"`
+}
+
+// EgressOnlyInternetGateway represents an IPv6 egress-only Internet gateway.
+type EgressOnlyInternetGateway struct {
+	EgressOnlyInternetGatewayID string   `json:"EgressOnlyInternetGatewayId"`
+	VpcIDs                      []string `json:"VpcIds"`
+}
+
+// FetchVPCPeeringConnections retrieves all VPC peering connections.
+func FetchVPCPeeringConnections(ctx context.Context, cfg aws.Config) ([]VPCPeeringConnection, error) {
+	client := ec2.NewFromConfig(cfg)
+	paginator := ec2.NewDescribeVpcPeeringConnectionsPaginator(client, &ec2.DescribeVpcPeeringConnectionsInput{})
+	var peerings []VPCPeeringConnection
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching VPC peering connections: %w", err)
+		}
+		for _, p := range page.VpcPeeringConnections {
+			peering := VPCPeeringConnection{
+				VpcPeeringConnectionID: aws.ToString(p.VpcPeeringConnectionId),
+				Tags:                   ec2TagsToMap(p.Tags),
+			}
+			if p.Status != nil {
+				peering.Status = string(p.Status.Code)
+			}
+			if info := p.RequesterVpcInfo; info != nil {
+				peering.RequesterVpcID = aws.ToString(info.VpcId)
+				peering.RequesterOwnerID = aws.ToString(info.OwnerId)
+				peering.RequesterRegion = aws.ToString(info.Region)
+				peering.RequesterCidrBlock = aws.ToString(info.CidrBlock)
+			}
+			if info := p.AccepterVpcInfo; info != nil {
+				peering.AccepterVpcID = aws.ToString(info.VpcId)
+				peering.AccepterOwnerID = aws.ToString(info.OwnerId)
+				peering.AccepterRegion = aws.ToString(info.Region)
+				peering.AccepterCidrBlock = aws.ToString(info.CidrBlock)
+			}
+			peerings = append(peerings, peering)
+		}
+	}
+	return peerings, nil
+}
+
+// FetchTransitGateways retrieves Transit Gateways together with their attachments and route tables.
+func FetchTransitGateways(ctx context.Context, cfg aws.Config) ([]TransitGateway, error) {
+	client := ec2.NewFromConfig(cfg)
+	var gateways []TransitGateway
+	index := make(map[string]int)
+
+	paginator := ec2.NewDescribeTransitGatewaysPaginator(client, &ec2.DescribeTransitGatewaysInput{})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Transit Gateways: %w", err)
+		}
+		for _, tgw := range page.TransitGateways {
+			gateway := TransitGateway{
+				TransitGatewayID:  aws.ToString(tgw.TransitGatewayId),
+				TransitGatewayArn: aws.ToString(tgw.TransitGatewayArn),
+				OwnerID:           aws.ToString(tgw.OwnerId),
+				State:             string(tgw.State),
+				Tags:              ec2TagsToMap(tgw.Tags),
+			}
+			if tgw.Options != nil {
+				gateway.AmazonSideAsn = aws.ToInt64(tgw.Options.AmazonSideAsn)
+			}
+			index[gateway.TransitGatewayID] = len(gateways)
+			gateways = append(gateways, gateway)
+		}
+	}
+
+	attachPaginator := ec2.NewDescribeTransitGatewayAttachmentsPaginator(client, &ec2.DescribeTransitGatewayAttachmentsInput{})
+	for attachPaginator.HasMorePages() {
+		page, err := attachPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Transit Gateway attachments: %w", err)
+		}
+		for _, a := range page.TransitGatewayAttachments {
+			i, ok := index[aws.ToString(a.TransitGatewayId)]
+			if !ok {
+				continue
+			}
+			attachment := TransitGatewayAttachment{
+				TransitGatewayAttachmentID: aws.ToString(a.TransitGatewayAttachmentId),
+				ResourceType:               string(a.ResourceType),
+				ResourceID:                 aws.ToString(a.ResourceId),
+				ResourceOwnerID:            aws.ToString(a.ResourceOwnerId),
+				State:                      string(a.State),
+			}
+			if a.Association != nil {
+				attachment.AssociatedRouteTableID = aws.ToString(a.Association.TransitGatewayRouteTableId)
+			}
+			gateways[i].Attachments = append(gateways[i].Attachments, attachment)
+		}
+	}
+
+	rtPaginator := ec2.NewDescribeTransitGatewayRouteTablesPaginator(client, &ec2.DescribeTransitGatewayRouteTablesInput{})
+	for rtPaginator.HasMorePages() {
+		page, err := rtPaginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching Transit Gateway route tables: %w", err)
+		}
+		for _, rt := range page.TransitGatewayRouteTables {
+			i, ok := index[aws.ToString(rt.TransitGatewayId)]
+			if !ok {
+				continue
+			}
+			routeTable := TransitGatewayRouteTable{
+				TransitGatewayRouteTableID:   aws.ToString(rt.TransitGatewayRouteTableId),
+				DefaultAssociationRouteTable: aws.ToBool(rt.DefaultAssociationRouteTable),
+				DefaultPropagationRouteTable: aws.ToBool(rt.DefaultPropagationRouteTable),
+			}
+			routeTable.Routes, routeTable.RoutesTruncated, err = fetchTransitGatewayRoutes(ctx, client, routeTable.TransitGatewayRouteTableID)
+			if err != nil {
+				return nil, err
+			}
+			gateways[i].RouteTables = append(gateways[i].RouteTables, routeTable)
+		}
+	}
+
+	return gateways, nil
+}
+
+// fetchTransitGatewayRoutes returns the active and blackhole routes of a
+// Transit Gateway route table. SearchTransitGatewayRoutes returns at most
+// 1000 routes and cannot be paginated, so the search is split by state and
+// route type; truncated reports whether a split still hit the limit.
+func fetchTransitGatewayRoutes(ctx context.Context, client *ec2.Client, routeTableID string) (routes []TransitGatewayRoute, truncated bool, err error) {
+	for _, state := range []string{"active", "blackhole"} {
+		for _, routeType := range []string{"static", "propagated"} {
+			out, err := client.SearchTransitGatewayRoutes(ctx, &ec2.SearchTransitGatewayRoutesInput{
+				TransitGatewayRouteTableId: &routeTableID,
+				Filters: []ec2types.Filter{
+					{Name: aws.String("state"), Values: []string{state}},
+					{Name: aws.String("type"), Values: []string{routeType}},
+				},
+			})
+			if err != nil {
+				return nil, false, fmt.Errorf("error fetching routes for Transit Gateway route table %s: %w", routeTableID, err)
+			}
+			truncated = truncated || aws.ToBool(out.AdditionalRoutesAvailable)
+			for _, r := range out.Routes {
+				route := TransitGatewayRoute{
+					DestinationCidrBlock: aws.ToString(r.DestinationCidrBlock),
+					PrefixListID:         aws.ToString(r.PrefixListId),
+					Type:                 string(r.Type),
+					State:                string(r.State),
+				}
+				for _, a := range r.TransitGatewayAttachments {
+					route.AttachmentIDs = append(route.AttachmentIDs, aws.ToString(a.TransitGatewayAttachmentId))
+				}
+				routes = append(routes, route)
+			}
+		}
+	}
+	return routes, truncated, nil
+}
+
+// FetchVPCEndpoints retrieves all interface and gateway VPC endpoints.
+func FetchVPCEndpoints(ctx context.Context, cfg aws.Config) ([]VPCEndpoint, error) {
+	client := ec2.NewFromConfig(cfg)
+	paginator := ec2.NewDescribeVpcEndpointsPaginator(client, &ec2.DescribeVpcEndpointsInput{})
+	var endpoints []VPCEndpoint
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching VPC endpoints: %w", err)
+		}
+		for _, e := range page.VpcEndpoints {
+			endpoint := VPCEndpoint{
+				VpcEndpointID:     aws.ToString(e.VpcEndpointId),
+				VpcEndpointType:   string(e.VpcEndpointType),
+				VpcID:             aws.ToString(e.VpcId),
+				ServiceName:       aws.ToString(e.ServiceName),
+				State:             string(e.State),
+				PolicyDocument:    aws.ToString(e.PolicyDocument),
+				PrivateDNSEnabled: aws.ToBool(e.PrivateDnsEnabled),
+				RouteTableIDs:     e.RouteTableIds,
+				SubnetIDs:         e.SubnetIds,
+				Tags:              ec2TagsToMap(e.Tags),
+			}
+			for _, g := range e.Groups {
+				endpoint.SecurityGroupIDs = append(endpoint.SecurityGroupIDs, aws.ToString(g.GroupId))
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	}
+	return endpoints, nil
+}
+
+// FetchVPNData retrieves Site-to-Site VPN connections, customer gateways and virtual private gateways.
+func FetchVPNData(ctx context.Context, cfg aws.Config) ([]VPNConnection, []CustomerGateway, []VPNGateway, error) {
+	client := ec2.NewFromConfig(cfg)
+
+	// Fetch VPN connections
+	vpnOut, err := client.DescribeVpnConnections(ctx, &ec2.DescribeVpnConnectionsInput{})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error fetching VPN connections: %w", err)
+	}
+	var connections []VPNConnection
+	for _, v := range vpnOut.VpnConnections {
+		conn := VPNConnection{
+			VpnConnectionID:   aws.ToString(v.VpnConnectionId),
+			State:             string(v.State),
+			Type:              string(v.Type),
+			CustomerGatewayID: aws.ToString(v.CustomerGatewayId),
+			VpnGatewayID:      aws.ToString(v.VpnGatewayId),
+			TransitGatewayID:  aws.ToString(v.TransitGatewayId),
+			Tags:              ec2TagsToMap(v.Tags),
+		}
+		for _, t := range v.VgwTelemetry {
+			conn.Tunnels = append(conn.Tunnels, VPNTunnel{
+				OutsideIPAddress: aws.ToString(t.OutsideIpAddress),
+				Status:           string(t.Status),
+				StatusMessage:    aws.ToString(t.StatusMessage),
+			})
+		}
+		connections = append(connections, conn)
+	}
+
+	// Fetch customer gateways
+	cgwOut, err := client.DescribeCustomerGateways(ctx, &ec2.DescribeCustomerGatewaysInput{})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error fetching customer gateways: %w", err)
+	}
+	var customerGateways []CustomerGateway
+	for _, c := range cgwOut.CustomerGateways {
+		customerGateways = append(customerGateways, CustomerGateway{
+			CustomerGatewayID: aws.ToString(c.CustomerGatewayId),
+			BgpAsn:            aws.ToString(c.BgpAsn),
+			IPAddress:         aws.ToString(c.IpAddress),
+			State:             aws.ToString(c.State),
+			Type:              aws.ToString(c.Type),
+			Tags:              ec2TagsToMap(c.Tags),
+		})
+	}
+
+	// Fetch virtual private gateways
+	vgwOut, err := client.DescribeVpnGateways(ctx, &ec2.DescribeVpnGatewaysInput{})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error fetching virtual private gateways: %w", err)
+	}
+	var vpnGateways []VPNGateway
+	for _, g := range vgwOut.VpnGateways {
+		gateway := VPNGateway{
+			VpnGatewayID:  aws.ToString(g.VpnGatewayId),
+			State:         string(g.State),
+			AmazonSideAsn: aws.ToInt64(g.AmazonSideAsn),
+			Tags:          ec2TagsToMap(g.Tags),
+		}
+		for _, a := range g.VpcAttachments {
+			if a.State == ec2types.AttachmentStatusAttached && a.VpcId != nil {
+				gateway.VpcIDs = append(gateway.VpcIDs, *a.VpcId)
+			}
+		}
+		vpnGateways = append(vpnGateways, gateway)
+	}
+
+	return connections, customerGateways, vpnGateways, nil
+}
+
+// FetchEgressOnlyInternetGateways retrieves all egress-only Internet gateways.
+func FetchEgressOnlyInternetGateways(ctx context.Context, cfg aws.Config) ([]EgressOnlyInternetGateway, error) {
+	client := ec2.NewFromConfig(cfg)
+	paginator := ec2.NewDescribeEgressOnlyInternetGatewaysPaginator(client, &ec2.DescribeEgressOnlyInternetGatewaysInput{})
+	var gateways []EgressOnlyInternetGateway
+
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching egress-only Internet gateways: %w", err)
+		}
+		for _, g := range page.EgressOnlyInternetGateways {
+			gateway := EgressOnlyInternetGateway{EgressOnlyInternetGatewayID: aws.ToString(g.EgressOnlyInternetGatewayId)}
+			for _, a := range g.Attachments {
+				if a.VpcId != nil {
+					gateway.VpcIDs = append(gateway.VpcIDs, *a.VpcId)
+				}
+			}
+			gateways = append(gateways, gateway)
+		}
+	}
+	return gateways, nil
+}
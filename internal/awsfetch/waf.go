@@ -0,0 +1,234 @@
+package awsfetch
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudfront"
+	"github.com/aws/aws-sdk-go-v2/service/wafv2"
+	wafv2types "github.com/aws/aws-sdk-go-v2/service/wafv2/types"
+)
+
+// cloudFrontRegion is the region that serves CloudFront-scoped WAF and Shield APIs.
+const cloudFrontRegion = "us-east-1"
+
+// WAFWebACL represents a WAFv2 web ACL and the resources it protects.
+type WAFWebACL struct {
+	Name                      string    `json:"Name"`
+	ID                        string    `json:"Id"`
+	ARN                       string    `json:"ARN"`
+	Scope                     string    `json:"Scope"` // REGIONAL or CLOUDFRONT.
+	DefaultAction             string    `json:"DefaultAction"`
+	Capacity                  int64     `json:"Capacity"`
+	ManagedByFirewallManager  bool      `json:"ManagedByFirewallManager"`
+	Rules                     []WAFRule `json:"Rules"`
+	ManagedRuleGroups         []string  `json:"ManagedRuleGroups"` // "Vendor/Name", with "@Version" when pinned.
+	LoadBalancerArns          []string  `json:"LoadBalancerArns"`
+	APIGatewayStageArns       []string  `json:"ApiGatewayStageArns"`
+	CognitoUserPoolArns       []string  `json:"CognitoUserPoolArns"`
+	CloudFrontDistributionIDs []string  `json:"CloudFrontDistributionIds"`
+}
+
+// WAFRule represents a rule of a web ACL.
+type WAFRule struct {
+	Name             string `json:"Name"`
+	Priority         int32  `json:"Priority"`
+	Type             string `json:"Type"`
+	Action           string `json:"Action"`
+	ManagedRuleGroup string `json:"ManagedRuleGroup"`
+	RuleGroupArn     string `json:"RuleGroupArn"`
+}
+
+// wafAssociatedResourceTypes are the regional resource types whose web ACL
+// associations are collected.
+var wafAssociatedResourceTypes = []wafv2types.ResourceType{
+	wafv2types.ResourceTypeApplicationLoadBalancer,
+	wafv2types.ResourceTypeApiGateway,
+	wafv2types.ResourceTypeCognitioUserPool,
+}
+
+// FetchWAFWebACLs retrieves regional web ACLs together with the resources
+// they protect. With global set it also retrieves CloudFront web ACLs, which
+// are the same from every region, outside isolated partitions.
+func FetchWAFWebACLs(ctx context.Context, cfg aws.Config, global bool) ([]WAFWebACL, error) {
+	client := wafv2.NewFromConfig(cfg)
+	acls, err := fetchWebACLs(ctx, client, wafv2types.ScopeRegional)
+	if err != nil {
+		return nil, err
+	}
+	for i := range acls {
+		if err := fetchRegionalWebACLResources(ctx, client, &acls[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	if !global || !supportsCloudFront(cfg.Region) {
+		return acls, nil
+	}
+	globalCfg := cfg.Copy()
+	globalCfg.Region = cloudFrontRegion
+	globalACLs, err := fetchWebACLs(ctx, wafv2.NewFromConfig(globalCfg), wafv2types.ScopeCloudfront)
+	if err != nil {
+		return nil, err
+	}
+	cfClient := cloudfront.NewFromConfig(globalCfg)
+	for i := range globalACLs {
+		globalACLs[i].CloudFrontDistributionIDs, err = fetchWebACLDistributions(ctx, cfClient, globalACLs[i].ARN)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return append(acls, globalACLs...), nil
+}
+
+func fetchWebACLs(ctx context.Context, client *wafv2.Client, scope wafv2types.Scope) ([]WAFWebACL, error) {
+	var acls []WAFWebACL
+	input := &wafv2.ListWebACLsInput{Scope: scope}
+	for {
+		out, err := client.ListWebACLs(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing %s web ACLs: %w", scope, err)
+		}
+		for _, summary := range out.WebACLs {
+			detail, err := client.GetWebACL(ctx, &wafv2.GetWebACLInput{Id: summary.Id, Name: summary.Name, Scope: scope})
+			if err != nil {
+				return nil, fmt.Errorf("error fetching web ACL %s: %w", aws.ToString(summary.Name), err)
+			}
+			w := detail.WebACL
+			acl := WAFWebACL{
+				Name:                     aws.ToString(w.Name),
+				ID:                       aws.ToString(w.Id),
+				ARN:                      aws.ToString(w.ARN),
+				Scope:                    string(scope),
+				Capacity:                 w.Capacity,
+				ManagedByFirewallManager: w.ManagedByFirewallManager,
+			}
+			if w.DefaultAction != nil {
+				acl.DefaultAction = "ALLOW"
+				if w.DefaultAction.Block != nil {
+					acl.DefaultAction = "BLOCK"
+				}
+			}
+			for _, r := range w.Rules {
+				rule := wafRule(r)
+				if rule.ManagedRuleGroup != "" {
+					acl.ManagedRuleGroups = append(acl.ManagedRuleGroups, rule.ManagedRuleGroup)
+				}
+				acl.Rules = append(acl.Rules, rule)
+			}
+			acls = append(acls, acl)
+		}
+		if out.NextMarker == nil || len(out.WebACLs) == 0 {
+			break
+		}
+		input.NextMarker = out.NextMarker
+	}
+	return acls, nil
+}
+
+func wafRule(r wafv2types.Rule) WAFRule {
+	rule := WAFRule{Name: aws.ToString(r.Name), Priority: r.Priority, Type: "custom"}
+	if s := r.Statement; s != nil {
+		switch {
+		case s.ManagedRuleGroupStatement != nil:
+			m := s.ManagedRuleGroupStatement
+			rule.Type = "managed-rule-group"
+			rule.ManagedRuleGroup = aws.ToString(m.VendorName) + "/" + aws.ToString(m.Name)
+			if m.Version != nil {
+				rule.ManagedRuleGroup += "@" + *m.Version
+			}
+		case s.RuleGroupReferenceStatement != nil:
+			rule.Type = "rule-group"
+			rule.RuleGroupArn = aws.ToString(s.RuleGroupReferenceStatement.ARN)
+		case s.RateBasedStatement != nil:
+			rule.Type = "rate-based"
+		case s.IPSetReferenceStatement != nil:
+			rule.Type = "ip-set"
+		case s.GeoMatchStatement != nil:
+			rule.Type = "geo-match"
+		}
+	}
+	// Rule groups carry an override action instead of an action.
+	switch {
+	case r.Action != nil && r.Action.Allow != nil:
+		rule.Action = "ALLOW"
+	case r.Action != nil && r.Action.Block != nil:
+		rule.Action = "BLOCK"
+	case r.Action != nil && r.Action.Count != nil:
+		rule.Action = "COUNT"
+	case r.Action != nil && r.Action.Captcha != nil:
+		rule.Action = "CAPTCHA"
+	case r.Action != nil && r.Action.Challenge != nil:
+		rule.Action = "CHALLENGE"
+	case r.OverrideAction != nil && r.OverrideAction.Count != nil:
+		rule.Action = "OVERRIDE_COUNT"
+	case r.OverrideAction != nil:
+		rule.Action = "NONE"
+	}
+	return rule
+}
+
+func fetchRegionalWebACLResources(ctx context.Context, client *wafv2.Client, acl *WAFWebACL) error {
+	for _, resourceType := range wafAssociatedResourceTypes {
+		out, err := client.ListResourcesForWebACL(ctx, &wafv2.ListResourcesForWebACLInput{
+			WebACLArn:    aws.String(acl.ARN),
+			ResourceType: resourceType,
+		})
+		if err != nil {
+			return fmt.Errorf("error listing %s resources for web ACL %s: %w", resourceType, acl.Name, err)
+		}
+		switch resourceType {
+		case wafv2types.ResourceTypeApplicationLoadBalancer:
+			acl.LoadBalancerArns = out.ResourceArns
+		case wafv2types.ResourceTypeApiGateway:
+			acl.APIGatewayStageArns = out.ResourceArns
+		case wafv2types.ResourceTypeCognitioUserPool:
+			acl.CognitoUserPoolArns = out.ResourceArns
+		}
+	}
+	return nil
+}
+
+func fetchWebACLDistributions(ctx context.Context, client *cloudfront.Client, webACLArn string) ([]string, error) {
+	var ids []string
+	input := &cloudfront.ListDistributionsByWebACLIdInput{WebACLId: &webACLArn}
+	for {
+		out, err := client.ListDistributionsByWebACLId(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("error listing CloudFront distributions for web ACL %s: %w", webACLArn, err)
+		}
+		if out.DistributionList == nil {
+			break
+		}
+		for _, d := range out.DistributionList.Items {
+			ids = append(ids, aws.ToString(d.Id))
+		}
+		if !aws.ToBool(out.DistributionList.IsTruncated) {
+			break
+		}
+		input.Marker = out.DistributionList.NextMarker
+	}
+	return ids, nil
+}
+
+// supportsCloudFront reports whether CloudFront is available in the partition of region.
+func supportsCloudFront(region string) bool {
+	return !strings.HasPrefix(region, "cn-") && !strings.HasPrefix(region, "us-gov-") && !strings.HasPrefix(region, "us-iso")
+}
+
+// LinkLoadBalancerWebACLs records on each load balancer the regional web ACL associated with it.
+func LinkLoadBalancerWebACLs(lbs []LoadBalancer, acls []WAFWebACL) {
+	byArn := make(map[string]string)
+	for _, acl := range acls {
+		for _, arn := range acl.LoadBalancerArns {
+			byArn[arn] = acl.ARN
+		}
+	}
+	for i := range lbs {
+		if lbs[i].LoadBalancerArn != "" {
+			lbs[i].WebACLArn = byArn[lbs[i].LoadBalancerArn]
+		}
+	}
+}
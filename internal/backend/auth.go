@@ -0,0 +1,114 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenRefreshMargin is how long before expiry an exchanged token is renewed.
+const tokenRefreshMargin = time.Minute
+
+// defaultTokenLifetime is assumed for exchanged tokens whose response gives no
+// expires_in. A token the backend revokes earlier is renewed on its 401.
+const defaultTokenLifetime = 15 * time.Minute
+
+// TokenSource supplies bearer tokens for backend requests.
+type TokenSource interface {
+	// Token returns a valid bearer token.
+	Token(ctx context.Context) (string, error)
+	// Invalidate discards a token the backend rejected and reports whether
+	// the next call to Token may return a different one.
+	Invalidate() bool
+}
+
+// StaticToken is a TokenSource for a fixed bearer token.
+type StaticToken string
+
+// Token returns the static token.
+func (t StaticToken) Token(context.Context) (string, error) { return string(t), nil }
+
+// Invalidate reports false; a static token cannot be renewed.
+func (t StaticToken) Invalidate() bool { return false }
+
+// ExchangeTokenSource obtains bearer tokens from a token-exchange endpoint
+// using the OAuth 2.0 client credentials grant, caching each token until
+// shortly before it expires.
+type ExchangeTokenSource struct {
+	URL          string
+	ClientID     string
+	ClientSecret string
+	HTTPClient   *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
+// Token returns the cached token, exchanging the client credentials for a new
+// one when it is missing or about to expire.
+func (s *ExchangeTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" && time.Now().Add(tokenRefreshMargin).Before(s.expires) {
+		return s.token, nil
+	}
+
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {s.ClientID},
+		"client_secret": {s.ClientSecret},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange token: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token endpoint returned status %d", resp.StatusCode)
+	}
+
+	var tr tokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tr); err != nil {
+		return "", fmt.Errorf("failed to decode token response: %w", err)
+	}
+	if tr.AccessToken == "" {
+		return "", fmt.Errorf("token endpoint returned no access token")
+	}
+	lifetime := time.Duration(tr.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = defaultTokenLifetime
+	}
+	s.token = tr.AccessToken
+	s.expires = time.Now().Add(lifetime)
+	return s.token, nil
+}
+
+// Invalidate discards the cached token so the next call exchanges a new one.
+func (s *ExchangeTokenSource) Invalidate() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.token = ""
+	return true
+}
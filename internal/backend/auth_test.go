@@ -0,0 +1,124 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+)
+
+// newTokenEndpoint serves client-credentials exchanges, issuing "token-N" for
+// the Nth exchange with the given expires_in.
+func newTokenEndpoint(t *testing.T, expiresIn int64) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var exchanges atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("failed to parse token request: %v", err)
+		}
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_id") != "id" || r.Form.Get("client_secret") != "secret" {
+			http.Error(w, "invalid client", http.StatusUnauthorized)
+			return
+		}
+		n := exchanges.Add(1)
+		json.NewEncoder(w).Encode(tokenResponse{
+			AccessToken: "token-" + strconv.Itoa(int(n)),
+			TokenType:   "Bearer",
+			ExpiresIn:   expiresIn,
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &exchanges
+}
+
+func TestExchangeTokenSource(t *testing.T) {
+	srv, exchanges := newTokenEndpoint(t, 3600)
+	src := &ExchangeTokenSource{URL: srv.URL, ClientID: "id", ClientSecret: "secret", HTTPClient: srv.Client()}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		token, err := src.Token(ctx)
+		if err != nil {
+			t.Fatalf("Token() = %v", err)
+		}
+		if token != "token-1" {
+			t.Errorf("Token() = %q, want the cached token-1", token)
+		}
+	}
+	if !src.Invalidate() {
+		t.Error("Invalidate() = false, want true")
+	}
+	if token, err := src.Token(ctx); err != nil || token != "token-2" {
+		t.Errorf("Token() after Invalidate = %q, %v, want token-2", token, err)
+	}
+	if n := exchanges.Load(); n != 2 {
+		t.Errorf("got %d exchanges, want 2", n)
+	}
+}
+
+func TestExchangeTokenSourceWithoutExpiry(t *testing.T) {
+	srv, exchanges := newTokenEndpoint(t, 0)
+	src := &ExchangeTokenSource{URL: srv.URL, ClientID: "id", ClientSecret: "secret", HTTPClient: srv.Client()}
+
+	for i := 0; i < 3; i++ {
+		if _, err := src.Token(context.Background()); err != nil {
+			t.Fatalf("Token() = %v", err)
+		}
+	}
+	if n := exchanges.Load(); n != 1 {
+		t.Errorf("got %d exchanges, want 1: a token without expires_in should be cached", n)
+	}
+}
+
+func TestExchangeTokenSourceRejected(t *testing.T) {
+	srv, _ := newTokenEndpoint(t, 3600)
+	src := &ExchangeTokenSource{URL: srv.URL, ClientID: "id", ClientSecret: "wrong", HTTPClient: srv.Client()}
+	if _, err := src.Token(context.Background()); err == nil {
+		t.Error("Token() with bad credentials = nil error, want an error")
+	}
+}
+
+func TestSendRetriesUnauthorizedOnlyWithRenewableToken(t *testing.T) {
+	tokenSrv, _ := newTokenEndpoint(t, 3600)
+
+	tests := []struct {
+		name         string
+		tokens       TokenSource
+		wantRequests int32
+		wantErr      bool
+	}{
+		// The backend accepts only the second exchanged token.
+		{"exchanged token", &ExchangeTokenSource{URL: tokenSrv.URL, ClientID: "id", ClientSecret: "secret", HTTPClient: tokenSrv.Client()}, 2, false},
+		{"static token", StaticToken("token-1"), 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				if r.Header.Get("Authorization") != "Bearer token-2" {
+					w.WriteHeader(http.StatusUnauthorized)
+				}
+			}))
+			defer srv.Close()
+
+			c := &Client{httpClient: srv.Client(), tokens: tt.tokens, encoding: EncodingIdentity}
+			_, err := c.send(context.Background(), http.MethodPost, srv.URL, "", []byte(`{}`))
+
+			var statusErr *StatusError
+			if tt.wantErr && (!errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusUnauthorized) {
+				t.Errorf("send() = %v, want status 401", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("send() = %v, want nil", err)
+			}
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("backend got %d requests, want %d", n, tt.wantRequests)
+			}
+		})
+	}
+}
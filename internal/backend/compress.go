@@ -0,0 +1,90 @@
+package backend
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Supported request body encodings. The empty string sends bodies uncompressed.
+const (
+	EncodingIdentity = ""
+	EncodingGzip     = "gzip"
+	EncodingZstd     = "zstd"
+)
+
+// maxDecompressedSize bounds Decompress so a small body cannot expand without limit.
+const maxDecompressedSize = 1 << 30
+
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil, zstd.WithDecoderMaxMemory(maxDecompressedSize))
+)
+
+// compress encodes data with encoding.
+func compress(encoding string, data []byte) ([]byte, error) {
+	switch encoding {
+	case EncodingIdentity:
+		return data, nil
+	case EncodingGzip:
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		if _, err := w.Write(data); err != nil {
+			return nil, fmt.Errorf("failed to gzip payload: %w", err)
+		}
+		if err := w.Close(); err != nil {
+			return nil, fmt.Errorf("failed to gzip payload: %w", err)
+		}
+		return buf.Bytes(), nil
+	case EncodingZstd:
+		return zstdEncoder.EncodeAll(data, make([]byte, 0, len(data)/8)), nil
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// Decompress decodes a request body sent with the given Content-Encoding.
+func Decompress(encoding string, data []byte) ([]byte, error) {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "", "identity":
+		return data, nil
+	case EncodingGzip:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		defer r.Close()
+		out, err := io.ReadAll(io.LimitReader(r, maxDecompressedSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > maxDecompressedSize {
+			return nil, fmt.Errorf("decompressed body exceeds %d bytes", maxDecompressedSize)
+		}
+		return out, nil
+	case EncodingZstd:
+		return zstdDecoder.DecodeAll(data, nil)
+	}
+	return nil, fmt.Errorf("unsupported content encoding %q", encoding)
+}
+
+// fallbackEncoding picks the encoding to use after the backend rejected a
+// request with 415: one the backend lists in its Accept-Encoding response
+// header (RFC 7694) and that has not been tried, preferring zstd, or no
+// compression.
+func fallbackEncoding(acceptEncoding string, tried map[string]bool) string {
+	accepted := make(map[string]bool)
+	for _, e := range strings.Split(acceptEncoding, ",") {
+		name, _, _ := strings.Cut(e, ";")
+		accepted[strings.ToLower(strings.TrimSpace(name))] = true
+	}
+	for _, e := range []string{EncodingZstd, EncodingGzip} {
+		if !tried[e] && accepted[e] {
+			return e
+		}
+	}
+	return EncodingIdentity
+}
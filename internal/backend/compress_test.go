@@ -0,0 +1,67 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSendFallsBackToEachEncodingOnce(t *testing.T) {
+	// The backend rejects every compressed body while advertising the
+	// encoding it did not just receive.
+	var got []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		got = append(got, encoding)
+		switch encoding {
+		case EncodingZstd:
+			w.Header().Set("Accept-Encoding", EncodingGzip)
+		case EncodingGzip:
+			w.Header().Set("Accept-Encoding", EncodingZstd)
+		default:
+			return
+		}
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	}))
+	defer srv.Close()
+
+	c := &Client{httpClient: srv.Client(), encoding: EncodingZstd}
+	if _, err := c.send(context.Background(), http.MethodPost, srv.URL, "", []byte(`{}`)); err != nil {
+		t.Fatalf("send() = %v, want nil", err)
+	}
+	want := []string{EncodingZstd, EncodingGzip, ""}
+	if len(got) != len(want) {
+		t.Fatalf("backend got encodings %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("backend got encodings %q, want %q", got, want)
+		}
+	}
+	if e := c.contentEncoding(); e != EncodingIdentity {
+		t.Errorf("later requests use %q, want %q", e, EncodingIdentity)
+	}
+}
+
+func TestFallbackEncoding(t *testing.T) {
+	tests := []struct {
+		accept string
+		tried  []string
+		want   string
+	}{
+		{"gzip, zstd", []string{EncodingZstd}, EncodingGzip},
+		{"zstd;q=1, gzip;q=0.5", []string{EncodingGzip}, EncodingZstd},
+		{"zstd", []string{EncodingZstd, EncodingGzip}, EncodingIdentity},
+		{"", []string{EncodingGzip}, EncodingIdentity},
+	}
+	for _, tt := range tests {
+		tried := make(map[string]bool)
+		for _, e := range tt.tried {
+			tried[e] = true
+		}
+		if got := fallbackEncoding(tt.accept, tried); got != tt.want {
+			t.Errorf("fallbackEncoding(%q, %v) = %q, want %q", tt.accept, tt.tried, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// HeaderIdempotencyKey carries the crawl ID so the backend can drop
+// deliveries it has already accepted.
+const HeaderIdempotencyKey = "Idempotency-Key"
+
+// RetryPolicy controls how failed deliveries are retried.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxWait bounds the total time spent waiting between the attempts of one
+	// delivery. A Retry-After that would exceed it ends the delivery instead.
+	MaxWait time.Duration
+}
+
+// DefaultRetryPolicy is used when no policy is configured.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    5,
+	InitialBackoff: time.Second,
+	MaxBackoff:     30 * time.Second,
+	MaxWait:        5 * time.Minute,
+}
+
+// StatusError is returned when the backend answers with a non-2xx status.
+type StatusError struct {
+	StatusCode int
+	RetryAfter time.Duration // Zero when the response had no Retry-After header.
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("backend returned status %d", e.StatusCode)
+}
+
+// newStatusError builds a StatusError from resp, parsing Retry-After as
+// either delay seconds or an HTTP date.
+func newStatusError(resp *http.Response) *StatusError {
+	e := &StatusError{StatusCode: resp.StatusCode}
+	if v := resp.Header.Get("Retry-After"); v != "" {
+		if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+			e.RetryAfter = time.Duration(secs) * time.Second
+		} else if t, err := http.ParseTime(v); err == nil {
+			e.RetryAfter = max(time.Until(t), 0)
+		}
+	}
+	return e
+}
+
+// IsRetryable reports whether a delivery that failed with err may succeed
+// when repeated: timeouts, refused or reset connections, temporary DNS
+// failures, 408, 429 and 5xx other than 501. Everything else is permanent,
+// including certificate and TLS handshake failures, unknown hosts, malformed
+// requests and cancellation of the caller's context.
+func IsRetryable(err error) bool {
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		switch code := statusErr.StatusCode; {
+		case code == http.StatusRequestTimeout, code == http.StatusTooManyRequests:
+			return true
+		case code == http.StatusNotImplemented:
+			return false
+		default:
+			return code >= 500
+		}
+	}
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	var (
+		certErr      *tls.CertificateVerificationError
+		unknownCAErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+		recordErr    tls.RecordHeaderError
+		opErr        *net.OpError
+		dnsErr       *net.DNSError
+		netErr       net.Error
+	)
+	switch {
+	case errors.As(err, &certErr), errors.As(err, &unknownCAErr), errors.As(err, &hostnameErr),
+		errors.As(err, &invalidErr), errors.As(err, &recordErr):
+		return false
+	case errors.As(err, &opErr) && opErr.Op == "remote error":
+		// A TLS alert from the peer, such as a rejected client certificate.
+		return false
+	case errors.As(err, &dnsErr):
+		return dnsErr.IsTimeout || dnsErr.IsTemporary
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return true
+	}
+	for _, transient := range []error{
+		syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED, syscall.EPIPE,
+		syscall.ENETUNREACH, syscall.EHOSTUNREACH, io.EOF, io.ErrUnexpectedEOF,
+	} {
+		if errors.Is(err, transient) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before retry number attempt (starting at 1), using
+// exponential backoff with full jitter. A Retry-After from the backend is
+// honoured in full when it asks for a longer wait.
+func (p RetryPolicy) backoff(attempt int, err error) time.Duration {
+	ceiling := p.MaxBackoff
+	if exp := p.InitialBackoff << (attempt - 1); exp > 0 && exp < ceiling {
+		ceiling = exp
+	}
+	wait := time.Duration(rand.Int64N(int64(ceiling) + 1))
+
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.RetryAfter > wait {
+		wait = statusErr.RetryAfter
+	}
+	return wait
+}
+
+// do runs send until it succeeds, fails permanently, exhausts the policy's
+// attempts or wait budget, or would outlive ctx.
+func (p RetryPolicy) do(ctx context.Context, send func(context.Context) error) error {
+	var err error
+	var waited time.Duration
+	for attempt := 1; ; attempt++ {
+		if err = send(ctx); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !IsRetryable(err) {
+			return fmt.Errorf("delivery failed permanently: %w", err)
+		}
+		if attempt >= p.MaxAttempts {
+			return fmt.Errorf("delivery failed after %d attempts: %w", attempt, err)
+		}
+		wait := p.backoff(attempt, err)
+		if p.MaxWait > 0 && waited+wait > p.MaxWait {
+			return fmt.Errorf("delivery failed after %d attempts, waiting %v more would exceed the retry budget: %w", attempt, wait.Round(time.Second), err)
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("delivery failed after %d attempts, no time left to retry: %w", attempt, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("delivery failed after %d attempts: %w", attempt, ctx.Err())
+		case <-time.After(wait):
+		}
+		waited += wait
+	}
+}
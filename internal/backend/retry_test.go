@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestBackoffHonoursRetryAfter(t *testing.T) {
+	p := DefaultRetryPolicy
+	err := &StatusError{StatusCode: 429, RetryAfter: 2 * p.MaxBackoff}
+	if got := p.backoff(1, err); got != err.RetryAfter {
+		t.Errorf("backoff() = %v, want the full Retry-After %v", got, err.RetryAfter)
+	}
+	if got := p.backoff(10, &StatusError{StatusCode: 503}); got > p.MaxBackoff {
+		t.Errorf("backoff() = %v, want at most %v", got, p.MaxBackoff)
+	}
+}
+
+func TestDoStopsWhenRetryAfterExceedsBudget(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxWait: time.Minute}
+	attempts := 0
+	start := time.Now()
+	err := p.do(context.Background(), func(context.Context) error {
+		attempts++
+		return &StatusError{StatusCode: 429, RetryAfter: time.Hour}
+	})
+	if err == nil || !strings.Contains(err.Error(), "retry budget") {
+		t.Errorf("do() = %v, want a retry budget error", err)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("do() waited %v before giving up", elapsed)
+	}
+}
+
+func TestDoStopsWhenRetryAfterExceedsDeadline(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 5, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	attempts := 0
+	err := p.do(ctx, func(context.Context) error {
+		attempts++
+		return &StatusError{StatusCode: 503, RetryAfter: time.Hour}
+	})
+	if err == nil || !strings.Contains(err.Error(), "no time left") {
+		t.Errorf("do() = %v, want a deadline error", err)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	// Real transport failures, as http.Client.Do reports them.
+	tlsSrv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	tlsSrv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	tlsSrv.StartTLS()
+	defer tlsSrv.Close()
+	_, untrustedErr := http.Get(tlsSrv.URL)
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedURL := "http://" + closed.Addr().String()
+	closed.Close()
+	_, refusedErr := http.Get(closedURL)
+
+	_, schemeErr := http.Get("ftp://backend.example/")
+
+	urlErr := func(err error) error { return &url.Error{Op: "Post", URL: "https://backend.example", Err: err} }
+	dial := func(err error) error {
+		return urlErr(&net.OpError{Op: "dial", Net: "tcp", Err: &os.SyscallError{Syscall: "connect", Err: err}})
+	}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"408", &StatusError{StatusCode: 408}, true},
+		{"429", &StatusError{StatusCode: 429}, true},
+		{"500", &StatusError{StatusCode: 500}, true},
+		{"503", &StatusError{StatusCode: 503}, true},
+		{"501", &StatusError{StatusCode: 501}, false},
+		{"400", &StatusError{StatusCode: 400}, false},
+		{"413", &StatusError{StatusCode: 413}, false},
+		{"422", &StatusError{StatusCode: 422}, false},
+		{"wrapped 503", fmt.Errorf("upload failed: %w", &StatusError{StatusCode: 503}), true},
+		{"connection refused", refusedErr, true},
+		{"connection reset", dial(syscall.ECONNRESET), true},
+		{"network unreachable", dial(syscall.ENETUNREACH), true},
+		{"server closed the connection", urlErr(io.EOF), true},
+		{"timeout", urlErr(context.DeadlineExceeded), true},
+		{"DNS timeout", urlErr(&net.DNSError{Err: "i/o timeout", Name: "backend.example", IsTimeout: true}), true},
+		{"temporary DNS failure", urlErr(&net.DNSError{Err: "server misbehaving", Name: "backend.example", IsTemporary: true}), true},
+		{"unknown host", urlErr(&net.DNSError{Err: "no such host", Name: "backend.example", IsNotFound: true}), false},
+		{"untrusted certificate", untrustedErr, false},
+		{"wrong host name", urlErr(x509.HostnameError{Host: "backend.example", Certificate: &x509.Certificate{}}), false},
+		{"client certificate rejected", urlErr(&net.OpError{Op: "remote error", Err: errors.New("tls: certificate required")}), false},
+		{"not TLS", urlErr(tls.RecordHeaderError{Msg: "first record does not look like a TLS handshake"}), false},
+		{"unsupported scheme", schemeErr, false},
+		{"canceled", urlErr(context.Canceled), false},
+		{"other", errors.New("failed to encode payload"), false},
+	}
+	for _, tt := range tests {
+		if tt.err == nil {
+			t.Fatalf("%s: no error to classify", tt.name)
+		}
+		if got := IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable(%v) = %v, want %v", tt.name, tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package backend
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSignedRequest(t *testing.T, body string, secret []byte) *http.Request {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, "https://backend.example/v1/crawls?part=1", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set(HeaderAgentID, "agent-1")
+	if err := SignRequest(req, []byte(body), secret); err != nil {
+		t.Fatal(err)
+	}
+	return req
+}
+
+func TestSignVerifyRoundTrip(t *testing.T) {
+	secret := []byte("s3cret")
+	body := `{"crawl":1}`
+	req := newSignedRequest(t, body, secret)
+	nonces := NewNonceCache()
+
+	if err := VerifyRequest(req, []byte(body), secret, nonces); err != nil {
+		t.Fatalf("VerifyRequest() = %v, want nil", err)
+	}
+	if err := VerifyRequest(req, []byte(body), secret, nonces); err == nil {
+		t.Error("VerifyRequest() accepted a replayed request")
+	}
+}
+
+func TestVerifyRejectsTampering(t *testing.T) {
+	secret := []byte("s3cret")
+	body := `{"crawl":1}`
+
+	tests := []struct {
+		name   string
+		tamper func(req *http.Request) (body []byte, secret []byte)
+	}{
+		{"body", func(*http.Request) ([]byte, []byte) { return []byte(`{"crawl":2}`), secret }},
+		{"secret", func(*http.Request) ([]byte, []byte) { return []byte(body), []byte("other") }},
+		{"agent ID", func(req *http.Request) ([]byte, []byte) {
+			req.Header.Set(HeaderAgentID, "agent-2")
+			return []byte(body), secret
+		}},
+		{"path", func(req *http.Request) ([]byte, []byte) {
+			req.URL.Path = "/v1/other"
+			return []byte(body), secret
+		}},
+		{"missing signature", func(req *http.Request) ([]byte, []byte) {
+			req.Header.Del(HeaderSignature)
+			return []byte(body), secret
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := newSignedRequest(t, body, secret)
+			gotBody, gotSecret := tt.tamper(req)
+			if err := VerifyRequest(req, gotBody, gotSecret, NewNonceCache()); err == nil {
+				t.Error("VerifyRequest() = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsClockSkew(t *testing.T) {
+	secret := []byte("s3cret")
+	body := []byte(`{}`)
+
+	for _, offset := range []time.Duration{-MaxClockSkew - time.Minute, MaxClockSkew + time.Minute} {
+		req := newSignedRequest(t, string(body), secret)
+		// Re-sign with the shifted timestamp so only the skew is wrong.
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(time.Now().Add(offset).Unix(), 10))
+		req.Header.Set(HeaderSignature, signaturePrefix+computeSignature(req, body, secret))
+
+		err := VerifyRequest(req, body, secret, NewNonceCache())
+		if err == nil || !strings.Contains(err.Error(), "clock skew") {
+			t.Errorf("VerifyRequest() with timestamp offset %v = %v, want a clock skew error", offset, err)
+		}
+	}
+}
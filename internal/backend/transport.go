@@ -0,0 +1,130 @@
+package backend
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/config"
+)
+
+// newTransport returns the HTTP transport for backend requests, with the
+// client certificate, extra CAs, minimum TLS version and proxy of cfg.
+func newTransport(cfg *config.Config) (*http.Transport, error) {
+	tlsConfig := &tls.Config{MinVersion: cfg.BackendTLSMinVersion}
+	if cfg.BackendTLSCertFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.BackendTLSCertFile, cfg.BackendTLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	if cfg.BackendCAFile != "" {
+		pem, err := os.ReadFile(cfg.BackendCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA bundle: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA bundle %s contains no certificates", cfg.BackendCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+	if cfg.BackendProxy != "" {
+		proxyURL, err := url.Parse(cfg.BackendProxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy URL: %w", err)
+		}
+		noProxy := parseNoProxy(cfg.BackendNoProxy)
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if noProxy.matches(req.URL) {
+				return nil, nil
+			}
+			return proxyURL, nil
+		}
+	}
+	return transport, nil
+}
+
+// noProxyList is a parsed NO_PROXY value: a comma-separated list of "*",
+// IP addresses, CIDR ranges, and host names, each optionally with a port. A
+// host name also matches its subdomains, with or without a leading dot.
+type noProxyList struct {
+	all   bool
+	nets  []*net.IPNet
+	hosts []noProxyHost
+}
+
+type noProxyHost struct {
+	name string // Lower case, without a leading dot.
+	port string // Empty to match any port.
+	ip   bool   // Name is an IP address, which has no subdomains.
+}
+
+func parseNoProxy(v string) noProxyList {
+	var list noProxyList
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case entry == "":
+			continue
+		case entry == "*":
+			list.all = true
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			list.nets = append(list.nets, ipNet)
+			continue
+		}
+		host, port := entry, ""
+		if h, p, err := net.SplitHostPort(entry); err == nil {
+			host, port = h, p
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			list.hosts = append(list.hosts, noProxyHost{name: ip.String(), port: port, ip: true})
+			continue
+		}
+		list.hosts = append(list.hosts, noProxyHost{name: strings.TrimPrefix(host, "."), port: port})
+	}
+	return list
+}
+
+// matches reports whether requests to u bypass the proxy.
+func (l noProxyList) matches(u *url.URL) bool {
+	if l.all {
+		return true
+	}
+	host, port := strings.ToLower(u.Hostname()), u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	ip := net.ParseIP(host)
+	for _, n := range l.nets {
+		if ip != nil && n.Contains(ip) {
+			return true
+		}
+	}
+	if ip != nil {
+		host = ip.String()
+	}
+	for _, h := range l.hosts {
+		if h.port != "" && h.port != port {
+			continue
+		}
+		if host == h.name || (ip == nil && !h.ip && strings.HasSuffix(host, "."+h.name)) {
+			return true
+		}
+	}
+	return false
+}
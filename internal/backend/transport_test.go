@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/config"
+)
+
+func TestNoProxyMatches(t *testing.T) {
+	tests := []struct {
+		noProxy string
+		url     string
+		want    bool
+	}{
+		{"", "https://backend.example.com", false},
+		{"*", "https://backend.example.com", true},
+		{"example.com", "https://example.com/api", true},
+		{"example.com", "https://backend.example.com/api", true},
+		{"example.com", "https://notexample.com/api", false},
+		{".example.com", "https://backend.example.com/api", true},
+		{".example.com", "https://example.com/api", true},
+		{"Example.COM", "https://BACKEND.example.com", true},
+		{"example.com:8443", "https://example.com:8443", true},
+		{"example.com:8443", "https://example.com", false},
+		{"example.com:443", "https://example.com", true},
+		{"example.com:80", "http://example.com", true},
+		{"10.0.0.0/8", "http://10.1.2.3:8080", true},
+		{"10.0.0.0/8", "http://11.1.2.3", false},
+		{"10.0.0.0/8", "http://ten.example.com", false},
+		{"192.168.1.10", "http://192.168.1.10/api", true},
+		{"192.168.1.10", "http://192.168.1.11/api", false},
+		{"192.168.1.10:8080", "http://192.168.1.10:8080", true},
+		{"192.168.1.10:8080", "http://192.168.1.10:9090", false},
+		{"::1", "http://[::1]:8080", true},
+		{"[::1]:8080", "http://[0:0:0:0:0:0:0:1]:8080", true},
+		{"fd00::/8", "http://[fd12::1]", true},
+		{"1.2.3.4", "http://host.1.2.3.4", false},
+		{"localhost, example.com", "http://backend.example.com", true},
+		{" , localhost ,", "http://localhost:3000", true},
+	}
+	for _, tt := range tests {
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := parseNoProxy(tt.noProxy).matches(u); got != tt.want {
+			t.Errorf("%q: matches(%s) = %v, want %v", tt.noProxy, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}))
+	ca := write("ca.pem", certPEM)
+	emptyCA := write("empty.pem", "# no certificates here\n")
+
+	tests := []struct {
+		name    string
+		cfg     config.Config
+		wantErr string
+	}{
+		{"CA bundle", config.Config{BackendCAFile: ca}, ""},
+		{"CA bundle without certificates", config.Config{BackendCAFile: emptyCA}, "contains no certificates"},
+		{"missing CA bundle", config.Config{BackendCAFile: filepath.Join(dir, "missing.pem")}, "failed to read CA bundle"},
+		{"certificate without key", config.Config{BackendTLSCertFile: ca, BackendTLSKeyFile: filepath.Join(dir, "missing.key")}, "failed to load client certificate"},
+		{"key that is not a key", config.Config{BackendTLSCertFile: ca, BackendTLSKeyFile: ca}, "failed to load client certificate"},
+		{"invalid proxy", config.Config{BackendProxy: "http://proxy:port"}, "invalid proxy URL"},
+	}
+	for _, tt := range tests {
+		transport, err := newTransport(&tt.cfg)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: newTransport() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: newTransport() error = %v", tt.name, err)
+			continue
+		}
+		resp, err := (&http.Client{Transport: transport}).Get(srv.URL)
+		if err != nil {
+			t.Errorf("%s: request to a server signed by the CA bundle failed: %v", tt.name, err)
+			continue
+		}
+		resp.Body.Close()
+	}
+}
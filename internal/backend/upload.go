@@ -0,0 +1,381 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Chunked upload protocol. Relative to the backend endpoint:
+//
+//	POST sessions                      open a session for a crawl
+//	PUT  sessions/{id}/batches/{seq}   upload batch number seq (from 0)
+//	POST sessions/{id}/commit          commit once every batch is uploaded
+//
+// Each batch carries one section of the crawl payload, identified by its JSON
+// field name. Array sections are split into "items" that the receiver appends
+// in sequence order; map and object sections are split into "entries" that it
+// merges. Scalar sections are sent whole as "value".
+
+// Default batch bounds, used when the configuration leaves them unset.
+const (
+	DefaultBatchBytes = 1 << 20
+	DefaultBatchItems = 500
+)
+
+// OpenSessionRequest is the body of a session open request.
+type OpenSessionRequest struct {
+	CrawlID string `json:"crawl_id"`
+	AgentID string `json:"agent_id"`
+}
+
+// OpenSessionResponse is the body of the response to a session open request.
+type OpenSessionResponse struct {
+	SessionID string `json:"session_id"`
+}
+
+// Batch is one size-bounded part of a section.
+type Batch struct {
+	Sequence int                        `json:"sequence"`
+	Section  string                     `json:"section"`
+	Items    []json.RawMessage          `json:"items,omitempty"`
+	Entries  map[string]json.RawMessage `json:"entries,omitempty"`
+	Value    json.RawMessage            `json:"value,omitempty"`
+}
+
+// CommitRequest is the body of a session commit request. Sections maps each
+// section to the number of items or entries sent for it, so the receiver can
+// check that it reassembled everything.
+type CommitRequest struct {
+	BatchCount int            `json:"batch_count"`
+	Sections   map[string]int `json:"sections"`
+}
+
+// UploadCrawl uploads data, a struct whose fields are the payload sections or
+// an already encoded JSON object, through the chunked upload protocol. Items
+// are encoded one at a time, so the whole payload is never marshalled into a
+// single buffer.
+func (c *Client) UploadCrawl(ctx context.Context, crawlID string, data any) error {
+	sections, err := payloadSections(data)
+	if err != nil {
+		return err
+	}
+	sessionsURL, err := url.JoinPath(c.endpoint, "sessions")
+	if err != nil {
+		return fmt.Errorf("invalid backend endpoint: %w", err)
+	}
+	open, err := json.Marshal(OpenSessionRequest{CrawlID: crawlID, AgentID: c.agentID})
+	if err != nil {
+		return err
+	}
+	body, err := c.deliver(ctx, "POST", sessionsURL, crawlID, open)
+	if err != nil {
+		return fmt.Errorf("failed to open upload session: %w", err)
+	}
+	var session OpenSessionResponse
+	if err := json.Unmarshal(body, &session); err != nil || session.SessionID == "" {
+		return fmt.Errorf("backend returned no upload session")
+	}
+	sessionURL := sessionsURL + "/" + url.PathEscape(session.SessionID)
+
+	commit := CommitRequest{Sections: make(map[string]int)}
+	send := func(b *Batch) error {
+		b.Sequence = commit.BatchCount
+		payload, err := json.Marshal(b)
+		if err != nil {
+			return err
+		}
+		seq := strconv.Itoa(b.Sequence)
+		if _, err := c.deliver(ctx, "PUT", sessionURL+"/batches/"+seq, crawlID+"/"+seq, payload); err != nil {
+			return fmt.Errorf("failed to upload batch %s of section %s: %w", seq, b.Section, err)
+		}
+		commit.BatchCount++
+		return nil
+	}
+
+	for _, section := range sections {
+		count, err := c.uploadSection(section.name, section.value, send)
+		if err != nil {
+			return err
+		}
+		commit.Sections[section.name] = count
+	}
+
+	payload, err := json.Marshal(commit)
+	if err != nil {
+		return err
+	}
+	reply, err := c.deliver(ctx, "POST", sessionURL+"/commit", crawlID+"/commit", payload)
+	if err != nil {
+		return fmt.Errorf("failed to commit upload session: %w", err)
+	}
+	c.collectInstructions(reply)
+	return nil
+}
+
+type section struct {
+	name  string
+	value reflect.Value
+}
+
+// payloadSections lists the sections of data in order. The members of an
+// encoded JSON object are decoded only down to their items.
+func payloadSections(data any) ([]section, error) {
+	raw, ok := data.(json.RawMessage)
+	if !ok {
+		return structSections(reflect.Indirect(reflect.ValueOf(data))), nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil, fmt.Errorf("payload is not a JSON object")
+	}
+	var sections []section
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode payload: %w", err)
+		}
+		name, _ := tok.(string)
+		var member json.RawMessage
+		if err := dec.Decode(&member); err != nil {
+			return nil, fmt.Errorf("failed to decode payload section %s: %w", name, err)
+		}
+		var value any = member
+		switch member[0] {
+		case '[', 'n':
+			var items []json.RawMessage
+			if err := json.Unmarshal(member, &items); err != nil {
+				return nil, fmt.Errorf("failed to decode payload section %s: %w", name, err)
+			}
+			value = items
+		case '{':
+			var entries map[string]json.RawMessage
+			if err := json.Unmarshal(member, &entries); err != nil {
+				return nil, fmt.Errorf("failed to decode payload section %s: %w", name, err)
+			}
+			value = entries
+		}
+		sections = append(sections, section{name, reflect.ValueOf(value)})
+	}
+	return sections, nil
+}
+
+// structSections lists the fields of a struct as sections. As in
+// encoding/json, the fields of an untagged embedded struct are promoted.
+func structSections(v reflect.Value) []section {
+	var sections []section
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Type().Field(i)
+		if f.Anonymous && f.Tag.Get("json") == "" {
+			embedded := v.Field(i)
+			if embedded.Kind() == reflect.Pointer {
+				if embedded.IsNil() {
+					continue
+				}
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				sections = append(sections, structSections(embedded)...)
+				continue
+			}
+		}
+		if name := sectionName(f); name != "" {
+			sections = append(sections, section{name, v.Field(i)})
+		}
+	}
+	return sections
+}
+
+// sectionName returns the JSON name of a payload field, or "" when the field
+// is not serialised.
+func sectionName(f reflect.StructField) string {
+	if !f.IsExported() {
+		return ""
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return f.Name
+	}
+	return name
+}
+
+// uploadSection splits one section into batches bounded by c.batchBytes and
+// c.batchItems and passes them to send. It returns the number of items or
+// entries in the section.
+func (c *Client) uploadSection(name string, v reflect.Value, send func(*Batch) error) (int, error) {
+	batch := &Batch{Section: name}
+	size, count := 0, 0
+	flush := func() error {
+		if len(batch.Items) == 0 && len(batch.Entries) == 0 {
+			return nil
+		}
+		err := send(batch)
+		batch = &Batch{Section: name}
+		size = 0
+		return err
+	}
+	add := func(key string, raw json.RawMessage) error {
+		n := len(key) + len(raw)
+		if size > 0 && (size+n > c.batchBytes || len(batch.Items)+len(batch.Entries) >= c.batchItems) {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if key == "" {
+			batch.Items = append(batch.Items, raw)
+		} else {
+			if batch.Entries == nil {
+				batch.Entries = make(map[string]json.RawMessage)
+			}
+			batch.Entries[key] = raw
+		}
+		size += n
+		count++
+		return nil
+	}
+
+	// Values with their own encoding, such as timestamps and raw JSON, are
+	// sent whole.
+	if m, ok := v.Interface().(json.Marshaler); ok {
+		raw, err := m.MarshalJSON()
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		return 1, send(&Batch{Section: name, Value: raw})
+	}
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			raw, err := json.Marshal(v.Index(i).Interface())
+			if err != nil {
+				return 0, fmt.Errorf("failed to encode %s item %d: %w", name, i, err)
+			}
+			if err := add("", raw); err != nil {
+				return 0, err
+			}
+		}
+	case reflect.Map, reflect.Struct:
+		entries, err := objectEntries(v)
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if err := add(k, entries[k]); err != nil {
+				return 0, err
+			}
+		}
+	default:
+		raw, err := json.Marshal(v.Interface())
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		return 1, send(&Batch{Section: name, Value: raw})
+	}
+	// An empty array or object is sent whole, so that it is not reassembled
+	// as null.
+	if count == 0 && !((v.Kind() == reflect.Slice || v.Kind() == reflect.Map) && v.IsNil()) {
+		raw, err := json.Marshal(v.Interface())
+		if err != nil {
+			return 0, fmt.Errorf("failed to encode %s: %w", name, err)
+		}
+		return 0, send(&Batch{Section: name, Value: raw})
+	}
+	return count, flush()
+}
+
+// Reassemble joins the batches of a session, keyed by sequence number, into
+// the payload a single-request upload would have sent, checking them against
+// the counts announced in commit. Members are in key order, not the order
+// they were uploaded in.
+func Reassemble(batches map[int]Batch, commit CommitRequest) ([]byte, error) {
+	if len(batches) != commit.BatchCount {
+		return nil, fmt.Errorf("received %d of %d batches", len(batches), commit.BatchCount)
+	}
+	items := make(map[string][]json.RawMessage)
+	entries := make(map[string]map[string]json.RawMessage)
+	payload := make(map[string]json.RawMessage)
+	for seq := 0; seq < commit.BatchCount; seq++ {
+		batch, ok := batches[seq]
+		if !ok {
+			return nil, fmt.Errorf("batch %d is missing", seq)
+		}
+		if _, ok := commit.Sections[batch.Section]; !ok {
+			return nil, fmt.Errorf("batch %d is of section %s, which is not committed", seq, batch.Section)
+		}
+		switch {
+		case batch.Value != nil:
+			payload[batch.Section] = batch.Value
+		case batch.Entries != nil:
+			if entries[batch.Section] == nil {
+				entries[batch.Section] = make(map[string]json.RawMessage)
+			}
+			for k, v := range batch.Entries {
+				entries[batch.Section][k] = v
+			}
+		default:
+			items[batch.Section] = append(items[batch.Section], batch.Items...)
+		}
+	}
+
+	for section, want := range commit.Sections {
+		var err error
+		switch {
+		case items[section] != nil:
+			if len(items[section]) != want {
+				return nil, fmt.Errorf("section %s has %d of %d items", section, len(items[section]), want)
+			}
+			payload[section], err = json.Marshal(items[section])
+		case entries[section] != nil:
+			if len(entries[section]) != want {
+				return nil, fmt.Errorf("section %s has %d of %d entries", section, len(entries[section]), want)
+			}
+			payload[section], err = json.Marshal(entries[section])
+		case payload[section] == nil:
+			if want != 0 {
+				return nil, fmt.Errorf("section %s is missing", section)
+			}
+			payload[section] = json.RawMessage("null")
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(payload)
+}
+
+// objectEntries encodes the members of a map or struct one at a time. Struct
+// members are small, so a struct is encoded whole and then split.
+func objectEntries(v reflect.Value) (map[string]json.RawMessage, error) {
+	entries := make(map[string]json.RawMessage)
+	if v.Kind() == reflect.Struct {
+		raw, err := json.Marshal(v.Interface())
+		if err != nil {
+			return nil, err
+		}
+		return entries, json.Unmarshal(raw, &entries)
+	}
+	iter := v.MapRange()
+	for iter.Next() {
+		raw, err := json.Marshal(iter.Value().Interface())
+		if err != nil {
+			return nil, err
+		}
+		entries[fmt.Sprint(iter.Key().Interface())] = raw
+	}
+	return entries, nil
+}
@@ -0,0 +1,256 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type uploadItem struct {
+	ID   string `json:"Id"`
+	Name string `json:"Name"`
+}
+
+type uploadPayload struct {
+	SchemaVersion int               `json:"schema_version"`
+	StartedAt     time.Time         `json:"started_at"`
+	Account       uploadItem        `json:"account"`
+	Instances     []uploadItem      `json:"instances"`
+	Tags          map[string]string `json:"tags"`
+	Empty         []uploadItem      `json:"empty"`
+	EmptyTags     map[string]string `json:"empty_tags"`
+	Missing       []uploadItem      `json:"missing"`
+	internal      string
+}
+
+// uploadBackend is an httptest backend implementing the chunked upload
+// protocol for one session.
+type uploadBackend struct {
+	mu      sync.Mutex
+	batches map[int]Batch
+	payload []byte // Reassembled on commit.
+	drop    int    // Batch that is acknowledged but not stored; -1 for none.
+	fail    int    // Batch whose first upload is stored but answered with 503; -1 for none.
+	failed  bool
+}
+
+func (b *uploadBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch {
+	case r.Method == "POST" && r.URL.Path == "/sessions":
+		json.NewEncoder(w).Encode(OpenSessionResponse{SessionID: "s1"})
+	case r.Method == "PUT" && strings.HasPrefix(r.URL.Path, "/sessions/s1/batches/"):
+		seq, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/sessions/s1/batches/"))
+		var batch Batch
+		if err := json.Unmarshal(body, &batch); err != nil || batch.Sequence != seq {
+			http.Error(w, "invalid batch", http.StatusBadRequest)
+			return
+		}
+		if seq != b.drop {
+			b.batches[seq] = batch
+		}
+		if seq == b.fail && !b.failed {
+			b.failed = true
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		}
+	case r.Method == "POST" && r.URL.Path == "/sessions/s1/commit":
+		var commit CommitRequest
+		json.Unmarshal(body, &commit)
+		payload, err := Reassemble(b.batches, commit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusConflict)
+			return
+		}
+		b.payload = payload
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// decodeJSON decodes data for a comparison that ignores member order.
+func decodeJSON(t *testing.T, data []byte) any {
+	t.Helper()
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		t.Fatalf("invalid JSON %s: %v", data, err)
+	}
+	return v
+}
+
+func TestUploadCrawlRoundTrip(t *testing.T) {
+	data := uploadPayload{
+		SchemaVersion: 2,
+		StartedAt:     time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		Account:       uploadItem{ID: "123456789012", Name: "prod"},
+		Tags:          map[string]string{"env": "prod", "team": "platform", "cost-center": "42"},
+		Empty:         []uploadItem{},
+		EmptyTags:     map[string]string{},
+		internal:      "not serialised",
+	}
+	for i := 0; i < 7; i++ {
+		data.Instances = append(data.Instances, uploadItem{ID: fmt.Sprintf("i-%02d", i), Name: strings.Repeat("x", i*10)})
+	}
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		data       any
+		batchBytes int
+		batchItems int
+	}{
+		{"struct", data, DefaultBatchBytes, DefaultBatchItems},
+		{"struct pointer", &data, DefaultBatchBytes, DefaultBatchItems},
+		{"raw message", json.RawMessage(encoded), DefaultBatchBytes, DefaultBatchItems},
+		{"struct split by bytes", data, 64, DefaultBatchItems},
+		{"raw message split by bytes", json.RawMessage(encoded), 64, DefaultBatchItems},
+		{"struct split by items", data, DefaultBatchBytes, 2},
+		{"raw message split by items", json.RawMessage(encoded), DefaultBatchBytes, 2},
+	}
+	for _, tt := range tests {
+		b := &uploadBackend{batches: make(map[int]Batch), drop: -1, fail: -1}
+		srv := httptest.NewServer(b)
+		c := &Client{endpoint: srv.URL, httpClient: srv.Client(), retry: RetryPolicy{MaxAttempts: 1}, batchBytes: tt.batchBytes, batchItems: tt.batchItems, encoding: EncodingIdentity}
+		err := c.UploadCrawl(context.Background(), "crawl-1", tt.data)
+		srv.Close()
+		if err != nil {
+			t.Errorf("%s: UploadCrawl() error = %v", tt.name, err)
+			continue
+		}
+		if got, want := decodeJSON(t, b.payload), decodeJSON(t, encoded); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: reassembled %s, want %s", tt.name, b.payload, encoded)
+		}
+		for seq, batch := range b.batches {
+			if n := len(batch.Items) + len(batch.Entries); n > tt.batchItems {
+				t.Errorf("%s: batch %d has %d items, want at most %d", tt.name, seq, n, tt.batchItems)
+			}
+			size := 0
+			for _, item := range batch.Items {
+				size += len(item)
+			}
+			for k, v := range batch.Entries {
+				size += len(k) + len(v)
+			}
+			if len(batch.Items)+len(batch.Entries) > 1 && size > tt.batchBytes {
+				t.Errorf("%s: batch %d has %d bytes of items, want at most %d", tt.name, seq, size, tt.batchBytes)
+			}
+		}
+		if tt.batchItems == 2 {
+			instances := 0
+			for _, batch := range b.batches {
+				if batch.Section == "instances" {
+					instances++
+				}
+			}
+			if instances != 4 {
+				t.Errorf("%s: instances sent in %d batches, want 4", tt.name, instances)
+			}
+		}
+	}
+}
+
+func TestUploadCrawlBatchFailures(t *testing.T) {
+	data := uploadPayload{Instances: []uploadItem{{ID: "i-1"}, {ID: "i-2"}, {ID: "i-3"}}}
+	tests := []struct {
+		name    string
+		drop    int
+		fail    int
+		wantErr bool
+	}{
+		{"missing batch", 1, -1, true},
+		{"re-sent batch", -1, 1, false},
+	}
+	for _, tt := range tests {
+		b := &uploadBackend{batches: make(map[int]Batch), drop: tt.drop, fail: tt.fail}
+		srv := httptest.NewServer(b)
+		c := &Client{
+			endpoint:   srv.URL,
+			httpClient: srv.Client(),
+			retry:      RetryPolicy{MaxAttempts: 2, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxWait: time.Second},
+			batchBytes: DefaultBatchBytes,
+			batchItems: 1,
+			encoding:   EncodingIdentity,
+		}
+		err := c.UploadCrawl(context.Background(), "crawl-1", data)
+		srv.Close()
+		if gotErr := err != nil; gotErr != tt.wantErr {
+			t.Errorf("%s: UploadCrawl() error = %v, want error %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusConflict {
+				t.Errorf("%s: UploadCrawl() error = %v, want the rejected commit", tt.name, err)
+			}
+			continue
+		}
+		var got uploadPayload
+		if err := json.Unmarshal(b.payload, &got); err != nil {
+			t.Fatalf("%s: invalid payload %s: %v", tt.name, b.payload, err)
+		}
+		if !reflect.DeepEqual(got.Instances, data.Instances) {
+			t.Errorf("%s: instances = %v, want %v", tt.name, got.Instances, data.Instances)
+		}
+	}
+}
+
+func TestReassembleRejectsInconsistentBatches(t *testing.T) {
+	item := json.RawMessage(`{"Id":"i-1"}`)
+	tests := []struct {
+		name    string
+		batches map[int]Batch
+		commit  CommitRequest
+		want    string
+	}{
+		{
+			"missing batch",
+			map[int]Batch{0: {Section: "instances", Items: []json.RawMessage{item}}, 2: {Section: "instances", Items: []json.RawMessage{item}}},
+			CommitRequest{BatchCount: 2, Sections: map[string]int{"instances": 2}},
+			"batch 1 is missing",
+		},
+		{
+			"extra batch",
+			map[int]Batch{0: {Section: "instances", Items: []json.RawMessage{item}}, 1: {Section: "instances", Items: []json.RawMessage{item}}},
+			CommitRequest{BatchCount: 1, Sections: map[string]int{"instances": 1}},
+			"received 2 of 1 batches",
+		},
+		{
+			"item count mismatch",
+			map[int]Batch{0: {Section: "instances", Items: []json.RawMessage{item}}},
+			CommitRequest{BatchCount: 1, Sections: map[string]int{"instances": 2}},
+			"has 1 of 2 items",
+		},
+		{
+			"uncommitted section",
+			map[int]Batch{0: {Section: "volumes", Items: []json.RawMessage{item}}},
+			CommitRequest{BatchCount: 1, Sections: map[string]int{"instances": 0}},
+			"not committed",
+		},
+		{
+			"missing section",
+			map[int]Batch{},
+			CommitRequest{Sections: map[string]int{"instances": 1}},
+			"section instances is missing",
+		},
+	}
+	for _, tt := range tests {
+		_, err := Reassemble(tt.batches, tt.commit)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: Reassemble() error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -2,27 +2,236 @@ package backend
 
 import (
 	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"log"
 	"net/http"
+	"sync"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/command"
+	"github.com/DavisAndn/go-aws-crawler/internal/config"
 )
 
-// SendInitialCrawlResults posts the JSON payload to the backend endpoint.
-func SendInitialCrawlResults(backendURL string, payload []byte) error {
-	req, err := http.NewRequest("POST", backendURL, bytes.NewBuffer(payload))
+// Client sends crawl results to the backend, authenticating and signing
+// each request as configured.
+type Client struct {
+	endpoint      string
+	agentID       string
+	httpClient    *http.Client
+	retry         RetryPolicy
+	batchBytes    int
+	batchItems    int
+	tokens        TokenSource // Nil when no bearer token is configured.
+	signingSecret []byte      // Nil when request signing is disabled.
+
+	encodingMu sync.Mutex
+	encoding   string // Content encoding of request bodies; lowered when the backend answers 415.
+
+	instructionsURL string // Polled for instructions; empty to rely on replies only.
+	instructionsMu  sync.Mutex
+	instructions    []command.Instruction // Received in replies and not yet taken.
+}
+
+// NewClient returns a backend client for cfg. A token-exchange endpoint takes
+// precedence over a static bearer token.
+func NewClient(cfg *config.Config) (*Client, error) {
+	transport, err := newTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		endpoint:   cfg.BackendEndpoint,
+		agentID:    cfg.AgentID,
+		httpClient: &http.Client{Timeout: cfg.BackendTimeout, Transport: transport},
+		retry:      DefaultRetryPolicy,
+		batchBytes: DefaultBatchBytes,
+		batchItems: DefaultBatchItems,
+		encoding:   cfg.BackendCompression,
+
+		instructionsURL: cfg.BackendInstructionsURL,
+	}
+	if cfg.BackendBatchBytes > 0 {
+		c.batchBytes = cfg.BackendBatchBytes
+	}
+	if cfg.BackendBatchItems > 0 {
+		c.batchItems = cfg.BackendBatchItems
+	}
+	if cfg.BackendMaxAttempts > 0 {
+		c.retry.MaxAttempts = cfg.BackendMaxAttempts
+	}
+	switch {
+	case cfg.BackendTokenURL != "":
+		c.tokens = &ExchangeTokenSource{
+			URL:          cfg.BackendTokenURL,
+			ClientID:     cfg.BackendClientID,
+			ClientSecret: cfg.BackendClientSecret,
+			HTTPClient:   c.httpClient,
+		}
+	case cfg.BackendToken != "":
+		c.tokens = StaticToken(cfg.BackendToken)
+	}
+	if cfg.BackendSigningSecret != "" {
+		c.signingSecret = []byte(cfg.BackendSigningSecret)
+	}
+	return c, nil
+}
+
+// SendInitialCrawlResults posts the JSON payload to the backend endpoint,
+// retrying retryable failures. crawlID is sent as the idempotency key so the
+// backend can recognise a retried delivery.
+func (c *Client) SendInitialCrawlResults(ctx context.Context, crawlID string, payload []byte) error {
+	body, err := c.deliver(ctx, http.MethodPost, c.endpoint, crawlID, payload)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
+	c.collectInstructions(body)
+	return nil
+}
 
-	resp, err := http.DefaultClient.Do(req)
+// Instructions returns the instructions received in replies since the last
+// call, followed by those returned by the instructions endpoint when one is
+// configured.
+func (c *Client) Instructions(ctx context.Context) ([]command.Instruction, error) {
+	c.instructionsMu.Lock()
+	instructions := c.instructions
+	c.instructions = nil
+	c.instructionsMu.Unlock()
+
+	if c.instructionsURL == "" {
+		return instructions, nil
+	}
+	body, err := c.deliver(ctx, http.MethodGet, c.instructionsURL, "", nil)
 	if err != nil {
-		return fmt.Errorf("failed to send HTTP request: %w", err)
+		return instructions, fmt.Errorf("failed to poll instructions: %w", err)
+	}
+	var resp command.Response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return instructions, fmt.Errorf("failed to decode instructions: %w", err)
+	}
+	return append(instructions, resp.Instructions...), nil
+}
+
+// collectInstructions keeps the instructions in a reply body. Replies without
+// instructions, including those that are not JSON, are ignored.
+func (c *Client) collectInstructions(body []byte) {
+	var resp command.Response
+	if json.Unmarshal(body, &resp) != nil || len(resp.Instructions) == 0 {
+		return
+	}
+	c.instructionsMu.Lock()
+	c.instructions = append(c.instructions, resp.Instructions...)
+	c.instructionsMu.Unlock()
+}
+
+// deliver sends a request, retrying retryable failures, and returns the body
+// of the successful response.
+func (c *Client) deliver(ctx context.Context, method, url, idempotencyKey string, payload []byte) ([]byte, error) {
+	var body []byte
+	err := c.retry.do(ctx, func(ctx context.Context) error {
+		var err error
+		body, err = c.send(ctx, method, url, idempotencyKey, payload)
+		return err
+	})
+	return body, err
+}
+
+// send makes a single delivery attempt. A request rejected as unauthorized is
+// repeated once with a fresh token when the token source can renew it, and a
+// compressed request rejected with 415 is repeated with a fallback encoding,
+// which later requests keep using.
+func (c *Client) send(ctx context.Context, method, url, idempotencyKey string, payload []byte) ([]byte, error) {
+	encoding := c.contentEncoding()
+	if payload == nil {
+		encoding = EncodingIdentity
+	}
+	resp, err := c.do(ctx, method, url, idempotencyKey, encoding, payload)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusUnauthorized && c.tokens != nil && c.tokens.Invalidate() {
+		resp.Body.Close()
+		if resp, err = c.do(ctx, method, url, idempotencyKey, encoding, payload); err != nil {
+			return nil, err
+		}
+	}
+	// Each encoding is tried once, so a backend that keeps advertising the
+	// encoding it just rejected ends in an uncompressed request.
+	tried := map[string]bool{encoding: true}
+	for resp.StatusCode == http.StatusUnsupportedMediaType && encoding != EncodingIdentity {
+		resp.Body.Close()
+		fallback := fallbackEncoding(resp.Header.Get("Accept-Encoding"), tried)
+		tried[fallback] = true
+		log.Printf("Backend rejected %s request bodies, falling back to %q", encoding, fallback)
+		c.setContentEncoding(fallback)
+		encoding = fallback
+		if resp, err = c.do(ctx, method, url, idempotencyKey, encoding, payload); err != nil {
+			return nil, err
+		}
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 300 {
-		return fmt.Errorf("backend returned status %d", resp.StatusCode)
+		return nil, newStatusError(resp)
 	}
 
-	return nil
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	return body, nil
+}
+
+func (c *Client) contentEncoding() string {
+	c.encodingMu.Lock()
+	defer c.encodingMu.Unlock()
+	return c.encoding
+}
+
+func (c *Client) setContentEncoding(encoding string) {
+	c.encodingMu.Lock()
+	defer c.encodingMu.Unlock()
+	c.encoding = encoding
+}
+
+func (c *Client) do(ctx context.Context, method, url, idempotencyKey, encoding string, payload []byte) (*http.Response, error) {
+	body, err := compress(encoding, payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if encoding != EncodingIdentity {
+		req.Header.Set("Content-Encoding", encoding)
+	}
+	if idempotencyKey != "" {
+		req.Header.Set(HeaderIdempotencyKey, idempotencyKey)
+	}
+	if c.agentID != "" {
+		req.Header.Set(HeaderAgentID, c.agentID)
+	}
+	if c.tokens != nil {
+		token, err := c.tokens.Token(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to obtain backend token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	if c.signingSecret != nil {
+		if err := SignRequest(req, body, c.signingSecret); err != nil {
+			return nil, err
+		}
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send HTTP request: %w", err)
+	}
+	return resp, nil
 }
@@ -0,0 +1,250 @@
+// Package command validates the instructions the backend sends the crawler
+// and keeps the settings they change.
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Type is the kind of an instruction.
+type Type string
+
+const (
+	// Recrawl crawls Services again and delivers the result as a partial
+	// crawl, limited to ResourceIDs when any are given and to Regions when
+	// any are given.
+	Recrawl Type = "recrawl"
+	// Describe crawls the single resource ResourceIDs[0] of Services[0].
+	Describe Type = "describe"
+	// SetRegions replaces the regions crawled by every later invocation.
+	SetRegions Type = "set_regions"
+	// SetSchedule changes the expression of the crawler's schedule.
+	SetSchedule Type = "set_schedule"
+)
+
+// DefaultAllowed are the instruction types executed when no allowlist is
+// configured: only those that read from the account.
+var DefaultAllowed = []Type{Recrawl, Describe}
+
+// Limits on instructions.
+const (
+	MaxInstructions = 10 // Executed per invocation; the rest are dropped.
+	// MaxCrawls bounds the region crawls recrawl and describe instructions
+	// run per invocation, as each is a full crawl of its services. An
+	// instruction over the bound waits for the backend to repeat it.
+	MaxCrawls = 3
+	MaxResourceIDs  = 100
+	MinScheduleRate = 15 * time.Minute
+)
+
+// Instruction is a request from the backend for the crawler to act.
+type Instruction struct {
+	ID          string   `json:"id"`
+	Type        Type     `json:"type"`
+	Services    []string `json:"services,omitempty"`
+	ResourceIDs []string `json:"resource_ids,omitempty"`
+	Regions     []string `json:"regions,omitempty"`
+	Schedule    string   `json:"schedule,omitempty"`
+}
+
+// Response is the body of backend replies that carry instructions, both to
+// deliveries and to polls.
+type Response struct {
+	Instructions []Instruction `json:"instructions"`
+}
+
+// Policy decides which instructions the crawler accepts.
+type Policy struct {
+	Allowed  []Type
+	Services []string // The services the crawler can fetch.
+	// Regions are the regions instructions may name: those enabled in the
+	// account, or the configured regions when the account could not be read.
+	Regions []string
+	// ScheduleName is the schedule set_schedule updates; set_schedule is
+	// rejected when it is empty.
+	ScheduleName string
+}
+
+var (
+	idPattern     = regexp.MustCompile(`^[A-Za-z0-9_.-]{1,64}$`)
+	regionPattern = regexp.MustCompile(`^[a-z]{2}(-[a-z]+)+-\d+$`)
+	ratePattern   = regexp.MustCompile(`^rate\((\d+) (minute|minutes|hour|hours|day|days)\)$`)
+	cronPattern   = regexp.MustCompile(`^cron\(\S+( \S+){5}\)$`)
+	rateUnits     = map[string]time.Duration{
+		"minute": time.Minute, "minutes": time.Minute,
+		"hour": time.Hour, "hours": time.Hour,
+		"day": 24 * time.Hour, "days": 24 * time.Hour,
+	}
+)
+
+// Validate returns an error when in is malformed or not allowed by p.
+func (p Policy) Validate(in Instruction) error {
+	if !idPattern.MatchString(in.ID) {
+		return fmt.Errorf("instruction ID %q is not 1-64 letters, digits, '.', '_' or '-'", in.ID)
+	}
+	if !slices.Contains(p.Allowed, in.Type) {
+		return fmt.Errorf("instruction type %q is not allowed", in.Type)
+	}
+
+	switch in.Type {
+	case Recrawl:
+		if len(in.Services) == 0 {
+			return fmt.Errorf("recrawl names no services")
+		}
+		if len(in.ResourceIDs) > MaxResourceIDs {
+			return fmt.Errorf("recrawl names %d resources, more than %d", len(in.ResourceIDs), MaxResourceIDs)
+		}
+	case Describe:
+		if len(in.Services) != 1 || len(in.ResourceIDs) != 1 {
+			return fmt.Errorf("describe must name one service and one resource")
+		}
+	case SetRegions:
+		if len(in.Regions) == 0 {
+			return fmt.Errorf("set_regions names no regions")
+		}
+	case SetSchedule:
+		if p.ScheduleName == "" {
+			return fmt.Errorf("no crawl schedule is configured")
+		}
+		if err := validateSchedule(in.Schedule); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown instruction type %q", in.Type)
+	}
+	for _, service := range in.Services {
+		if !slices.Contains(p.Services, service) {
+			return fmt.Errorf("unknown service %q", service)
+		}
+	}
+	for _, region := range in.Regions {
+		if !regionPattern.MatchString(region) {
+			return fmt.Errorf("region %q is malformed", region)
+		}
+		if !slices.Contains(p.Regions, region) {
+			return fmt.Errorf("region %q is not enabled in the account", region)
+		}
+	}
+	return nil
+}
+
+// validateSchedule accepts EventBridge Scheduler rate and cron expressions
+// that run at most every MinScheduleRate.
+func validateSchedule(expr string) error {
+	var interval time.Duration
+	if cronPattern.MatchString(expr) {
+		fields := strings.Fields(strings.TrimSuffix(strings.TrimPrefix(expr, "cron("), ")"))
+		minutes, err := parseCronField(fields[0], 0, 59)
+		if err != nil {
+			return fmt.Errorf("schedule %q has invalid minutes: %w", expr, err)
+		}
+		hours, err := parseCronField(fields[1], 0, 23)
+		if err != nil {
+			return fmt.Errorf("schedule %q has invalid hours: %w", expr, err)
+		}
+		interval = minCronInterval(minutes, hours)
+	} else {
+		m := ratePattern.FindStringSubmatch(expr)
+		if m == nil {
+			return fmt.Errorf("schedule %q is not a rate() or cron() expression", expr)
+		}
+		n, err := strconv.Atoi(m[1])
+		if err != nil {
+			return fmt.Errorf("schedule %q has an invalid rate: %w", expr, err)
+		}
+		interval = time.Duration(n) * rateUnits[m[2]]
+	}
+	if interval < MinScheduleRate {
+		return fmt.Errorf("schedule %q is more frequent than every %v", expr, MinScheduleRate)
+	}
+	return nil
+}
+
+// parseCronField returns the values in [lo, hi] matched by a cron minutes or
+// hours field: a comma-separated list of "*", values and ranges, each
+// optionally followed by "/step".
+func parseCronField(field string, lo, hi int) ([]int, error) {
+	matched := make([]bool, hi+1)
+	for _, part := range strings.Split(field, ",") {
+		spec, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepText)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid step %q", stepText)
+			}
+			step = n
+		}
+		first, last := lo, hi
+		if spec != "*" {
+			from, to, isRange := strings.Cut(spec, "-")
+			var err error
+			if first, err = strconv.Atoi(from); err != nil || first < lo || first > hi {
+				return nil, fmt.Errorf("invalid value %q", from)
+			}
+			last = first
+			if isRange {
+				if last, err = strconv.Atoi(to); err != nil || last < first || last > hi {
+					return nil, fmt.Errorf("invalid range %q", spec)
+				}
+			} else if hasStep {
+				last = hi
+			}
+		}
+		for v := first; v <= last; v += step {
+			matched[v] = true
+		}
+	}
+	var values []int
+	for v, ok := range matched {
+		if ok {
+			values = append(values, v)
+		}
+	}
+	return values, nil
+}
+
+// minCronInterval returns the shortest time between two runs of a schedule
+// firing at minutes past hours, assuming it runs every day. Restricting the
+// days only lengthens the gaps.
+func minCronInterval(minutes, hours []int) time.Duration {
+	var times []int // Minutes since midnight, ascending.
+	for _, h := range hours {
+		for _, m := range minutes {
+			times = append(times, h*60+m)
+		}
+	}
+	shortest := 24 * 60
+	for i := 1; i < len(times); i++ {
+		shortest = min(shortest, times[i]-times[i-1])
+	}
+	// The last run of a day is followed by the first of the next.
+	shortest = min(shortest, times[0]+24*60-times[len(times)-1])
+	return time.Duration(shortest) * time.Minute
+}
+
+// ParseAllowed parses a comma-separated list of instruction types. "none"
+// allows no instructions.
+func ParseAllowed(v string) ([]Type, error) {
+	if strings.TrimSpace(v) == "none" {
+		return nil, nil
+	}
+	var allowed []Type
+	for _, name := range strings.Split(v, ",") {
+		t := Type(strings.TrimSpace(name))
+		switch t {
+		case "":
+			continue
+		case Recrawl, Describe, SetRegions, SetSchedule:
+			allowed = append(allowed, t)
+		default:
+			return nil, fmt.Errorf("unknown instruction type %q", t)
+		}
+	}
+	return allowed, nil
+}
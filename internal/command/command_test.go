@@ -0,0 +1,48 @@
+package command
+
+import "testing"
+
+func TestValidateSchedule(t *testing.T) {
+	tests := []struct {
+		expr string
+		ok   bool
+	}{
+		{"rate(15 minutes)", true},
+		{"rate(1 hour)", true},
+		{"rate(5 minutes)", false},
+		{"cron(0 * * * ? *)", true},
+		{"cron(0/15 * * * ? *)", true},
+		{"cron(0,30 8-17 ? * MON-FRI *)", true},
+		{"cron(0 0 * * ? *)", true},
+		{"cron(* * * * ? *)", false},
+		{"cron(0/5 * * * ? *)", false},
+		{"cron(0-10 9 * * ? *)", false},
+		{"cron(59 0/1 * * ? *)", true},
+		{"cron(50 8 * * ? *)", true},
+		{"cron(55,5 12 * * ? *)", true},
+		{"cron(55,5 12,13 * * ? *)", false}, // 12:55 then 13:05.
+		{"cron(L * * * ? *)", false},
+		{"cron(0 24 * * ? *)", false},
+		{"cron(*/0 * * * ? *)", false},
+		{"every day", false},
+	}
+	for _, tt := range tests {
+		err := validateSchedule(tt.expr)
+		if (err == nil) != tt.ok {
+			t.Errorf("validateSchedule(%q) = %v, want ok %v", tt.expr, err, tt.ok)
+		}
+	}
+}
+
+func TestValidateRegions(t *testing.T) {
+	p := Policy{Allowed: []Type{SetRegions}, Regions: []string{"us-east-1"}}
+	if err := p.Validate(Instruction{ID: "r-1", Type: SetRegions, Regions: []string{"us-east-1"}}); err != nil {
+		t.Errorf("Validate() of an enabled region = %v", err)
+	}
+	if err := p.Validate(Instruction{ID: "r-2", Type: SetRegions, Regions: []string{"eu-west-1"}}); err == nil {
+		t.Error("Validate() accepted a region that is not enabled")
+	}
+	if err := (Policy{Allowed: []Type{SetRegions}}).Validate(Instruction{ID: "r-3", Type: SetRegions, Regions: []string{"us-east-1"}}); err == nil {
+		t.Error("Validate() accepted a region with no regions known")
+	}
+}
@@ -0,0 +1,55 @@
+package command
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+)
+
+// FilterResources trims data, a pointer to a crawl payload struct, down to
+// the resources identified by ids. A list item is kept when one of its
+// top-level string fields, such as an ID, name or ARN, equals an ID; a map
+// entry is kept when its key does. Other fields are left as they are.
+func FilterResources(data any, ids []string) {
+	v := reflect.Indirect(reflect.ValueOf(data))
+	for i := 0; i < v.NumField(); i++ {
+		f := v.Field(i)
+		if !v.Type().Field(i).IsExported() {
+			continue
+		}
+		switch f.Kind() {
+		case reflect.Slice:
+			kept := reflect.MakeSlice(f.Type(), 0, 0)
+			for j := 0; j < f.Len(); j++ {
+				if identifiedBy(f.Index(j).Interface(), ids) {
+					kept = reflect.Append(kept, f.Index(j))
+				}
+			}
+			f.Set(kept)
+		case reflect.Map:
+			for _, key := range f.MapKeys() {
+				if key.Kind() != reflect.String || !slices.Contains(ids, key.String()) {
+					f.SetMapIndex(key, reflect.Value{})
+				}
+			}
+		}
+	}
+}
+
+// identifiedBy reports whether a top-level string field of item is in ids.
+func identifiedBy(item any, ids []string) bool {
+	raw, err := json.Marshal(item)
+	if err != nil {
+		return false
+	}
+	var fields map[string]any
+	if json.Unmarshal(raw, &fields) != nil {
+		return false
+	}
+	for _, v := range fields {
+		if s, ok := v.(string); ok && slices.Contains(ids, s) {
+			return true
+		}
+	}
+	return false
+}
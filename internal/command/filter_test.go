@@ -0,0 +1,55 @@
+package command
+
+import (
+	"reflect"
+	"testing"
+)
+
+type filterItem struct {
+	ID   string `json:"Id"`
+	Arn  string `json:"Arn"`
+	Tags map[string]string
+}
+
+type filterPayload struct {
+	Account   filterItem
+	Instances []filterItem
+	Buckets   []filterItem
+	Coverage  map[string][]string
+	Counts    map[int]int
+	Note      string
+	hidden    []filterItem
+}
+
+func TestFilterResources(t *testing.T) {
+	data := &filterPayload{
+		Account: filterItem{ID: "123456789012"},
+		Instances: []filterItem{
+			{ID: "i-1"},
+			{ID: "i-2", Arn: "arn:aws:ec2:us-east-1:123456789012:instance/i-2"},
+			{ID: "i-3", Tags: map[string]string{"Name": "i-1"}},
+		},
+		Buckets:  []filterItem{{ID: "logs"}, {ID: "assets"}},
+		Coverage: map[string][]string{"i-1": {"plan"}, "vol-1": {"plan"}},
+		Counts:   map[int]int{1: 1},
+		Note:     "unchanged",
+		hidden:   []filterItem{{ID: "other"}},
+	}
+	FilterResources(data, []string{"i-1", "arn:aws:ec2:us-east-1:123456789012:instance/i-2", "missing"})
+
+	want := &filterPayload{
+		Account: filterItem{ID: "123456789012"},
+		Instances: []filterItem{
+			{ID: "i-1"},
+			{ID: "i-2", Arn: "arn:aws:ec2:us-east-1:123456789012:instance/i-2"},
+		},
+		Buckets:  []filterItem{},
+		Coverage: map[string][]string{"i-1": {"plan"}},
+		Counts:   map[int]int{},
+		Note:     "unchanged",
+		hidden:   []filterItem{{ID: "other"}},
+	}
+	if !reflect.DeepEqual(data, want) {
+		t.Errorf("FilterResources() = %+v, want %+v", data, want)
+	}
+}
@@ -0,0 +1,38 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/scheduler"
+)
+
+// UpdateSchedule sets the expression of the EventBridge Scheduler schedule
+// name in the default group. UpdateSchedule replaces the whole schedule, so
+// every other setting is copied from the current one.
+func UpdateSchedule(ctx context.Context, awsConfig aws.Config, name, expression string) error {
+	client := scheduler.NewFromConfig(awsConfig)
+	current, err := client.GetSchedule(ctx, &scheduler.GetScheduleInput{Name: aws.String(name)})
+	if err != nil {
+		return fmt.Errorf("failed to read schedule %s: %w", name, err)
+	}
+	_, err = client.UpdateSchedule(ctx, &scheduler.UpdateScheduleInput{
+		Name:                       current.Name,
+		GroupName:                  current.GroupName,
+		ScheduleExpression:         aws.String(expression),
+		ScheduleExpressionTimezone: current.ScheduleExpressionTimezone,
+		FlexibleTimeWindow:         current.FlexibleTimeWindow,
+		Target:                     current.Target,
+		ActionAfterCompletion:      current.ActionAfterCompletion,
+		Description:                current.Description,
+		StartDate:                  current.StartDate,
+		EndDate:                    current.EndDate,
+		KmsKeyArn:                  current.KmsKeyArn,
+		State:                      current.State,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update schedule %s: %w", name, err)
+	}
+	return nil
+}
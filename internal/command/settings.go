@@ -0,0 +1,73 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+)
+
+// settingsKey is the key of the settings in their Store.
+const settingsKey = "settings.json"
+
+// maxExecuted bounds the instruction IDs remembered in Settings.Executed.
+const maxExecuted = 200
+
+// Store persists the settings between invocations.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// Settings are the crawler settings changed by instructions.
+type Settings struct {
+	// Regions are the regions to crawl; empty means the configured region.
+	Regions []string `json:"regions,omitempty"`
+	// Executed holds the IDs of the latest executed instructions, oldest
+	// first, so an instruction the backend repeats runs only once.
+	Executed []string `json:"executed,omitempty"`
+}
+
+// LoadSettings reads the settings from store, returning empty settings when
+// none were saved.
+func LoadSettings(ctx context.Context, store Store) (*Settings, error) {
+	data, err := store.Get(ctx, settingsKey)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+	var s Settings
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode settings: %w", err)
+	}
+	return &s, nil
+}
+
+// Save writes the settings to store.
+func (s *Settings) Save(ctx context.Context, store Store) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	if err := store.Put(ctx, settingsKey, data); err != nil {
+		return fmt.Errorf("failed to save settings: %w", err)
+	}
+	return nil
+}
+
+// WasExecuted reports whether the instruction id has already run.
+func (s *Settings) WasExecuted(id string) bool {
+	return slices.Contains(s.Executed, id)
+}
+
+// MarkExecuted records that the instruction id has run.
+func (s *Settings) MarkExecuted(id string) {
+	s.Executed = append(s.Executed, id)
+	if n := len(s.Executed) - maxExecuted; n > 0 {
+		s.Executed = s.Executed[n:]
+	}
+}
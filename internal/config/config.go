@@ -1,9 +1,16 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
+	"net/url"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/command"
 )
 
 // Config holds all configuration settings.
@@ -12,6 +19,78 @@ type Config struct {
 	AWSSecretKey    string
 	AWSRegion       string
 	BackendEndpoint string
+
+	// AgentID identifies this crawler deployment to the backend.
+	AgentID string
+	// BackendToken is a static bearer token. It is ignored when
+	// BackendTokenURL is set.
+	BackendToken string
+	// BackendTokenURL is a token-exchange endpoint that trades the client
+	// credentials for short-lived bearer tokens.
+	BackendTokenURL     string
+	BackendClientID     string
+	BackendClientSecret string
+	// BackendSigningSecret enables HMAC request signing when set.
+	BackendSigningSecret string
+	// BackendTimeout bounds each delivery attempt.
+	BackendTimeout time.Duration
+	// BackendMaxAttempts is the number of delivery attempts before giving up.
+	BackendMaxAttempts int
+	// ChunkedUpload sends the payload through the chunked upload protocol
+	// instead of a single request.
+	ChunkedUpload bool
+	// BackendBatchBytes and BackendBatchItems bound the batches of a chunked
+	// upload; zero selects the defaults.
+	BackendBatchBytes int
+	BackendBatchItems int
+	// BackendCompression is the Content-Encoding of request bodies: "gzip",
+	// "zstd" or "" for none.
+	BackendCompression string
+	// BackendTLSCertFile and BackendTLSKeyFile hold the PEM client
+	// certificate and key presented for mutual TLS.
+	BackendTLSCertFile string
+	BackendTLSKeyFile  string
+	// BackendCAFile is a PEM bundle of CAs trusted in addition to the system
+	// roots, such as a private CA or a TLS-inspecting proxy.
+	BackendCAFile string
+	// BackendTLSMinVersion is the minimum TLS version, a crypto/tls constant.
+	BackendTLSMinVersion uint16
+	// BackendProxy is the proxy URL for backend requests, overriding the
+	// HTTPS_PROXY environment variable; hosts matching BackendNoProxy are
+	// reached directly.
+	BackendProxy   string
+	BackendNoProxy string
+
+	// Sinks lists the destinations of finished crawls: "http", "s3", "sqs",
+	// "file" and "stdout".
+	Sinks []string
+	// SinkS3Bucket and SinkS3Prefix locate the archive written by the s3 sink.
+	SinkS3Bucket string
+	SinkS3Prefix string
+	// SinkSQSQueueURL is the queue of the sqs sink. Crawls too large for a
+	// message are offloaded to SinkSQSOffloadBucket.
+	SinkSQSQueueURL      string
+	SinkSQSOffloadBucket string
+	// SinkFileDir is the directory written by the file sink.
+	SinkFileDir string
+
+	// SpoolDir, or SpoolS3Bucket and SpoolS3Prefix, locate the spool of
+	// crawls awaiting redelivery. The spool is disabled when both are empty.
+	SpoolDir      string
+	SpoolS3Bucket string
+	SpoolS3Prefix string
+	// SpoolMaxBytes and SpoolMaxAge bound the spool.
+	SpoolMaxBytes int64
+	SpoolMaxAge   time.Duration
+
+	// BackendInstructionsURL is polled for instructions after each crawl.
+	// Instructions are also taken from the backend's replies to deliveries.
+	BackendInstructionsURL string
+	// InstructionsAllowed lists the instruction types the crawler executes.
+	InstructionsAllowed []command.Type
+	// CrawlScheduleName is the EventBridge Scheduler schedule that invokes the
+	// crawler, changed by set_schedule instructions.
+	CrawlScheduleName string
 }
 
 // LoadConfig reads the required environment variables.
@@ -30,14 +109,220 @@ func LoadConfig() (*Config, error) {
 	if awsRegion == "" {
 		awsRegion = "us-east-1"
 	}
-	if backendEndpoint == "" {
-		return nil, fmt.Errorf("BACKEND_ENDPOINT is not set")
+
+	agentID := strings.TrimSpace(os.Getenv("AGENT_ID"))
+	if agentID == "" {
+		agentID = defaultAgentID()
+	}
+	backendTokenURL := strings.TrimSpace(os.Getenv("BACKEND_TOKEN_URL"))
+	backendClientID := strings.TrimSpace(os.Getenv("BACKEND_CLIENT_ID"))
+	backendClientSecret := strings.TrimSpace(os.Getenv("BACKEND_CLIENT_SECRET"))
+	if backendTokenURL != "" && (backendClientID == "" || backendClientSecret == "") {
+		return nil, fmt.Errorf("BACKEND_TOKEN_URL requires BACKEND_CLIENT_ID and BACKEND_CLIENT_SECRET")
+	}
+
+	backendTimeout := 30 * time.Second
+	if v := strings.TrimSpace(os.Getenv("BACKEND_TIMEOUT")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("BACKEND_TIMEOUT %q is not a positive duration", v)
+		}
+		backendTimeout = d
+	}
+	backendMaxAttempts, err := positiveIntEnv("BACKEND_MAX_ATTEMPTS", 5)
+	if err != nil {
+		return nil, err
+	}
+	var chunkedUpload bool
+	switch mode := strings.TrimSpace(os.Getenv("BACKEND_UPLOAD_MODE")); mode {
+	case "", "single":
+	case "chunked":
+		chunkedUpload = true
+	default:
+		return nil, fmt.Errorf("BACKEND_UPLOAD_MODE %q is not single or chunked", mode)
+	}
+	// Compression is opt-in: backends that do not decode Content-Encoding
+	// often answer 400 rather than 415, which the client cannot fall back from.
+	backendCompression := strings.TrimSpace(os.Getenv("BACKEND_COMPRESSION"))
+	switch backendCompression {
+	case "gzip", "zstd":
+	case "", "none":
+		backendCompression = ""
+	default:
+		return nil, fmt.Errorf("BACKEND_COMPRESSION %q is not gzip, zstd or none", backendCompression)
+	}
+	backendBatchBytes, err := positiveIntEnv("BACKEND_BATCH_BYTES", 0)
+	if err != nil {
+		return nil, err
+	}
+	backendBatchItems, err := positiveIntEnv("BACKEND_BATCH_ITEMS", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	backendTLSCertFile := strings.TrimSpace(os.Getenv("BACKEND_TLS_CERT_FILE"))
+	backendTLSKeyFile := strings.TrimSpace(os.Getenv("BACKEND_TLS_KEY_FILE"))
+	if (backendTLSCertFile == "") != (backendTLSKeyFile == "") {
+		return nil, fmt.Errorf("BACKEND_TLS_CERT_FILE and BACKEND_TLS_KEY_FILE must be set together")
+	}
+	var backendTLSMinVersion uint16
+	switch v := strings.TrimSpace(os.Getenv("BACKEND_TLS_MIN_VERSION")); v {
+	case "", "1.2":
+		backendTLSMinVersion = tls.VersionTLS12
+	case "1.3":
+		backendTLSMinVersion = tls.VersionTLS13
+	default:
+		return nil, fmt.Errorf("BACKEND_TLS_MIN_VERSION %q is not 1.2 or 1.3", v)
+	}
+	backendProxy := strings.TrimSpace(os.Getenv("BACKEND_PROXY"))
+	if backendProxy != "" {
+		if u, err := url.Parse(backendProxy); err != nil || u.Host == "" {
+			return nil, fmt.Errorf("BACKEND_PROXY %q is not a URL", backendProxy)
+		}
+	}
+	backendNoProxy, ok := os.LookupEnv("BACKEND_NO_PROXY")
+	if !ok {
+		backendNoProxy = os.Getenv("NO_PROXY")
+	}
+
+	instructionsAllowed := command.DefaultAllowed
+	if v, ok := os.LookupEnv("INSTRUCTIONS_ALLOWED"); ok {
+		if instructionsAllowed, err = command.ParseAllowed(v); err != nil {
+			return nil, fmt.Errorf("INSTRUCTIONS_ALLOWED: %w", err)
+		}
+	}
+
+	sinks := []string{"http"}
+	if v := strings.TrimSpace(os.Getenv("SINKS")); v != "" {
+		sinks = nil
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				sinks = append(sinks, name)
+			}
+		}
+	}
+	sinkS3Bucket := strings.TrimSpace(os.Getenv("SINK_S3_BUCKET"))
+	sinkSQSQueueURL := strings.TrimSpace(os.Getenv("SINK_SQS_QUEUE_URL"))
+	sinkSQSOffloadBucket := strings.TrimSpace(os.Getenv("SINK_SQS_OFFLOAD_BUCKET"))
+	if sinkSQSOffloadBucket == "" {
+		sinkSQSOffloadBucket = sinkS3Bucket
+	}
+	sinkFileDir := strings.TrimSpace(os.Getenv("SINK_FILE_DIR"))
+	if sinkFileDir == "" {
+		sinkFileDir = os.TempDir()
+	}
+	for _, name := range sinks {
+		switch name {
+		case "http":
+			if backendEndpoint == "" {
+				return nil, fmt.Errorf("BACKEND_ENDPOINT is not set")
+			}
+		case "s3":
+			if sinkS3Bucket == "" {
+				return nil, fmt.Errorf("SINK_S3_BUCKET is not set")
+			}
+		case "sqs":
+			if sinkSQSQueueURL == "" {
+				return nil, fmt.Errorf("SINK_SQS_QUEUE_URL is not set")
+			}
+		case "file", "stdout":
+		default:
+			return nil, fmt.Errorf("SINKS entry %q is not http, s3, sqs, file or stdout", name)
+		}
+	}
+	if len(sinks) == 0 {
+		return nil, fmt.Errorf("SINKS is empty")
+	}
+
+	var spoolDir, spoolS3Bucket, spoolS3Prefix string
+	switch spool := strings.TrimSpace(os.Getenv("SPOOL")); {
+	case spool == "":
+		spoolDir = filepath.Join(os.TempDir(), "crawler-spool")
+	case spool == "none":
+	case strings.HasPrefix(spool, "s3://"):
+		spoolS3Bucket, spoolS3Prefix, _ = strings.Cut(strings.TrimPrefix(spool, "s3://"), "/")
+		if spoolS3Bucket == "" {
+			return nil, fmt.Errorf("SPOOL %q has no bucket", spool)
+		}
+		if spoolS3Prefix != "" && !strings.HasSuffix(spoolS3Prefix, "/") {
+			spoolS3Prefix += "/"
+		}
+	default:
+		spoolDir = spool
+	}
+	spoolMaxMiB, err := positiveIntEnv("SPOOL_MAX_MB", 256)
+	if err != nil {
+		return nil, err
+	}
+	spoolMaxAge := 72 * time.Hour
+	if v := strings.TrimSpace(os.Getenv("SPOOL_MAX_AGE")); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("SPOOL_MAX_AGE %q is not a positive duration", v)
+		}
+		spoolMaxAge = d
 	}
 
 	return &Config{
-		AWSAccessKey:    awsAccessKey,
-		AWSSecretKey:    awsSecretKey,
-		AWSRegion:       awsRegion,
-		BackendEndpoint: backendEndpoint,
+		AWSAccessKey:           awsAccessKey,
+		AWSSecretKey:           awsSecretKey,
+		AWSRegion:              awsRegion,
+		BackendEndpoint:        backendEndpoint,
+		AgentID:                agentID,
+		BackendToken:           strings.TrimSpace(os.Getenv("BACKEND_TOKEN")),
+		BackendTokenURL:        backendTokenURL,
+		BackendClientID:        backendClientID,
+		BackendClientSecret:    backendClientSecret,
+		BackendSigningSecret:   strings.TrimSpace(os.Getenv("BACKEND_SIGNING_SECRET")),
+		BackendTimeout:         backendTimeout,
+		BackendMaxAttempts:     backendMaxAttempts,
+		ChunkedUpload:          chunkedUpload,
+		BackendBatchBytes:      backendBatchBytes,
+		BackendBatchItems:      backendBatchItems,
+		BackendCompression:     backendCompression,
+		BackendTLSCertFile:     backendTLSCertFile,
+		BackendTLSKeyFile:      backendTLSKeyFile,
+		BackendCAFile:          strings.TrimSpace(os.Getenv("BACKEND_CA_FILE")),
+		BackendTLSMinVersion:   backendTLSMinVersion,
+		BackendProxy:           backendProxy,
+		BackendNoProxy:         strings.TrimSpace(backendNoProxy),
+		Sinks:                  sinks,
+		SinkS3Bucket:           sinkS3Bucket,
+		SinkS3Prefix:           strings.TrimSpace(os.Getenv("SINK_S3_PREFIX")),
+		SinkSQSQueueURL:        sinkSQSQueueURL,
+		SinkSQSOffloadBucket:   sinkSQSOffloadBucket,
+		SinkFileDir:            sinkFileDir,
+		SpoolDir:               spoolDir,
+		SpoolS3Bucket:          spoolS3Bucket,
+		SpoolS3Prefix:          spoolS3Prefix,
+		SpoolMaxBytes:          int64(spoolMaxMiB) << 20,
+		SpoolMaxAge:            spoolMaxAge,
+		BackendInstructionsURL: strings.TrimSpace(os.Getenv("BACKEND_INSTRUCTIONS_URL")),
+		InstructionsAllowed:    instructionsAllowed,
+		CrawlScheduleName:      strings.TrimSpace(os.Getenv("CRAWL_SCHEDULE_NAME")),
 	}, nil
 }
+
+// positiveIntEnv parses the environment variable name as a positive integer,
+// returning def when it is unset.
+func positiveIntEnv(name string, def int) (int, error) {
+	v := strings.TrimSpace(os.Getenv(name))
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%s %q is not a positive integer", name, v)
+	}
+	return n, nil
+}
+
+// defaultAgentID falls back to the Lambda function name, or the host name
+// when running outside Lambda.
+func defaultAgentID() string {
+	if name := os.Getenv("AWS_LAMBDA_FUNCTION_NAME"); name != "" {
+		return name
+	}
+	host, _ := os.Hostname()
+	return host
+}
@@ -0,0 +1,177 @@
+// Package envelope describes the metadata that accompanies every crawl
+// payload and the rules for evolving the payload schema.
+//
+// The envelope's fields sit at the top level of the payload beside the crawl
+// sections ("account", "ec2_instances", ...), so consumers that ignore
+// unknown fields keep reading the sections, and chunked uploads still batch
+// each section on its own.
+//
+// Schema versioning rules:
+//
+//   - Adding a section or an optional field is compatible and does not change
+//     SchemaVersion. Consumers must ignore fields they do not know.
+//   - Removing or renaming a field, or changing its type or meaning,
+//     increments SchemaVersion and adds a migration to migrations that
+//     upgrades a payload of the previous version.
+//   - Migrate upgrades a payload of any earlier version by applying the
+//     migrations in order, and gives the sections the payload lacks their
+//     empty values. Payloads newer than SchemaVersion are rejected rather
+//     than misread.
+package envelope
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// SchemaVersion is the version of the payload schema this build produces.
+// Version 1 was the bare crawl sections without an envelope.
+const SchemaVersion = 2
+
+// Envelope is the crawl metadata.
+type Envelope struct {
+	SchemaVersion int       `json:"schema_version"`
+	CrawlID       string    `json:"crawl_id"`
+	StartedAt     time.Time `json:"started_at"`
+	FinishedAt    time.Time `json:"finished_at"`
+	Crawler       Crawler   `json:"crawler"`
+	Scope         Scope     `json:"scope"`
+	// ServiceDurationsMs is the time each crawled service took to fetch, in
+	// milliseconds, keyed by service name.
+	ServiceDurationsMs map[string]int64 `json:"service_durations_ms"`
+	// ServiceErrors holds the error of each service that could not be
+	// fetched, keyed by service name. The sections of these services are
+	// empty because they are unknown, not because the resources are gone.
+	ServiceErrors map[string]string `json:"service_errors"`
+}
+
+// Crawler identifies the build and deployment that produced a crawl.
+type Crawler struct {
+	Version string `json:"version"`
+	Commit  string `json:"commit"`
+	AgentID string `json:"agent_id"`
+}
+
+// Scope is what a crawl covers.
+type Scope struct {
+	AccountID string `json:"account_id"`
+	Region    string `json:"region"`
+	// Partial is set for crawls of some services only, requested by a backend
+	// instruction; sections of other services are then empty, not cleared.
+	Partial bool `json:"partial"`
+	// Global is set for the crawl that includes global resources, CloudFront
+	// web ACLs and Shield protections, which the crawl of one region of a run
+	// fetches on behalf of all.
+	Global      bool     `json:"global"`
+	Services    []string `json:"services"`
+	ResourceIDs []string `json:"resource_ids,omitempty"`
+	Instruction string   `json:"instruction,omitempty"` // ID of the instruction that requested the crawl.
+}
+
+// Durations converts per-service durations to ServiceDurationsMs.
+func Durations(d map[string]time.Duration) map[string]int64 {
+	ms := make(map[string]int64, len(d))
+	for service, duration := range d {
+		ms[service] = duration.Milliseconds()
+	}
+	return ms
+}
+
+// Errors converts per-service errors to ServiceErrors.
+func Errors(errs map[string]error) map[string]string {
+	messages := make(map[string]string, len(errs))
+	for service, err := range errs {
+		messages[service] = err.Error()
+	}
+	return messages
+}
+
+// migrations[v] upgrades the top-level members of a version v payload to
+// version v+1.
+var migrations = map[int]func(payload map[string]json.RawMessage) error{
+	1: fromV1,
+}
+
+// Migrate upgrades an encoded payload to SchemaVersion and returns it with the
+// version it had. A payload without schema_version is version 1. zero is an
+// empty payload of SchemaVersion, such as payload.Payload{}; an upgraded
+// payload takes the members it lacks from it, so sections added since its
+// version are empty rather than missing.
+func Migrate(payload []byte, zero any) ([]byte, int, error) {
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &members); err != nil {
+		return nil, 0, fmt.Errorf("payload is not a JSON object: %w", err)
+	}
+	version := 1
+	if raw, ok := members["schema_version"]; ok {
+		if string(raw) == "null" {
+			return nil, 0, fmt.Errorf("invalid schema_version: null")
+		}
+		if err := json.Unmarshal(raw, &version); err != nil {
+			return nil, 0, fmt.Errorf("invalid schema_version: %w", err)
+		}
+	}
+	if version < 1 || version > SchemaVersion {
+		return nil, version, fmt.Errorf("unsupported schema version %d, this build supports up to %d", version, SchemaVersion)
+	}
+	if version == SchemaVersion {
+		return payload, version, nil
+	}
+	for v := version; v < SchemaVersion; v++ {
+		if err := migrations[v](members); err != nil {
+			return nil, version, fmt.Errorf("failed to migrate schema version %d: %w", v, err)
+		}
+	}
+	empty, err := objectMembers(zero)
+	if err != nil {
+		return nil, version, fmt.Errorf("invalid empty payload: %w", err)
+	}
+	for name, member := range empty {
+		if _, ok := members[name]; !ok {
+			members[name] = member
+		}
+	}
+	upgraded, err := json.Marshal(members)
+	return upgraded, version, err
+}
+
+// fromV1 adds the envelope to bare crawl sections. The scope comes from the
+// account section; members the sections do not reveal, such as the crawl ID
+// and timestamps, take their zero values so the result matches the schema.
+func fromV1(payload map[string]json.RawMessage) error {
+	env := Envelope{SchemaVersion: 2}
+	if raw, ok := payload["account"]; ok {
+		var account struct {
+			AccountID string `json:"AccountId"`
+			Region    string `json:"Region"`
+		}
+		if err := json.Unmarshal(raw, &account); err != nil {
+			return fmt.Errorf("invalid account section: %w", err)
+		}
+		env.Scope.AccountID = account.AccountID
+		env.Scope.Region = account.Region
+	}
+	members, err := objectMembers(env)
+	if err != nil {
+		return err
+	}
+	for name, member := range members {
+		payload[name] = member
+	}
+	return nil
+}
+
+// objectMembers encodes v, which must encode as a JSON object, and returns
+// its members.
+func objectMembers(v any) (map[string]json.RawMessage, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &members); err != nil {
+		return nil, err
+	}
+	return members, nil
+}
@@ -0,0 +1,102 @@
+package envelope
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAccount struct {
+	AccountID string `json:"AccountId"`
+	Region    string `json:"Region"`
+}
+
+// testPayload stands in for payload.Payload: the envelope beside a version 1
+// section and one added in version 2.
+type testPayload struct {
+	Envelope
+	Account      *testAccount `json:"account"`
+	EC2Instances []string     `json:"ec2_instances"`
+	NewSection   []string     `json:"new_section"`
+}
+
+func TestMigrateFromV1(t *testing.T) {
+	v1 := `{"account":{"AccountId":"123456789012","Region":"eu-west-1"},"ec2_instances":["i-1"],"unknown":true}`
+	data, version, err := Migrate([]byte(v1), testPayload{})
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if version != 1 {
+		t.Errorf("Migrate() version = %d, want 1", version)
+	}
+
+	var members map[string]json.RawMessage
+	if err := json.Unmarshal(data, &members); err != nil {
+		t.Fatal(err)
+	}
+	var zero map[string]json.RawMessage
+	raw, _ := json.Marshal(testPayload{})
+	json.Unmarshal(raw, &zero)
+	for name := range zero {
+		if _, ok := members[name]; !ok {
+			t.Errorf("migrated payload lacks %s", name)
+		}
+	}
+	if got := string(members["new_section"]); got != "null" {
+		t.Errorf("new_section = %s, want null", got)
+	}
+	if got := string(members["unknown"]); got != "true" {
+		t.Errorf("unknown = %s, want it kept", got)
+	}
+
+	var got testPayload
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.SchemaVersion != SchemaVersion {
+		t.Errorf("schema_version = %d, want %d", got.SchemaVersion, SchemaVersion)
+	}
+	if want := (Scope{AccountID: "123456789012", Region: "eu-west-1"}); !reflect.DeepEqual(got.Scope, want) {
+		t.Errorf("scope = %+v, want %+v", got.Scope, want)
+	}
+	if !reflect.DeepEqual(got.EC2Instances, []string{"i-1"}) {
+		t.Errorf("ec2_instances = %v, want [i-1]", got.EC2Instances)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	current, _ := json.Marshal(testPayload{Envelope: Envelope{SchemaVersion: SchemaVersion}})
+	tests := []struct {
+		name        string
+		payload     string
+		wantVersion int
+		wantErr     string
+	}{
+		{"current version", string(current), SchemaVersion, ""},
+		{"version above current", `{"schema_version":99}`, 99, "unsupported schema version 99"},
+		{"version zero", `{"schema_version":0}`, 0, "unsupported schema version 0"},
+		{"string version", `{"schema_version":"2"}`, 0, "invalid schema_version"},
+		{"fractional version", `{"schema_version":1.5}`, 0, "invalid schema_version"},
+		{"null version", `{"schema_version":null}`, 0, "invalid schema_version"},
+		{"not an object", `["account"]`, 0, "not a JSON object"},
+		{"invalid account", `{"account":"123456789012"}`, 1, "invalid account section"},
+	}
+	for _, tt := range tests {
+		data, version, err := Migrate([]byte(tt.payload), testPayload{})
+		if version != tt.wantVersion {
+			t.Errorf("%s: Migrate() version = %d, want %d", tt.name, version, tt.wantVersion)
+		}
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: Migrate() error = %v, want %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Migrate() error = %v", tt.name, err)
+		} else if string(data) != tt.payload {
+			t.Errorf("%s: Migrate() = %s, want the payload unchanged", tt.name, data)
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/awsfetch"
+	"github.com/DavisAndn/go-aws-crawler/internal/envelope"
+)
+
+// Fixture is a crawl encoded the way the crawler encodes it.
+type Fixture struct {
+	Name string
+	Data []byte
+}
+
+// Fixtures returns crawls that exercise the schema: an empty crawl, a crawl
+// with every field of every section set, a partial crawl and a version 1
+// payload upgraded by envelope.Migrate.
+func Fixtures() ([]Fixture, error) {
+	startedAt := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	env := envelope.Envelope{
+		SchemaVersion: envelope.SchemaVersion,
+		CrawlID:       "0123456789abcdef",
+		StartedAt:     startedAt,
+		FinishedAt:    startedAt.Add(3 * time.Minute),
+		Crawler:       envelope.Crawler{Version: "dev", Commit: "0000000", AgentID: "fixture"},
+		Scope:         envelope.Scope{AccountID: "123456789012", Region: "us-east-1", Services: []string{"account"}},
+	}
+
+	// A crawl that found nothing: every section is empty.
+	empty := Payload{Envelope: env}
+
+	// A crawl with every field of every section set.
+	var full Payload
+	fill(reflect.ValueOf(&full).Elem(), 0)
+	full.Envelope = env
+	full.ServiceDurationsMs = map[string]int64{"account": 120}
+
+	partial := full
+	partial.Scope.Partial = true
+	partial.Scope.Instruction = "fill-1"
+	partial.Scope.ResourceIDs = []string{"i-0123456789abcdef0"}
+
+	d := full.InitialData
+	v1 := v1InitialData{
+		EC2Instances:      d.EC2Instances,
+		VPCs:              d.VPCs,
+		Subnets:           d.Subnets,
+		RouteTables:       d.RouteTables,
+		NATGateways:       d.NATGateways,
+		InternetGateways:  d.InternetGateways,
+		S3Buckets:         d.S3Buckets,
+		RDSInstances:      d.RDSInstances,
+		Route53Zones:      d.Route53Zones,
+		AutoScalingGroups: d.AutoScalingGroups,
+		LoadBalancers:     d.LoadBalancers,
+		EKSClusters:       d.EKSClusters,
+		IAMUsers:          d.IAMUsers,
+		IAMPolicies:       d.IAMPolicies,
+		ElastiCaches:      d.ElastiCaches,
+	}
+
+	var fs []Fixture
+	for _, f := range []struct {
+		name string
+		data any
+	}{
+		{"empty crawl", empty},
+		{"full crawl", full},
+		{"partial crawl", partial},
+		// A version 1 payload: bare sections, upgraded by envelope.Migrate.
+		{"migrated version 1 crawl", v1},
+	} {
+		data, err := json.Marshal(f.data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", f.name, err)
+		}
+		if data, _, err = envelope.Migrate(data, Payload{}); err != nil {
+			return nil, fmt.Errorf("failed to migrate %s: %w", f.name, err)
+		}
+		fs = append(fs, Fixture{f.name, data})
+	}
+	return fs, nil
+}
+
+// v1InitialData has the sections of a version 1 payload, the first release of
+// the crawler: no account section and none of the services added since. Its
+// items have the current fields.
+type v1InitialData struct {
+	EC2Instances      []awsfetch.EC2Instance      `json:"ec2_instances"`
+	VPCs              []awsfetch.VPC              `json:"vpcs"`
+	Subnets           []awsfetch.Subnet           `json:"subnets"`
+	RouteTables       []awsfetch.RouteTable       `json:"route_tables"`
+	NATGateways       []awsfetch.NATGateway       `json:"nat_gateways"`
+	InternetGateways  []awsfetch.InternetGateway  `json:"internet_gateways"`
+	S3Buckets         []awsfetch.S3Bucket         `json:"s3_buckets"`
+	RDSInstances      []awsfetch.RDSInstance      `json:"rds_instances"`
+	Route53Zones      []awsfetch.Route53Zone      `json:"route53_hosted_zones"`
+	AutoScalingGroups []awsfetch.AutoScalingGroup `json:"autoscaling_groups"`
+	LoadBalancers     []awsfetch.LoadBalancer     `json:"load_balancers"`
+	EKSClusters       []awsfetch.EKSCluster       `json:"eks_clusters"`
+	IAMUsers          []awsfetch.IAMUser          `json:"iam_users"`
+	IAMPolicies       []awsfetch.IAMPolicy        `json:"iam_policies"`
+	ElastiCaches      []awsfetch.ElastiCache      `json:"elastic_caches"`
+}
+
+// maxFillDepth bounds the nesting fill populates, for recursive types.
+const maxFillDepth = 8
+
+// fill sets every settable value reachable from v to a non-zero value, so a
+// fixture exercises every member of the schema.
+func fill(v reflect.Value, depth int) {
+	if depth > maxFillDepth || !v.CanSet() {
+		return
+	}
+	switch v.Interface().(type) {
+	case time.Time:
+		v.Set(reflect.ValueOf(time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)))
+		return
+	case json.RawMessage:
+		v.Set(reflect.ValueOf(json.RawMessage(`{"fixture":true}`)))
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(1)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	case reflect.String:
+		v.SetString("fixture")
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), depth+1)
+	case reflect.Slice:
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), depth+1)
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fill(v.Index(i), depth+1)
+		}
+	case reflect.Map:
+		key := reflect.New(v.Type().Key()).Elem()
+		fill(key, depth+1)
+		elem := reflect.New(v.Type().Elem()).Elem()
+		fill(elem, depth+1)
+		v.Set(reflect.MakeMap(v.Type()))
+		v.SetMapIndex(key, elem)
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			fill(v.Field(i), depth+1)
+		}
+	}
+}
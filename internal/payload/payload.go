@@ -0,0 +1,85 @@
+// Package payload defines the crawl payload the crawler delivers.
+package payload
+
+import (
+	"github.com/DavisAndn/go-aws-crawler/internal/awsfetch"
+	"github.com/DavisAndn/go-aws-crawler/internal/envelope"
+)
+
+// InitialData holds the crawl sections, one per fetched service.
+type InitialData struct {
+	Account                        awsfetch.AccountInfo                     `json:"account"`
+	EC2Instances                   []awsfetch.EC2Instance                   `json:"ec2_instances"`
+	VPCs                           []awsfetch.VPC                           `json:"vpcs"`
+	Subnets                        []awsfetch.Subnet                        `json:"subnets"`
+	RouteTables                    []awsfetch.RouteTable                    `json:"route_tables"`
+	NATGateways                    []awsfetch.NATGateway                    `json:"nat_gateways"`
+	InternetGateways               []awsfetch.InternetGateway               `json:"internet_gateways"`
+	S3Buckets                      []awsfetch.S3Bucket                      `json:"s3_buckets"`
+	RDSInstances                   []awsfetch.RDSInstance                   `json:"rds_instances"`
+	Route53Zones                   []awsfetch.Route53Zone                   `json:"route53_hosted_zones"`
+	AutoScalingGroups              []awsfetch.AutoScalingGroup              `json:"autoscaling_groups"`
+	LoadBalancers                  []awsfetch.LoadBalancer                  `json:"load_balancers"`
+	EKSClusters                    []awsfetch.EKSCluster                    `json:"eks_clusters"`
+	IAMUsers                       []awsfetch.IAMUser                       `json:"iam_users"`
+	IAMPolicies                    []awsfetch.IAMPolicy                     `json:"iam_policies"`
+	ElastiCaches                   []awsfetch.ElastiCache                   `json:"elastic_caches"`
+	APIGatewayRestAPIs             []awsfetch.APIGatewayRestAPI             `json:"apigateway_rest_apis"`
+	APIGatewayVpcLinks             []awsfetch.APIGatewayVpcLink             `json:"apigateway_vpc_links"`
+	APIGatewayV2APIs               []awsfetch.APIGatewayV2API               `json:"apigatewayv2_apis"`
+	APIGatewayV2VpcLinks           []awsfetch.APIGatewayV2VpcLink           `json:"apigatewayv2_vpc_links"`
+	APIGatewayDomainNames          []awsfetch.APIGatewayDomainName          `json:"apigateway_domain_names"`
+	VPCPeeringConnections          []awsfetch.VPCPeeringConnection          `json:"vpc_peering_connections"`
+	TransitGateways                []awsfetch.TransitGateway                `json:"transit_gateways"`
+	VPCEndpoints                   []awsfetch.VPCEndpoint                   `json:"vpc_endpoints"`
+	VPNConnections                 []awsfetch.VPNConnection                 `json:"vpn_connections"`
+	CustomerGateways               []awsfetch.CustomerGateway               `json:"customer_gateways"`
+	VPNGateways                    []awsfetch.VPNGateway                    `json:"vpn_gateways"`
+	EgressOnlyInternetGateways     []awsfetch.EgressOnlyInternetGateway     `json:"egress_only_internet_gateways"`
+	DirectConnectVirtualInterfaces []awsfetch.DirectConnectVirtualInterface `json:"direct_connect_virtual_interfaces"`
+	DirectConnectGateways          []awsfetch.DirectConnectGateway          `json:"direct_connect_gateways"`
+	NetworkInterfaces              []awsfetch.NetworkInterface              `json:"network_interfaces"`
+	ElasticIPs                     []awsfetch.ElasticIP                     `json:"elastic_ips"`
+	ACMCertificates                []awsfetch.ACMCertificate                `json:"acm_certificates"`
+	CloudFormationStacks           []awsfetch.CloudFormationStack           `json:"cloudformation_stacks"`
+	StackMembership                map[string]awsfetch.StackMembership      `json:"cloudformation_stack_membership"`
+	MetricAlarms                   []awsfetch.MetricAlarm                   `json:"cloudwatch_metric_alarms"`
+	CompositeAlarms                []awsfetch.CompositeAlarm                `json:"cloudwatch_composite_alarms"`
+	LogGroups                      []awsfetch.LogGroup                      `json:"log_groups"`
+	ECRRepositories                []awsfetch.ECRRepository                 `json:"ecr_repositories"`
+	ContainerImageConsumers        []awsfetch.ContainerImageConsumer        `json:"container_image_consumers"`
+	EKSWorkloadErrors              []awsfetch.EKSWorkloadError              `json:"eks_workload_errors"`
+	EFSFileSystems                 []awsfetch.EFSFileSystem                 `json:"efs_file_systems"`
+	EBSVolumes                     []awsfetch.EBSVolume                     `json:"ebs_volumes"`
+	DynamoDBTables                 []awsfetch.DynamoDBTable                 `json:"dynamodb_tables"`
+	FSxFileSystems                 []awsfetch.FSxFileSystem                 `json:"fsx_file_systems"`
+	BackupVaults                   []awsfetch.BackupVault                   `json:"backup_vaults"`
+	BackupPlans                    []awsfetch.BackupPlan                    `json:"backup_plans"`
+	BackupProtectedResources       []awsfetch.BackupProtectedResource       `json:"backup_protected_resources"`
+	BackupCoverage                 map[string]awsfetch.BackupCoverage       `json:"backup_coverage"`
+	KinesisStreams                 []awsfetch.KinesisStream                 `json:"kinesis_streams"`
+	FirehoseDeliveryStreams        []awsfetch.FirehoseDeliveryStream        `json:"firehose_delivery_streams"`
+	MSKClusters                    []awsfetch.MSKCluster                    `json:"msk_clusters"`
+	RedshiftClusters               []awsfetch.RedshiftCluster               `json:"redshift_clusters"`
+	RedshiftServerlessWorkgroups   []awsfetch.RedshiftServerlessWorkgroup   `json:"redshift_serverless_workgroups"`
+	OpenSearchDomains              []awsfetch.OpenSearchDomain              `json:"opensearch_domains"`
+	StateMachines                  []awsfetch.StateMachine                  `json:"step_functions_state_machines"`
+	EventBuses                     []awsfetch.EventBus                      `json:"eventbridge_buses"`
+	APIDestinations                []awsfetch.APIDestination                `json:"eventbridge_api_destinations"`
+	EventConnections               []awsfetch.EventConnection               `json:"eventbridge_connections"`
+	Schedules                      []awsfetch.Schedule                      `json:"scheduler_schedules"`
+	CognitoUserPools               []awsfetch.CognitoUserPool               `json:"cognito_user_pools"`
+	CognitoIdentityPools           []awsfetch.CognitoIdentityPool           `json:"cognito_identity_pools"`
+	WAFWebACLs                     []awsfetch.WAFWebACL                     `json:"waf_web_acls"`
+	ShieldProtections              []awsfetch.ShieldProtection              `json:"shield_protections"`
+	ServiceQuotas                  []awsfetch.ServiceQuota                  `json:"service_quotas"`
+	SecurityFindings               []awsfetch.SecurityFinding               `json:"security_findings"`
+	SSMHybridInstances             []awsfetch.SSMManagedInstance            `json:"ssm_hybrid_instances"`
+}
+
+// Payload is what the crawler delivers: the envelope followed by the crawl
+// sections, side by side at the top level.
+type Payload struct {
+	envelope.Envelope
+	InitialData
+}
@@ -0,0 +1,33 @@
+package payload
+
+//go:generate go run ../../cmd/schemagen -o ../../schema/crawl-payload.schema.json
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/envelope"
+	"github.com/DavisAndn/go-aws-crawler/internal/schema"
+)
+
+// Schema returns the JSON Schema of Payload, with schema_version pinned to
+// envelope.SchemaVersion.
+//
+// Every member this build writes is required. Members added since an older
+// build of the same schema version are unknown to that build's schema, which
+// accepts them, so a receiver of several builds validates against the schema
+// of the oldest.
+func Schema() *schema.Schema {
+	s := schema.Generate(Payload{}, fmt.Sprintf("Crawl payload, schema version %d", envelope.SchemaVersion))
+	s.Properties["schema_version"].Const = envelope.SchemaVersion
+	return s
+}
+
+// SchemaJSON returns Schema encoded as published in schema/.
+func SchemaJSON() ([]byte, error) {
+	data, err := json.MarshalIndent(Schema(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode schema: %w", err)
+	}
+	return append(data, '\n'), nil
+}
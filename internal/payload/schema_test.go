@@ -0,0 +1,47 @@
+package payload
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/schema"
+)
+
+const publishedSchema = "../../schema/crawl-payload.schema.json"
+
+func TestSchemaUpToDate(t *testing.T) {
+	generated, err := SchemaJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	published, err := os.ReadFile(publishedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(published, generated) {
+		t.Errorf("%s is out of date with the payload types; run go generate ./internal/payload", publishedSchema)
+	}
+}
+
+func TestFixturesValidate(t *testing.T) {
+	published, err := os.ReadFile(publishedSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := schema.Parse(published)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixtures, err := Fixtures()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range fixtures {
+		t.Run(f.Name, func(t *testing.T) {
+			if err := s.Validate(f.Data); err != nil {
+				t.Errorf("fixture does not validate:\n%v", err)
+			}
+		})
+	}
+}
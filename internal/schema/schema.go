@@ -0,0 +1,276 @@
+// Package schema generates JSON Schemas from Go types and validates JSON
+// documents against them.
+//
+// Generate follows the encoding/json rules, so the schema describes exactly
+// what json.Marshal produces for the type. Validate supports the keywords
+// Generate emits, not the whole JSON Schema specification.
+package schema
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Draft is the JSON Schema dialect of generated schemas.
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema.
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// Types is the "type" keyword: a single JSON type, or several when the value
+// may also be null.
+type Types []string
+
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	rawMessageType    = reflect.TypeOf(json.RawMessage{})
+	marshalerType     = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// generator collects the definitions of the named struct types it meets.
+type generator struct {
+	defs  map[string]*Schema
+	names map[reflect.Type]string
+}
+
+// Generate returns the schema of the JSON encoding of v's type. Named struct
+// types are described once under $defs and referenced from every use.
+//
+// Struct fields without omitempty are required, since encoding/json always
+// writes them. Objects allow members the schema does not list, so a
+// consumer's schema keeps accepting payloads that gained compatible fields.
+func Generate(v any, title string) *Schema {
+	g := &generator{defs: make(map[string]*Schema), names: make(map[reflect.Type]string)}
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	// The root is described in place rather than referenced.
+	var root *Schema
+	if t.Kind() == reflect.Struct {
+		root = g.object(t)
+	} else {
+		root = g.schema(t)
+	}
+	root.Schema = Draft
+	root.Title = title
+	if len(g.defs) > 0 {
+		root.Defs = g.defs
+	}
+	return root
+}
+
+func (g *generator) schema(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: Types{"string"}, Format: "date-time"}
+	case t == rawMessageType:
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(marshalerType) || reflect.PointerTo(t).Implements(marshalerType)):
+		// The encoding is up to the type.
+		return &Schema{}
+	case t.Kind() != reflect.Pointer && (t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType)):
+		return &Schema{Type: Types{"string"}}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return &Schema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: Types{"number"}}
+	case reflect.String:
+		return &Schema{Type: Types{"string"}}
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem()))
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// Byte slices are encoded as base64 strings.
+			return &Schema{Type: Types{"string", "null"}}
+		}
+		return &Schema{Type: Types{"array", "null"}, Items: g.schema(t.Elem())}
+	case reflect.Array:
+		return &Schema{Type: Types{"array"}, Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: Types{"object", "null"}, AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t)
+		}
+		return &Schema{Ref: "#/$defs/" + g.define(t)}
+	}
+	// Channels and functions cannot be encoded.
+	panic(fmt.Sprintf("schema: unsupported type %v", t))
+}
+
+// define adds the schema of the named struct type t to the definitions and
+// returns its name there: the type name, qualified with the package name when
+// another package has a type of the same name.
+func (g *generator) define(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := g.defs[name]; taken {
+		pkg := t.PkgPath()
+		name = pkg[strings.LastIndex(pkg, "/")+1:] + "." + name
+	}
+	g.names[t] = name
+	g.defs[name] = &Schema{} // Placeholder for recursive types.
+	*g.defs[name] = *g.object(t)
+	return name
+}
+
+// object returns the schema of a struct type.
+func (g *generator) object(t reflect.Type) *Schema {
+	s := &Schema{Type: Types{"object"}, Properties: make(map[string]*Schema)}
+	for _, f := range fields(t) {
+		fs := g.schema(f.typ)
+		if f.quoted {
+			fs = &Schema{Type: Types{"string"}}
+			if f.typ.Kind() == reflect.Pointer {
+				fs = nullable(fs)
+			}
+		}
+		s.Properties[f.name] = fs
+		if !f.optional {
+			s.Required = append(s.Required, f.name)
+		}
+	}
+	return s
+}
+
+type field struct {
+	name     string
+	typ      reflect.Type
+	optional bool
+	quoted   bool // The ",string" option.
+	depth    int
+}
+
+// fields lists the encoded fields of struct type t, promoting the fields of
+// embedded structs as encoding/json does. Of fields with the same name, the
+// shallowest wins. Fields promoted through an embedded pointer are optional,
+// since a nil pointer leaves them out.
+func fields(t reflect.Type) []field {
+	var list []field
+	index := make(map[string]int)
+	var walk func(t reflect.Type, depth int, viaPointer bool)
+	walk = func(t reflect.Type, depth int, viaPointer bool) {
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			tag := f.Tag.Get("json")
+			if tag == "-" {
+				continue
+			}
+			name, opts, _ := strings.Cut(tag, ",")
+			ft := f.Type
+			if f.Anonymous && name == "" {
+				embedded, pointer := ft, false
+				if embedded.Kind() == reflect.Pointer {
+					embedded, pointer = embedded.Elem(), true
+				}
+				if embedded.Kind() == reflect.Struct {
+					if !f.IsExported() && pointer {
+						continue
+					}
+					walk(embedded, depth+1, viaPointer || pointer)
+					continue
+				}
+			}
+			if !f.IsExported() {
+				continue
+			}
+			if name == "" {
+				name = f.Name
+			}
+			fd := field{
+				name:     name,
+				typ:      ft,
+				optional: viaPointer || strings.Contains(","+opts+",", ",omitempty,") || strings.Contains(","+opts+",", ",omitzero,"),
+				quoted:   strings.Contains(","+opts+",", ",string,") && isScalar(ft),
+				depth:    depth,
+			}
+			if i, ok := index[name]; ok {
+				if list[i].depth > depth {
+					list[i] = fd
+				}
+				continue
+			}
+			index[name] = len(list)
+			list = append(list, fd)
+		}
+	}
+	walk(t, 0, false)
+	return list
+}
+
+// isScalar reports whether the ",string" option applies to values of t.
+func isScalar(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64, reflect.String:
+		return true
+	}
+	return false
+}
+
+// nullable returns s also accepting null.
+func nullable(s *Schema) *Schema {
+	switch {
+	case len(s.Type) == 0 && s.Ref == "" && s.AnyOf == nil:
+		return s // Accepts anything already.
+	case s.Ref != "":
+		return &Schema{AnyOf: []*Schema{{Type: Types{"null"}}, s}}
+	}
+	for _, t := range s.Type {
+		if t == "null" {
+			return s
+		}
+	}
+	n := *s
+	n.Type = append(append(Types{}, s.Type...), "null")
+	return &n
+}
@@ -0,0 +1,93 @@
+package schema
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+type inner struct {
+	Name string `json:"name"`
+}
+
+type Embedded struct {
+	Promoted string `json:"promoted"`
+}
+
+type sample struct {
+	Embedded
+	ID       int               `json:"id"`
+	Optional string            `json:"optional,omitempty"`
+	Quoted   int64             `json:"quoted,string"`
+	At       time.Time         `json:"at"`
+	Inner    inner             `json:"inner"`
+	Ptr      *inner            `json:"ptr"`
+	List     []inner           `json:"list"`
+	Labels   map[string]string `json:"labels"`
+	Raw      json.RawMessage   `json:"raw"`
+	Skipped  string            `json:"-"`
+	private  string
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(sample{}, "Sample")
+
+	if s.Schema != Draft || s.Title != "Sample" {
+		t.Errorf("root $schema, title = %q, %q", s.Schema, s.Title)
+	}
+	wantRequired := []string{"promoted", "id", "quoted", "at", "inner", "ptr", "list", "labels", "raw"}
+	if !slices.Equal(s.Required, wantRequired) {
+		t.Errorf("required = %q, want %q", s.Required, wantRequired)
+	}
+	for _, name := range []string{"Skipped", "private", "-"} {
+		if _, ok := s.Properties[name]; ok {
+			t.Errorf("property %q should not be described", name)
+		}
+	}
+
+	tests := []struct {
+		property string
+		want     string
+	}{
+		{"id", `{"type":"integer"}`},
+		{"optional", `{"type":"string"}`},
+		{"quoted", `{"type":"string"}`},
+		{"at", `{"type":"string","format":"date-time"}`},
+		{"inner", `{"$ref":"#/$defs/inner"}`},
+		{"ptr", `{"anyOf":[{"type":"null"},{"$ref":"#/$defs/inner"}]}`},
+		{"list", `{"type":["array","null"],"items":{"$ref":"#/$defs/inner"}}`},
+		{"labels", `{"type":["object","null"],"additionalProperties":{"type":"string"}}`},
+		{"raw", `{}`},
+	}
+	for _, tt := range tests {
+		got, err := json.Marshal(s.Properties[tt.property])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(got) != tt.want {
+			t.Errorf("property %q = %s, want %s", tt.property, got, tt.want)
+		}
+	}
+	if def := s.Defs["inner"]; def == nil || !slices.Equal(def.Required, []string{"name"}) {
+		t.Errorf("$defs/inner = %+v, want an object requiring name", def)
+	}
+}
+
+func TestGenerateRoundTrip(t *testing.T) {
+	data, err := json.Marshal(Generate(sample{}, "Sample"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(again) != string(data) {
+		t.Errorf("parsed schema encodes as\n%s\nwant\n%s", again, data)
+	}
+}
@@ -0,0 +1,168 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxErrors bounds the violations Validate reports.
+const maxErrors = 20
+
+// Parse decodes a schema written by Generate.
+func Parse(data []byte) (*Schema, error) {
+	var s Schema
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	return &s, nil
+}
+
+// Validate checks the JSON document data against s. It returns the
+// violations, each prefixed with the JSON pointer of the offending value.
+func (s *Schema) Validate(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("invalid JSON: data after the top-level value")
+	}
+
+	v := &validator{root: s}
+	v.validate(s, doc, "")
+	if v.dropped > 0 {
+		v.errs = append(v.errs, fmt.Errorf("and %d more", v.dropped))
+	}
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	root    *Schema
+	errs    []error
+	dropped int
+}
+
+func (v *validator) fail(path, format string, args ...any) {
+	if len(v.errs) == maxErrors {
+		v.dropped++
+		return
+	}
+	if path == "" {
+		path = "/"
+	}
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) validate(s *Schema, doc any, path string) {
+	if s.Ref != "" {
+		def, ok := v.root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")]
+		if !ok || !strings.HasPrefix(s.Ref, "#/$defs/") {
+			v.fail(path, "cannot resolve $ref %q", s.Ref)
+			return
+		}
+		v.validate(def, doc, path)
+	}
+	if len(s.AnyOf) > 0 && !slices.ContainsFunc(s.AnyOf, func(alt *Schema) bool {
+		sub := &validator{root: v.root}
+		sub.validate(alt, doc, path)
+		return len(sub.errs) == 0
+	}) {
+		v.fail(path, "matches none of the alternatives")
+	}
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return hasType(doc, t) }) {
+		v.fail(path, "is %s, want %s", typeOf(doc), strings.Join(s.Type, " or "))
+		return
+	}
+	if s.Const != nil && !equal(s.Const, doc) {
+		c, _ := json.Marshal(s.Const)
+		v.fail(path, "must be %s", c)
+	}
+	if str, ok := doc.(string); ok && s.Format == "date-time" {
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			v.fail(path, "%q is not an RFC 3339 date-time", str)
+		}
+	}
+
+	switch doc := doc.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := doc[name]; !ok {
+				v.fail(path, "missing required member %q", name)
+			}
+		}
+		names := make([]string, 0, len(doc))
+		for name := range doc {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			member := doc[name]
+			if ps, ok := s.Properties[name]; ok {
+				v.validate(ps, member, path+"/"+escape(name))
+			} else if s.AdditionalProperties != nil {
+				v.validate(s.AdditionalProperties, member, path+"/"+escape(name))
+			}
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range doc {
+				v.validate(s.Items, item, path+"/"+strconv.Itoa(i))
+			}
+		}
+	}
+}
+
+// hasType reports whether doc is of the JSON Schema type t.
+func hasType(doc any, t string) bool {
+	switch doc := doc.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case string:
+		return t == "string"
+	case json.Number:
+		if t == "number" {
+			return true
+		}
+		f, err := doc.Float64()
+		return t == "integer" && err == nil && f == math.Trunc(f)
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+func typeOf(doc any) string {
+	for _, t := range []string{"null", "boolean", "string", "integer", "number", "array", "object"} {
+		if hasType(doc, t) {
+			return t
+		}
+	}
+	return fmt.Sprintf("%T", doc)
+}
+
+// equal compares JSON values by their encoding, which sorts object members
+// and writes equal numbers alike.
+func equal(a, b any) bool {
+	ea, errA := json.Marshal(a)
+	eb, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(ea, eb)
+}
+
+// escape escapes a member name for a JSON pointer.
+func escape(name string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(name)
+}
@@ -0,0 +1,113 @@
+package schema
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAcceptsMarshalledValues(t *testing.T) {
+	s := Generate(sample{}, "Sample")
+	values := []sample{
+		{},
+		{
+			Embedded: Embedded{Promoted: "p"},
+			ID:       1,
+			Optional: "o",
+			Quoted:   7,
+			At:       time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Ptr:      &inner{Name: "n"},
+			List:     []inner{{Name: "a"}, {Name: "b"}},
+			Labels:   map[string]string{"k": "v"},
+			Raw:      json.RawMessage(`[1, "two"]`),
+		},
+	}
+	for _, v := range values {
+		data, err := json.Marshal(v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := s.Validate(data); err != nil {
+			t.Errorf("Validate(%s) = %v, want nil", data, err)
+		}
+	}
+}
+
+func TestValidateRejects(t *testing.T) {
+	s := Generate(sample{}, "Sample")
+	valid, err := json.Marshal(sample{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		edit    func(doc map[string]any)
+		raw     string // Used instead of the edited document when set.
+		wantErr string
+	}{
+		{"wrong type", func(doc map[string]any) { doc["id"] = "1" }, "", `/id: is string, want integer`},
+		{"fractional integer", func(doc map[string]any) { doc["id"] = 1.5 }, "", `/id: is number, want integer`},
+		{"missing required member", func(doc map[string]any) { delete(doc, "id") }, "", `/: missing required member "id"`},
+		{"missing promoted member", func(doc map[string]any) { delete(doc, "promoted") }, "", `missing required member "promoted"`},
+		{"nested wrong type", func(doc map[string]any) { doc["inner"] = map[string]any{"name": 1} }, "", `/inner/name: is integer, want string`},
+		{"nested missing member", func(doc map[string]any) { doc["list"] = []any{map[string]any{}} }, "", `/list/0: missing required member "name"`},
+		{"null where not nullable", func(doc map[string]any) { doc["inner"] = nil }, "", `/inner: is null, want object`},
+		{"wrong map value", func(doc map[string]any) { doc["labels"] = map[string]any{"k": true} }, "", `/labels/k: is boolean, want string`},
+		{"invalid date-time", func(doc map[string]any) { doc["at"] = "yesterday" }, "", `/at: "yesterday" is not an RFC 3339 date-time`},
+		{"pointer of wrong type", func(doc map[string]any) { doc["ptr"] = "x" }, "", `/ptr: matches none of the alternatives`},
+		{"not an object", nil, `[]`, `/: is array, want object`},
+		{"invalid JSON", nil, `{"id":`, `invalid JSON`},
+		{"trailing data", nil, `{} {}`, `data after the top-level value`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := []byte(tt.raw)
+			if tt.edit != nil {
+				var doc map[string]any
+				if err := json.Unmarshal(valid, &doc); err != nil {
+					t.Fatal(err)
+				}
+				tt.edit(doc)
+				if data, err = json.Marshal(doc); err != nil {
+					t.Fatal(err)
+				}
+			}
+			err := s.Validate(data)
+			if err == nil {
+				t.Fatalf("Validate(%s) = nil, want an error", data)
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Validate(%s) = %v, want an error containing %q", data, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateConst(t *testing.T) {
+	s := Generate(struct {
+		Version int `json:"version"`
+	}{}, "Versioned")
+	s.Properties["version"].Const = 2
+
+	if err := s.Validate([]byte(`{"version":2}`)); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+	if err := s.Validate([]byte(`{"version":1}`)); err == nil || !strings.Contains(err.Error(), "/version: must be 2") {
+		t.Errorf("Validate() = %v, want a const error", err)
+	}
+}
+
+func TestValidateCapsErrors(t *testing.T) {
+	s := Generate([]inner{}, "List")
+	data := []byte(`[` + strings.Repeat(`1,`, maxErrors+4) + `1]`)
+	err := s.Validate(data)
+	if err == nil {
+		t.Fatal("Validate() = nil, want errors")
+	}
+	lines := strings.Split(err.Error(), "\n")
+	if len(lines) != maxErrors+1 || lines[len(lines)-1] != "and 5 more" {
+		t.Errorf("Validate() reported %d lines ending %q, want %d ending \"and 5 more\"", len(lines), lines[len(lines)-1], maxErrors+1)
+	}
+}
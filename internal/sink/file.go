@@ -0,0 +1,54 @@
+package sink
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// File writes each crawl to {Dir}/{crawl ID}.json.
+type File struct {
+	Dir string
+}
+
+// Name returns "file".
+func (f *File) Name() string { return "file" }
+
+// Deliver writes the crawl through a temporary file, so a partially written
+// crawl is never left under its final name.
+func (f *File) Deliver(_ context.Context, crawl *Crawl) error {
+	payload, err := crawl.JSON()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", f.Dir, err)
+	}
+	path := filepath.Join(f.Dir, crawl.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, payload, 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", tmp, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
+// Stdout prints each crawl as one line of JSON.
+type Stdout struct{}
+
+// Name returns "stdout".
+func (Stdout) Name() string { return "stdout" }
+
+// Deliver prints the crawl.
+func (Stdout) Deliver(_ context.Context, crawl *Crawl) error {
+	payload, err := crawl.JSON()
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stdout, "%s\n", payload)
+	return err
+}
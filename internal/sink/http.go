@@ -0,0 +1,28 @@
+package sink
+
+import (
+	"context"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/backend"
+)
+
+// HTTP delivers crawls to the backend API.
+type HTTP struct {
+	Client  *backend.Client
+	Chunked bool // Use the chunked upload protocol instead of a single request.
+}
+
+// Name returns "http".
+func (h *HTTP) Name() string { return "http" }
+
+// Deliver sends the crawl to the backend.
+func (h *HTTP) Deliver(ctx context.Context, crawl *Crawl) error {
+	if h.Chunked {
+		return h.Client.UploadCrawl(ctx, crawl.ID, crawl.Data)
+	}
+	payload, err := crawl.JSON()
+	if err != nil {
+		return err
+	}
+	return h.Client.SendInitialCrawlResults(ctx, crawl.ID, payload)
+}
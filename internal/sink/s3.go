@@ -0,0 +1,68 @@
+package sink
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// S3 archives crawls as gzipped JSON objects keyed by account, region and
+// start time: {Prefix}{account}/{region}/{timestamp}-{crawl ID}.json.gz.
+type S3 struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+// Name returns "s3".
+func (s *S3) Name() string { return "s3" }
+
+// Deliver uploads the crawl.
+func (s *S3) Deliver(ctx context.Context, crawl *Crawl) error {
+	_, err := s.put(ctx, crawl)
+	return err
+}
+
+// Key returns the object key of crawl.
+func (s *S3) Key(crawl *Crawl) string {
+	return fmt.Sprintf("%s%s/%s/%s-%s.json.gz", s.Prefix, crawl.AccountID, crawl.Region,
+		crawl.StartedAt.UTC().Format("20060102T150405Z"), crawl.ID)
+}
+
+// put uploads the crawl and returns its key.
+func (s *S3) put(ctx context.Context, crawl *Crawl) (string, error) {
+	payload, err := crawl.JSON()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(payload); err != nil {
+		return "", fmt.Errorf("failed to gzip crawl: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("failed to gzip crawl: %w", err)
+	}
+
+	key := s.Key(crawl)
+	_, err = s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:          aws.String(s.Bucket),
+		Key:             aws.String(key),
+		Body:            bytes.NewReader(buf.Bytes()),
+		ContentType:     aws.String("application/json"),
+		ContentEncoding: aws.String("gzip"),
+		Metadata: map[string]string{
+			"crawl-id":   crawl.ID,
+			"account-id": crawl.AccountID,
+			"region":     crawl.Region,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to upload s3://%s/%s: %w", s.Bucket, key, err)
+	}
+	return key, nil
+}
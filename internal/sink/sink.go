@@ -0,0 +1,127 @@
+// Package sink delivers finished crawls to one or more destinations.
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/backend"
+	"github.com/DavisAndn/go-aws-crawler/internal/config"
+)
+
+// Crawl is a finished crawl handed to the sinks.
+type Crawl struct {
+	ID        string
+	AccountID string
+	Region    string
+	StartedAt time.Time
+	Data      any // The payload; encoded as JSON by sinks that need bytes.
+
+	once    sync.Once
+	payload []byte
+	err     error
+}
+
+// JSON returns the JSON encoding of Data, encoding it only once however many
+// sinks ask for it.
+func (c *Crawl) JSON() ([]byte, error) {
+	c.once.Do(func() {
+		c.payload, c.err = json.Marshal(c.Data)
+		if c.err != nil {
+			c.err = fmt.Errorf("failed to marshal crawl %s: %w", c.ID, c.err)
+		}
+	})
+	return c.payload, c.err
+}
+
+// Sink is a destination for finished crawls.
+type Sink interface {
+	// Name identifies the sink in logs and errors.
+	Name() string
+	// Deliver writes the crawl to the destination.
+	Deliver(ctx context.Context, crawl *Crawl) error
+}
+
+// Multi delivers a crawl to every sink it holds.
+type Multi []Sink
+
+// Name lists the names of the sinks.
+func (m Multi) Name() string {
+	names := make([]string, len(m))
+	for i, s := range m {
+		names[i] = s.Name()
+	}
+	return strings.Join(names, ",")
+}
+
+// Deliver delivers the crawl to all sinks concurrently. A failing sink does
+// not stop the others; the failures are returned together.
+func (m Multi) Deliver(ctx context.Context, crawl *Crawl) error {
+	errs := make([]error, len(m))
+	var wg sync.WaitGroup
+	for i, s := range m {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := s.Deliver(ctx, crawl); err != nil {
+				errs[i] = fmt.Errorf("%s sink: %w", s.Name(), err)
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// NewSpoolStore returns the spool configured by cfg, or nil when the spool is
+// disabled.
+func NewSpoolStore(cfg *config.Config, awsConfig aws.Config) SpoolStore {
+	switch {
+	case cfg.SpoolS3Bucket != "":
+		return &S3Spool{Client: s3.NewFromConfig(awsConfig), Bucket: cfg.SpoolS3Bucket, Prefix: cfg.SpoolS3Prefix}
+	case cfg.SpoolDir != "":
+		return &DirSpool{Dir: cfg.SpoolDir}
+	}
+	return nil
+}
+
+// FromConfig builds the sinks selected by cfg.Sinks; the http sink sends
+// through client. When store is not nil every sink but stdout spools the
+// crawls it fails to deliver there.
+func FromConfig(cfg *config.Config, awsConfig aws.Config, client *backend.Client, store SpoolStore) (Multi, error) {
+	var sinks Multi
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "http":
+			sinks = append(sinks, &HTTP{Client: client, Chunked: cfg.ChunkedUpload})
+		case "s3":
+			sinks = append(sinks, &S3{Client: s3.NewFromConfig(awsConfig), Bucket: cfg.SinkS3Bucket, Prefix: cfg.SinkS3Prefix})
+		case "sqs":
+			q := &SQS{Client: sqs.NewFromConfig(awsConfig), QueueURL: cfg.SinkSQSQueueURL}
+			if cfg.SinkSQSOffloadBucket != "" {
+				q.Offload = &S3{Client: s3.NewFromConfig(awsConfig), Bucket: cfg.SinkSQSOffloadBucket, Prefix: "sqs-offload/"}
+			}
+			sinks = append(sinks, q)
+		case "file":
+			sinks = append(sinks, &File{Dir: cfg.SinkFileDir})
+		case "stdout":
+			sinks = append(sinks, Stdout{})
+			continue
+		default:
+			return nil, fmt.Errorf("unknown sink %q", name)
+		}
+		if store != nil {
+			last := len(sinks) - 1
+			sinks[last] = &Spooled{Sink: sinks[last], Store: store, MaxBytes: cfg.SpoolMaxBytes, MaxAge: cfg.SpoolMaxAge}
+		}
+	}
+	return sinks, nil
+}
@@ -0,0 +1,251 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/backend"
+)
+
+// SpoolEntry describes a spooled crawl.
+type SpoolEntry struct {
+	Key     string
+	Size    int64
+	ModTime time.Time
+}
+
+// SpoolStore holds spooled crawls. Keys sort in delivery order.
+type SpoolStore interface {
+	List(ctx context.Context, prefix string) ([]SpoolEntry, error)
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// spooledCrawl is the stored form of a crawl.
+type spooledCrawl struct {
+	ID        string          `json:"id"`
+	AccountID string          `json:"account_id"`
+	Region    string          `json:"region"`
+	StartedAt time.Time       `json:"started_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// Spooled keeps crawls its sink failed to deliver and redelivers them, oldest
+// first, before the next crawl. A crawl keeps its ID when redelivered, so
+// destinations that deduplicate by crawl ID see each crawl once.
+//
+// Only transient failures are spooled. A crawl the destination rejects, such
+// as a payload a backend answers 4xx to, would be rejected again and hold up
+// every crawl behind it, so it is moved to the dead letters instead: a
+// separate prefix of the store that is kept for inspection and never
+// redelivered.
+type Spooled struct {
+	Sink  Sink
+	Store SpoolStore
+	// MaxBytes bounds the total size of the spool and MaxAge the age of its
+	// crawls; the oldest crawls are evicted first. Zero means no bound.
+	MaxBytes int64
+	MaxAge   time.Duration
+}
+
+// Name returns the name of the wrapped sink.
+func (s *Spooled) Name() string { return s.Sink.Name() }
+
+// Deliver redelivers the spooled crawls and then delivers crawl. Once a
+// delivery fails transiently the remaining crawls stay spooled, with crawl
+// behind them, so the order of deliveries is preserved.
+func (s *Spooled) Deliver(ctx context.Context, crawl *Crawl) error {
+	err := s.redeliver(ctx)
+	if err == nil {
+		if err = s.Sink.Deliver(ctx, crawl); err == nil {
+			return nil
+		}
+		if ctx.Err() == nil && !transient(err) {
+			if deadErr := s.deadLetter(ctx, crawl); deadErr != nil {
+				return errors.Join(err, deadErr)
+			}
+			return fmt.Errorf("crawl %s rejected and moved to the dead letters: %w", crawl.ID, err)
+		}
+	}
+	if spoolErr := s.spool(ctx, crawl); spoolErr != nil {
+		return errors.Join(err, spoolErr)
+	}
+	return fmt.Errorf("crawl %s spooled for redelivery: %w", crawl.ID, err)
+}
+
+// transient reports whether a failed delivery may succeed if repeated later:
+// a backend or transport failure the backend client retries, or an AWS error
+// the SDK retries, including one it gave up retrying.
+func transient(err error) bool {
+	if backend.IsRetryable(err) {
+		return true
+	}
+	var maxAttempts *retry.MaxAttemptsError
+	if errors.As(err, &maxAttempts) {
+		return true
+	}
+	return retry.IsErrorRetryables(retry.DefaultRetryables).IsErrorRetryable(err) == aws.TrueTernary
+}
+
+// prefix keeps the crawls of each sink apart in a shared store.
+func (s *Spooled) prefix() string { return s.Sink.Name() + "/" }
+
+// deadPrefix holds the crawls of the sink that were rejected.
+func (s *Spooled) deadPrefix() string { return "dead-letter/" + s.prefix() }
+
+func (s *Spooled) redeliver(ctx context.Context) error {
+	entries, err := s.Store.List(ctx, s.prefix())
+	if err != nil {
+		return fmt.Errorf("failed to list spool: %w", err)
+	}
+	for _, entry := range entries {
+		if s.expired(entry) {
+			log.Printf("Evicting expired spooled crawl %s", entry.Key)
+			if err := s.Store.Delete(ctx, entry.Key); err != nil {
+				return fmt.Errorf("failed to evict spooled crawl %s: %w", entry.Key, err)
+			}
+			continue
+		}
+		data, err := s.Store.Get(ctx, entry.Key)
+		if err != nil {
+			return fmt.Errorf("failed to read spooled crawl %s: %w", entry.Key, err)
+		}
+		var stored spooledCrawl
+		if err := json.Unmarshal(data, &stored); err != nil {
+			// A corrupt entry can never be delivered; drop it rather than
+			// block the spool behind it.
+			log.Printf("Dropping unreadable spooled crawl %s: %v", entry.Key, err)
+			s.Store.Delete(ctx, entry.Key)
+			continue
+		}
+		crawl := &Crawl{
+			ID:        stored.ID,
+			AccountID: stored.AccountID,
+			Region:    stored.Region,
+			StartedAt: stored.StartedAt,
+			Data:      stored.Data,
+		}
+		if err := s.Sink.Deliver(ctx, crawl); err != nil {
+			if ctx.Err() != nil || transient(err) {
+				return fmt.Errorf("failed to redeliver spooled crawl %s: %w", stored.ID, err)
+			}
+			log.Printf("Spooled crawl %s rejected by %s, moving it to the dead letters: %v", stored.ID, s.Name(), err)
+			if err := s.putDead(ctx, strings.TrimPrefix(entry.Key, s.prefix()), data); err != nil {
+				return err
+			}
+		} else {
+			log.Printf("Redelivered spooled crawl %s to %s", stored.ID, s.Name())
+		}
+		if err := s.Store.Delete(ctx, entry.Key); err != nil {
+			return fmt.Errorf("failed to remove spooled crawl %s: %w", entry.Key, err)
+		}
+	}
+	return nil
+}
+
+func (s *Spooled) spool(ctx context.Context, crawl *Crawl) error {
+	data, err := encodeCrawl(crawl)
+	if err != nil {
+		return err
+	}
+	if s.MaxBytes > 0 && int64(len(data)) > s.MaxBytes {
+		return fmt.Errorf("crawl %s is %d bytes, larger than the spool", crawl.ID, len(data))
+	}
+	if err := s.evict(ctx, s.prefix(), int64(len(data))); err != nil {
+		return err
+	}
+	if err := s.Store.Put(ctx, s.prefix()+crawlKey(crawl), data); err != nil {
+		return fmt.Errorf("failed to spool crawl %s: %w", crawl.ID, err)
+	}
+	return nil
+}
+
+// deadLetter stores a crawl the sink rejected.
+func (s *Spooled) deadLetter(ctx context.Context, crawl *Crawl) error {
+	data, err := encodeCrawl(crawl)
+	if err != nil {
+		return err
+	}
+	return s.putDead(ctx, crawlKey(crawl), data)
+}
+
+// putDead stores an encoded crawl under key among the dead letters, which
+// are bounded like the spool.
+func (s *Spooled) putDead(ctx context.Context, key string, data []byte) error {
+	if s.MaxBytes > 0 && int64(len(data)) > s.MaxBytes {
+		log.Printf("Dropping rejected crawl %s, larger than the spool", key)
+		return nil
+	}
+	if err := s.evict(ctx, s.deadPrefix(), int64(len(data))); err != nil {
+		return err
+	}
+	if err := s.Store.Put(ctx, s.deadPrefix()+key, data); err != nil {
+		return fmt.Errorf("failed to store rejected crawl %s: %w", key, err)
+	}
+	return nil
+}
+
+// encodeCrawl returns the stored form of crawl.
+func encodeCrawl(crawl *Crawl) ([]byte, error) {
+	payload, err := crawl.JSON()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(spooledCrawl{
+		ID:        crawl.ID,
+		AccountID: crawl.AccountID,
+		Region:    crawl.Region,
+		StartedAt: crawl.StartedAt,
+		Data:      payload,
+	})
+}
+
+// crawlKey names a stored crawl below a prefix so that keys sort by start
+// time.
+func crawlKey(crawl *Crawl) string {
+	return crawl.StartedAt.UTC().Format("20060102T150405.000000000Z") + "-" + crawl.ID + ".json"
+}
+
+// evict removes crawls under prefix older than MaxAge, then the oldest crawls
+// until another incoming bytes fit within MaxBytes.
+func (s *Spooled) evict(ctx context.Context, prefix string, incoming int64) error {
+	entries, err := s.Store.List(ctx, prefix)
+	if err != nil {
+		return fmt.Errorf("failed to list spool: %w", err)
+	}
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+	for _, entry := range entries {
+		full := s.MaxBytes > 0 && total+incoming > s.MaxBytes
+		if !s.expired(entry) && !full {
+			break
+		}
+		log.Printf("Evicting spooled crawl %s", entry.Key)
+		if err := s.Store.Delete(ctx, entry.Key); err != nil {
+			return fmt.Errorf("failed to evict spooled crawl %s: %w", entry.Key, err)
+		}
+		total -= entry.Size
+	}
+	return nil
+}
+
+func (s *Spooled) expired(entry SpoolEntry) bool {
+	return s.MaxAge > 0 && time.Since(entry.ModTime) > s.MaxAge
+}
+
+// sortEntries orders entries by key, which is their delivery order.
+func sortEntries(entries []SpoolEntry) {
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Key < entries[j].Key })
+}
@@ -0,0 +1,266 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DavisAndn/go-aws-crawler/internal/backend"
+)
+
+// memSpool is an in-memory SpoolStore.
+type memSpool struct {
+	entries map[string]memEntry
+}
+
+type memEntry struct {
+	data    []byte
+	modTime time.Time
+}
+
+func newMemSpool() *memSpool { return &memSpool{entries: make(map[string]memEntry)} }
+
+func (m *memSpool) List(_ context.Context, prefix string) ([]SpoolEntry, error) {
+	var entries []SpoolEntry
+	for key, e := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			entries = append(entries, SpoolEntry{Key: key, Size: int64(len(e.data)), ModTime: e.modTime})
+		}
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+func (m *memSpool) Get(_ context.Context, key string) ([]byte, error) {
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, fs.ErrNotExist
+	}
+	return e.data, nil
+}
+
+func (m *memSpool) Put(_ context.Context, key string, data []byte) error {
+	m.entries[key] = memEntry{data: data, modTime: time.Now()}
+	return nil
+}
+
+func (m *memSpool) Delete(_ context.Context, key string) error {
+	delete(m.entries, key)
+	return nil
+}
+
+// keys lists the stored keys under prefix.
+func (m *memSpool) keys(prefix string) []string {
+	var keys []string
+	for key := range m.entries {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// fakeSink records the crawls it delivers and fails those listed in errs.
+type fakeSink struct {
+	errs      map[string]error
+	delivered []string
+}
+
+func (f *fakeSink) Name() string { return "fake" }
+
+func (f *fakeSink) Deliver(_ context.Context, crawl *Crawl) error {
+	if err := f.errs[crawl.ID]; err != nil {
+		return err
+	}
+	f.delivered = append(f.delivered, crawl.ID)
+	return nil
+}
+
+var (
+	unavailable = &backend.StatusError{StatusCode: 503}
+	rejected    = &backend.StatusError{StatusCode: 422}
+)
+
+func testCrawl(id string, minute int) *Crawl {
+	return &Crawl{
+		ID:        id,
+		AccountID: "123456789012",
+		Region:    "eu-west-1",
+		StartedAt: time.Date(2024, 5, 1, 12, minute, 0, 0, time.UTC),
+		Data:      json.RawMessage(`{"crawl_id":"` + id + `"}`),
+	}
+}
+
+func TestSpooledRedeliversInOrder(t *testing.T) {
+	ctx := context.Background()
+	sink := &fakeSink{errs: map[string]error{"c1": unavailable, "c2": unavailable}}
+	s := &Spooled{Sink: sink, Store: newMemSpool()}
+
+	if err := s.Deliver(ctx, testCrawl("c1", 1)); err == nil {
+		t.Fatal("Deliver(c1) succeeded, want the crawl spooled")
+	}
+	// c2 would now succeed, but waits behind the spooled c1.
+	sink.errs = map[string]error{"c1": unavailable}
+	if err := s.Deliver(ctx, testCrawl("c2", 2)); err == nil {
+		t.Fatal("Deliver(c2) succeeded, want the crawl spooled behind c1")
+	}
+	if len(sink.delivered) != 0 {
+		t.Fatalf("delivered %v while c1 was failing, want nothing", sink.delivered)
+	}
+
+	sink.errs = nil
+	if err := s.Deliver(ctx, testCrawl("c3", 3)); err != nil {
+		t.Fatalf("Deliver(c3) error = %v", err)
+	}
+	if want := []string{"c1", "c2", "c3"}; !reflect.DeepEqual(sink.delivered, want) {
+		t.Errorf("delivered %v, want %v", sink.delivered, want)
+	}
+	if keys := s.Store.(*memSpool).keys(""); len(keys) != 0 {
+		t.Errorf("spool holds %v after redelivery, want nothing", keys)
+	}
+}
+
+func TestSpooledDeadLettersRejectedCrawls(t *testing.T) {
+	ctx := context.Background()
+	store := newMemSpool()
+	sink := &fakeSink{errs: map[string]error{"c1": unavailable, "c2": unavailable}}
+	s := &Spooled{Sink: sink, Store: store}
+	s.Deliver(ctx, testCrawl("c1", 1))
+	s.Deliver(ctx, testCrawl("c2", 2))
+
+	// c1 is now rejected; it must not hold up c2 and c3.
+	sink.errs = map[string]error{"c1": rejected}
+	if err := s.Deliver(ctx, testCrawl("c3", 3)); err != nil {
+		t.Fatalf("Deliver(c3) error = %v", err)
+	}
+	if want := []string{"c2", "c3"}; !reflect.DeepEqual(sink.delivered, want) {
+		t.Errorf("delivered %v, want %v", sink.delivered, want)
+	}
+
+	// A rejected new crawl is not spooled either.
+	sink.errs = map[string]error{"c4": rejected}
+	if err := s.Deliver(ctx, testCrawl("c4", 4)); err == nil {
+		t.Error("Deliver(c4) succeeded, want the rejection")
+	}
+	if keys := store.keys("fake/"); len(keys) != 0 {
+		t.Errorf("spool holds %v, want nothing", keys)
+	}
+	want := []string{
+		"dead-letter/fake/20240501T120100.000000000Z-c1.json",
+		"dead-letter/fake/20240501T120400.000000000Z-c4.json",
+	}
+	if got := store.keys("dead-letter/"); !reflect.DeepEqual(got, want) {
+		t.Errorf("dead letters = %v, want %v", got, want)
+	}
+}
+
+func TestSpooledEviction(t *testing.T) {
+	ctx := context.Background()
+
+	// Crawls older than MaxAge are dropped without being redelivered.
+	store := newMemSpool()
+	sink := &fakeSink{errs: map[string]error{"c1": unavailable, "c2": unavailable}}
+	s := &Spooled{Sink: sink, Store: store, MaxAge: time.Hour}
+	s.Deliver(ctx, testCrawl("c1", 1))
+	s.Deliver(ctx, testCrawl("c2", 2))
+	old := store.entries["fake/20240501T120100.000000000Z-c1.json"]
+	old.modTime = time.Now().Add(-2 * time.Hour)
+	store.entries["fake/20240501T120100.000000000Z-c1.json"] = old
+	sink.errs = nil
+	if err := s.Deliver(ctx, testCrawl("c3", 3)); err != nil {
+		t.Fatalf("Deliver(c3) error = %v", err)
+	}
+	if want := []string{"c2", "c3"}; !reflect.DeepEqual(sink.delivered, want) {
+		t.Errorf("age: delivered %v, want %v", sink.delivered, want)
+	}
+
+	// When the spool is full the oldest crawls make room.
+	store = newMemSpool()
+	sink = &fakeSink{errs: map[string]error{"c1": unavailable, "c2": unavailable, "c3": unavailable}}
+	size, _ := encodeCrawl(testCrawl("c1", 1))
+	s = &Spooled{Sink: sink, Store: store, MaxBytes: int64(2*len(size) + 1)}
+	for i, id := range []string{"c1", "c2", "c3"} {
+		s.Deliver(ctx, testCrawl(id, i+1))
+	}
+	want := []string{
+		"fake/20240501T120200.000000000Z-c2.json",
+		"fake/20240501T120300.000000000Z-c3.json",
+	}
+	if got := store.keys(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("size: spool = %v, want %v", got, want)
+	}
+}
+
+func TestSpooledDropsCorruptEntries(t *testing.T) {
+	ctx := context.Background()
+	store := newMemSpool()
+	store.Put(ctx, "fake/20240501T120000.000000000Z-c0.json", []byte("{not json"))
+	sink := &fakeSink{errs: map[string]error{"c1": unavailable}}
+	s := &Spooled{Sink: sink, Store: store}
+	s.Deliver(ctx, testCrawl("c1", 1))
+
+	sink.errs = nil
+	if err := s.Deliver(ctx, testCrawl("c2", 2)); err != nil {
+		t.Fatalf("Deliver(c2) error = %v", err)
+	}
+	if want := []string{"c1", "c2"}; !reflect.DeepEqual(sink.delivered, want) {
+		t.Errorf("delivered %v, want %v", sink.delivered, want)
+	}
+	if keys := store.keys(""); len(keys) != 0 {
+		t.Errorf("spool holds %v, want the corrupt entry dropped", keys)
+	}
+}
+
+func TestDirSpool(t *testing.T) {
+	ctx := context.Background()
+	d := &DirSpool{Dir: t.TempDir()}
+
+	if entries, err := d.List(ctx, "fake/"); err != nil || entries != nil {
+		t.Errorf("List() of a missing prefix = %v, %v, want nothing", entries, err)
+	}
+	if err := d.Put(ctx, "fake/b.json", []byte("bb")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Put(ctx, "fake/a.json", []byte("a")); err != nil {
+		t.Fatal(err)
+	}
+	// A crash between writing and renaming leaves a temporary file behind.
+	if err := os.WriteFile(filepath.Join(d.Dir, "fake", "c.json.tmp"), []byte("partial"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := d.List(ctx, "fake/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var keys []string
+	for _, e := range entries {
+		keys = append(keys, e.Key)
+	}
+	if want := []string{"fake/a.json", "fake/b.json"}; !reflect.DeepEqual(keys, want) {
+		t.Errorf("List() = %v, want %v", keys, want)
+	}
+	if entries[1].Size != 2 {
+		t.Errorf("size of fake/b.json = %d, want 2", entries[1].Size)
+	}
+	if data, err := d.Get(ctx, "fake/b.json"); err != nil || string(data) != "bb" {
+		t.Errorf("Get() = %q, %v, want \"bb\"", data, err)
+	}
+	if err := d.Delete(ctx, "fake/a.json"); err != nil {
+		t.Errorf("Delete() error = %v", err)
+	}
+	if err := d.Delete(ctx, "fake/a.json"); err != nil {
+		t.Errorf("Delete() of a deleted entry error = %v, want nil", err)
+	}
+	if _, err := d.Get(ctx, "fake/a.json"); !os.IsNotExist(err) {
+		t.Errorf("Get() of a deleted entry error = %v, want not exist", err)
+	}
+}
@@ -0,0 +1,153 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	s3types "github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// DirSpool is a SpoolStore in a local directory. On Lambda only /tmp is
+// writable, and it survives between invocations of a warm instance.
+type DirSpool struct {
+	Dir string
+}
+
+// List returns the entries under prefix in key order. Partially written
+// entries are skipped.
+func (d *DirSpool) List(_ context.Context, prefix string) ([]SpoolEntry, error) {
+	files, err := os.ReadDir(filepath.Join(d.Dir, filepath.FromSlash(prefix)))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []SpoolEntry
+	for _, f := range files {
+		if f.IsDir() || strings.HasSuffix(f.Name(), ".tmp") {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, SpoolEntry{Key: prefix + f.Name(), Size: info.Size(), ModTime: info.ModTime()})
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Get reads an entry.
+func (d *DirSpool) Get(_ context.Context, key string) ([]byte, error) {
+	return os.ReadFile(d.path(key))
+}
+
+// Put writes an entry through a temporary file, so a crash never leaves a
+// partial entry behind.
+func (d *DirSpool) Put(_ context.Context, key string, data []byte) error {
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// Delete removes an entry.
+func (d *DirSpool) Delete(_ context.Context, key string) error {
+	err := os.Remove(d.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (d *DirSpool) path(key string) string {
+	return filepath.Join(d.Dir, filepath.FromSlash(key))
+}
+
+// S3Spool is a SpoolStore under a prefix of an S3 bucket, for spooled crawls
+// that must outlive the Lambda instance.
+type S3Spool struct {
+	Client *s3.Client
+	Bucket string
+	Prefix string
+}
+
+// List returns the entries under prefix in key order.
+func (s *S3Spool) List(ctx context.Context, prefix string) ([]SpoolEntry, error) {
+	var entries []SpoolEntry
+	paginator := s3.NewListObjectsV2Paginator(s.Client, &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(s.Prefix + prefix),
+	})
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list s3://%s/%s: %w", s.Bucket, s.Prefix+prefix, err)
+		}
+		for _, obj := range page.Contents {
+			entries = append(entries, SpoolEntry{
+				Key:     strings.TrimPrefix(aws.ToString(obj.Key), s.Prefix),
+				Size:    aws.ToInt64(obj.Size),
+				ModTime: aws.ToTime(obj.LastModified),
+			})
+		}
+	}
+	sortEntries(entries)
+	return entries, nil
+}
+
+// Get reads an entry. A missing entry is reported as fs.ErrNotExist.
+func (s *S3Spool) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.Client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.Prefix + key),
+	})
+	var noSuchKey *s3types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return nil, fs.ErrNotExist
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer out.Body.Close()
+	return io.ReadAll(out.Body)
+}
+
+// Put writes an entry.
+func (s *S3Spool) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.Client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(s.Prefix + key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String("application/json"),
+	})
+	return err
+}
+
+// Delete removes an entry.
+func (s *S3Spool) Delete(ctx context.Context, key string) error {
+	_, err := s.Client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.Bucket),
+		Key:    aws.String(s.Prefix + key),
+	})
+	return err
+}
@@ -0,0 +1,79 @@
+package sink
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sqs"
+	sqstypes "github.com/aws/aws-sdk-go-v2/service/sqs/types"
+)
+
+// maxSQSMessageBytes is the SQS limit on message body plus attributes; bodies
+// are kept under it with room to spare for the attributes.
+const maxSQSMessageBytes = 256*1024 - 4*1024
+
+// SQS sends crawls as queue messages. A crawl too large for a message is
+// uploaded to Offload and the message carries an S3Pointer to it instead.
+type SQS struct {
+	Client   *sqs.Client
+	QueueURL string
+	Offload  *S3 // Nil when large crawls cannot be offloaded.
+}
+
+// S3Pointer is the body of a message whose crawl was offloaded to S3.
+type S3Pointer struct {
+	CrawlID  string `json:"crawl_id"`
+	S3Bucket string `json:"s3_bucket"`
+	S3Key    string `json:"s3_key"`
+}
+
+// Name returns "sqs".
+func (q *SQS) Name() string { return "sqs" }
+
+// Deliver sends the crawl, or a pointer to its offloaded copy, to the queue.
+// The "payload_location" message attribute is "inline" or "s3".
+func (q *SQS) Deliver(ctx context.Context, crawl *Crawl) error {
+	payload, err := crawl.JSON()
+	if err != nil {
+		return err
+	}
+	location := "inline"
+	if len(payload) > maxSQSMessageBytes {
+		if q.Offload == nil {
+			return fmt.Errorf("crawl is %d bytes, over the SQS limit, and no offload bucket is configured", len(payload))
+		}
+		key, err := q.Offload.put(ctx, crawl)
+		if err != nil {
+			return err
+		}
+		if payload, err = json.Marshal(S3Pointer{CrawlID: crawl.ID, S3Bucket: q.Offload.Bucket, S3Key: key}); err != nil {
+			return err
+		}
+		location = "s3"
+	}
+
+	_, err = q.Client.SendMessage(ctx, &sqs.SendMessageInput{
+		QueueUrl:    aws.String(q.QueueURL),
+		MessageBody: aws.String(string(payload)),
+		MessageAttributes: map[string]sqstypes.MessageAttributeValue{
+			"crawl_id":         stringAttribute(crawl.ID),
+			"account_id":       stringAttribute(crawl.AccountID),
+			"region":           stringAttribute(crawl.Region),
+			"payload_location": stringAttribute(location),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to send message to %s: %w", q.QueueURL, err)
+	}
+	return nil
+}
+
+func stringAttribute(v string) sqstypes.MessageAttributeValue {
+	// SQS rejects empty attribute values.
+	if v == "" {
+		v = "-"
+	}
+	return sqstypes.MessageAttributeValue{DataType: aws.String("String"), StringValue: aws.String(v)}
+}
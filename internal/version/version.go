@@ -0,0 +1,31 @@
+// Package version identifies the crawler build.
+package version
+
+import "runtime/debug"
+
+// Version and Commit are set at build time with
+//
+//	-ldflags "-X github.com/DavisAndn/go-aws-crawler/internal/version.Version=v1.2.3
+//	          -X github.com/DavisAndn/go-aws-crawler/internal/version.Commit=$(git rev-parse HEAD)"
+//
+// Without them Commit falls back to the revision recorded by the Go toolchain
+// when building from a git checkout.
+var (
+	Version = "dev"
+	Commit  = ""
+)
+
+func init() {
+	if Commit != "" {
+		return
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+	for _, s := range info.Settings {
+		if s.Key == "vcs.revision" {
+			Commit = s.Value
+		}
+	}
+}